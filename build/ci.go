@@ -81,6 +81,7 @@ var (
 		executablePath("puppeth"),
 		executablePath("rlpdump"),
 		executablePath("clef"),
+		executablePath("pocrsim"),
 	}
 
 	// A debian package is created for all executables listed here.
@@ -0,0 +1,393 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/cliquepocr"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	pocrCommand = &cli.Command{
+		Name:        "pocr",
+		Usage:       "A set of commands for the proof-of-carbon-reduction networks",
+		Description: "",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "genesis",
+				Usage:     "Write the PoCR contracts into a genesis file",
+				ArgsUsage: "<description> <genesisPath>",
+				Action:    pocrGenesis,
+				Description: `
+geth pocr genesis <description> <genesisPath>
+reads the YAML (or JSON) description of the initial content of the PoCR
+contracts and prints the genesis file with the code and the storage of the
+contracts in its alloc, and the sealers as the signers of its extra-data. The
+footprints are read from the storage of the contracts written, so the genesis
+schedules the footprint storage fork at block 0 if it has none. The clique
+configuration of the genesis is switched to the PoCR engine.
+
+The description lists the sealers, with their audited footprint and optionally
+the block of their audit the audit age penalty runs from (the genesis block by
+default), the owner of the PoCR contract (by default the first caller of
+setOwner) and the governance session variables:
+
+sealers:
+  - address: "0x6e45c195e12d7fe5e02059f15d59c2c976a9b730"
+    footprint: 1000
+    auditBlock: 0
+owner: "0x6e45c195e12d7fe5e02059f15d59c2c976a9b730"
+governance:
+  RankDecay: 80
+`,
+			},
+			{
+				Name:   "audit",
+				Usage:  "Replay the stored chain to check the PoCR rewards",
+				Action: pocrAudit,
+				Flags: flags.Merge([]cli.Flag{
+					pocrAuditFromFlag,
+					pocrAuditToFlag,
+					pocrAuditOutputFlag,
+					pocrAuditFormatFlag,
+					pocrAuditorFlag,
+					utils.PasswordFileFlag,
+					utils.KeyStoreDirFlag,
+					utils.CacheFlag,
+				}, utils.DatabasePathFlags),
+				Description: `
+geth pocr audit --from <N> --to <M> --output <report> --auditor <address>
+replays the blocks N to M of the stored chain against the state of their parent
+with the engine code, and checks the reward, the fee adjustment and the total
+crypto generated it computes against the balance of the sealer and the total
+stored in the state of the block, and against the reward record of the node.
+
+The report lists the outcome of every block, in CSV or JSON (--format), and is
+signed by an account of the keystore: the signature of the report file, as
+produced by eth_sign, is written to <report>.sig, so it can be checked with
+
+ethkey verifymessage --msgfile <report> <address> <signature>
+
+The replay needs the state of the parent of every audited block: the historical
+blocks can only be audited on an archive node (--gcmode=archive). The command
+fails if any block does not match.
+`,
+			},
+			pocrFootprintCommand,
+			pocrOwnerCommand,
+			pocrStatusCommand,
+		},
+	}
+
+	pocrAuditFromFlag = &cli.Uint64Flag{
+		Name:  "from",
+		Usage: "First block to audit",
+		Value: 1,
+	}
+	pocrAuditToFlag = &cli.Uint64Flag{
+		Name:  "to",
+		Usage: "Last block to audit (default = head block)",
+	}
+	pocrAuditOutputFlag = &cli.StringFlag{
+		Name:  "output",
+		Usage: "File to write the report to",
+	}
+	pocrAuditFormatFlag = &cli.StringFlag{
+		Name:  "format",
+		Usage: "Format of the report (csv or json)",
+		Value: "csv",
+	}
+	pocrAuditorFlag = &cli.StringFlag{
+		Name:  "auditor",
+		Usage: "Account of the keystore signing the report",
+	}
+)
+
+// pocrGenesisDescription is the description of the initial content of the PoCR
+// contracts read by the genesis command.
+type pocrGenesisDescription struct {
+	Sealers []struct {
+		Address    common.Address `yaml:"address"`
+		Footprint  *big.Int       `yaml:"footprint"`
+		AuditBlock *big.Int       `yaml:"auditBlock"`
+	} `yaml:"sealers"`
+	Owner      *common.Address     `yaml:"owner"`
+	Governance map[string]*big.Int `yaml:"governance"`
+}
+
+// contracts returns the genesis content of the PoCR contracts described.
+func (d *pocrGenesisDescription) contracts() *cliquepocr.GenesisContracts {
+	contracts := &cliquepocr.GenesisContracts{
+		Owner:      d.Owner,
+		Governance: d.Governance,
+	}
+	for _, sealer := range d.Sealers {
+		contracts.Sealers = append(contracts.Sealers, cliquepocr.GenesisSealer{
+			Address:    sealer.Address,
+			Footprint:  sealer.Footprint,
+			AuditBlock: sealer.AuditBlock,
+		})
+	}
+	return contracts
+}
+
+// readPoCRGenesisDescription reads the description of the PoCR contracts from a
+// YAML or JSON file, rejecting the unknown fields.
+func readPoCRGenesisDescription(path string) (*pocrGenesisDescription, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	description := new(pocrGenesisDescription)
+	if err := decoder.Decode(description); err != nil {
+		return nil, err
+	}
+	return description, nil
+}
+
+func pocrGenesis(ctx *cli.Context) error {
+	if ctx.Args().Len() != 2 {
+		utils.Fatalf("This command requires two arguments.")
+	}
+	description, err := readPoCRGenesisDescription(ctx.Args().Get(0))
+	if err != nil {
+		utils.Fatalf("Invalid PoCR description: %v", err)
+	}
+	file, err := os.Open(ctx.Args().Get(1))
+	if err != nil {
+		utils.Fatalf("Failed to read genesis file: %v", err)
+	}
+	defer file.Close()
+
+	genesis := new(core.Genesis)
+	if err := json.NewDecoder(file).Decode(genesis); err != nil {
+		utils.Fatalf("invalid genesis file: %v", err)
+	}
+	contracts := description.contracts()
+	alloc, err := contracts.Alloc()
+	if err != nil {
+		utils.Fatalf("Invalid PoCR description: %v", err)
+	}
+	if genesis.Alloc == nil {
+		genesis.Alloc = make(core.GenesisAlloc)
+	}
+	for address, account := range alloc {
+		genesis.Alloc[address] = account
+	}
+	genesis.ExtraData = contracts.ExtraData()
+
+	// The built contracts run the PoCR engine, reading the footprints and the
+	// audit blocks from the storage
+	if genesis.Config == nil {
+		utils.Fatalf("Genesis file without chain configuration")
+	}
+	if genesis.Config.Clique == nil {
+		genesis.Config.Clique = new(params.CliqueConfig)
+	}
+	genesis.Config.Clique.PoCR = true
+	if genesis.Config.Clique.FootprintStorageBlock == nil {
+		genesis.Config.Clique.FootprintStorageBlock = new(big.Int)
+	}
+
+	out, err := json.MarshalIndent(genesis, "", "  ")
+	if err != nil {
+		utils.Fatalf("Failed to encode genesis: %v", err)
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// pocrAuditReport is the JSON report of the audit command, the amounts being
+// decimal strings.
+type pocrAuditReport struct {
+	ChainID    string             `json:"chainId"`
+	From       uint64             `json:"from"`
+	To         uint64             `json:"to"`
+	Mismatches int                `json:"mismatches"`
+	Blocks     []pocrAuditedBlock `json:"blocks"`
+}
+
+type pocrAuditedBlock struct {
+	Number            uint64         `json:"number"`
+	Hash              common.Hash    `json:"hash"`
+	Author            common.Address `json:"author"`
+	Rank              string         `json:"rank"`
+	BlockReward       string         `json:"blockReward"`
+	FeeAdjustment     string         `json:"feeAdjustment"`
+	Burnt             string         `json:"burnt"`
+	TotalCryptoBefore string         `json:"totalCryptoBefore"`
+	TotalCryptoAfter  string         `json:"totalCryptoAfter"`
+	ExpectedBalance   string         `json:"expectedBalance"`
+	Balance           string         `json:"balance"`
+	Mismatches        []string       `json:"mismatches"`
+}
+
+// pocrAuditColumns are the columns of the CSV report, in the order of the
+// fields of pocrAuditedBlock.
+var pocrAuditColumns = []string{"number", "hash", "author", "rank", "blockReward", "feeAdjustment", "burnt", "totalCryptoBefore", "totalCryptoAfter", "expectedBalance", "balance", "mismatches"}
+
+// decimalString formats an amount of the audit, empty if the block could not be
+// replayed.
+func decimalString(x *big.Int) string {
+	if x == nil {
+		return ""
+	}
+	return x.String()
+}
+
+func newPoCRAuditedBlock(audit *cliquepocr.BlockAudit) pocrAuditedBlock {
+	block := pocrAuditedBlock{
+		Number:            audit.Number,
+		Hash:              audit.Hash,
+		Author:            audit.Author,
+		BlockReward:       decimalString(audit.BlockReward),
+		FeeAdjustment:     decimalString(audit.FeeAdjustment),
+		Burnt:             decimalString(audit.Burnt),
+		TotalCryptoBefore: decimalString(audit.TotalCryptoBefore),
+		TotalCryptoAfter:  decimalString(audit.TotalCryptoAfter),
+		ExpectedBalance:   decimalString(audit.ExpectedBalance),
+		Balance:           decimalString(audit.Balance),
+		Mismatches:        audit.Mismatches,
+	}
+	if audit.Rank != nil {
+		block.Rank = audit.Rank.FloatString(18)
+	}
+	if block.Mismatches == nil {
+		block.Mismatches = []string{}
+	}
+	return block
+}
+
+// encode returns the report in the given format.
+func (r *pocrAuditReport) encode(format string) ([]byte, error) {
+	switch format {
+	case "json":
+		return json.MarshalIndent(r, "", "  ")
+	case "csv":
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		w.Write(pocrAuditColumns)
+		for _, b := range r.Blocks {
+			w.Write([]string{
+				strconv.FormatUint(b.Number, 10), b.Hash.Hex(), b.Author.Hex(), b.Rank,
+				b.BlockReward, b.FeeAdjustment, b.Burnt, b.TotalCryptoBefore, b.TotalCryptoAfter,
+				b.ExpectedBalance, b.Balance, strings.Join(b.Mismatches, "; "),
+			})
+		}
+		w.Flush()
+		return buf.Bytes(), w.Error()
+	default:
+		return nil, fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+func pocrAudit(ctx *cli.Context) error {
+	output, signer := ctx.String(pocrAuditOutputFlag.Name), ctx.String(pocrAuditorFlag.Name)
+	if output == "" || signer == "" {
+		utils.Fatalf("The report file (--%s) and its signer (--%s) are required.", pocrAuditOutputFlag.Name, pocrAuditorFlag.Name)
+	}
+	format := ctx.String(pocrAuditFormatFlag.Name)
+	if format != "csv" && format != "json" {
+		utils.Fatalf("Unknown report format %q, want csv or json", format)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	// Unlock the signer first, so a wrong password does not waste the replay
+	ks := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+	account, _ := unlockAccount(ks, signer, 0, utils.MakePasswordList(ctx))
+
+	chain, db := utils.MakeChain(ctx, stack)
+	defer db.Close()
+	defer chain.Stop()
+
+	from, to := ctx.Uint64(pocrAuditFromFlag.Name), chain.CurrentBlock().NumberU64()
+	if ctx.IsSet(pocrAuditToFlag.Name) {
+		to = ctx.Uint64(pocrAuditToFlag.Name)
+	}
+	if from == 0 || from > to || to > chain.CurrentBlock().NumberU64() {
+		utils.Fatalf("Invalid block range %d-%d, the head block is %d", from, to, chain.CurrentBlock().NumberU64())
+	}
+	auditor, err := cliquepocr.NewAuditor(chain, db)
+	if err != nil {
+		utils.Fatalf("Failed to audit the chain: %v", err)
+	}
+	report := &pocrAuditReport{ChainID: chain.Config().ChainID.String(), From: from, To: to}
+
+	start, logged := time.Now(), time.Now()
+	for number := from; number <= to; number++ {
+		audit, err := auditor.AuditBlock(number)
+		if err != nil {
+			utils.Fatalf("Failed to audit block %d: %v", number, err)
+		}
+		for _, mismatch := range audit.Mismatches {
+			log.Warn("PoCR reward mismatch", "number", number, "hash", audit.Hash, "mismatch", mismatch)
+		}
+		if len(audit.Mismatches) > 0 {
+			report.Mismatches++
+		}
+		report.Blocks = append(report.Blocks, newPoCRAuditedBlock(audit))
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Auditing PoCR rewards", "number", number, "to", to, "mismatches", report.Mismatches, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	data, err := report.encode(format)
+	if err != nil {
+		utils.Fatalf("Failed to encode the report: %v", err)
+	}
+	signature, err := ks.SignHash(account, accounts.TextHash(data))
+	if err != nil {
+		utils.Fatalf("Failed to sign the report: %v", err)
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		utils.Fatalf("Failed to write the report: %v", err)
+	}
+	if err := os.WriteFile(output+".sig", []byte(hexutil.Encode(signature)+"\n"), 0644); err != nil {
+		utils.Fatalf("Failed to write the report signature: %v", err)
+	}
+	log.Info("Audited PoCR rewards", "from", from, "to", to, "mismatches", report.Mismatches, "signer", account.Address, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	if report.Mismatches > 0 {
+		return fmt.Errorf("%d blocks do not match their replay, see %s", report.Mismatches, output)
+	}
+	return nil
+}
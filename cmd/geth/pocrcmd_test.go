@@ -0,0 +1,433 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/cliquepocr"
+	"github.com/ethereum/go-ethereum/consensus/cliquepocr/contracts"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var pocrGenesisDescriptions = []string{
+	// YAML description
+	`
+sealers:
+  - address: 0x0000000000000000000000000000000000000002
+    footprint: 3000
+    auditBlock: 0
+  - address: "0x0000000000000000000000000000000000000001"
+    footprint: 0x3e8
+    auditBlock: 5
+owner: "0x000000000000000000000000000000000000000f"
+governance:
+  RankDecay: 80
+`,
+	// JSON description
+	`{
+	"sealers": [
+		{"address": "0x0000000000000000000000000000000000000002", "footprint": 3000, "auditBlock": 0},
+		{"address": "0x0000000000000000000000000000000000000001", "footprint": 1000, "auditBlock": 5}
+	],
+	"owner": "0x000000000000000000000000000000000000000f",
+	"governance": {"RankDecay": 80}
+}`,
+}
+
+// Tests that the genesis command writes the PoCR contracts of a description
+// into a genesis file, whose footprints read back through the contract getters.
+func TestPoCRGenesis(t *testing.T) {
+	t.Parallel()
+
+	owner := common.HexToAddress("0x0f")
+	want := &cliquepocr.GenesisContracts{
+		Sealers: []cliquepocr.GenesisSealer{
+			{Address: common.HexToAddress("0x02"), Footprint: big.NewInt(3000), AuditBlock: big.NewInt(0)},
+			{Address: common.HexToAddress("0x01"), Footprint: big.NewInt(1000), AuditBlock: big.NewInt(5)},
+		},
+		Owner:      &owner,
+		Governance: map[string]*big.Int{"RankDecay": big.NewInt(80)},
+	}
+	wantAlloc, err := want.Alloc()
+	if err != nil {
+		t.Fatalf("failed to build the genesis alloc: %v", err)
+	}
+	for i, description := range pocrGenesisDescriptions {
+		datadir := t.TempDir()
+
+		descriptionPath := filepath.Join(datadir, "pocr.yml")
+		if err := os.WriteFile(descriptionPath, []byte(description), 0600); err != nil {
+			t.Fatalf("test %d: failed to write description: %v", i, err)
+		}
+		genesisPath := filepath.Join(datadir, "genesis.json")
+		if err := os.WriteFile(genesisPath, []byte(customGenesisTests[0].genesis), 0600); err != nil {
+			t.Fatalf("test %d: failed to write genesis file: %v", i, err)
+		}
+		geth := runGeth(t, "--datadir", datadir, "pocr", "genesis", descriptionPath, genesisPath)
+		output := geth.Output()
+		geth.WaitExit()
+		if geth.ExitStatus() != 0 {
+			t.Fatalf("test %d: command failed: %s", i, geth.StderrText())
+		}
+		genesis := new(core.Genesis)
+		if err := json.Unmarshal(output, genesis); err != nil {
+			t.Fatalf("test %d: invalid genesis output: %v", i, err)
+		}
+		if genesis.Nonce != 0x1338 {
+			t.Errorf("test %d: genesis nonce mismatch: have %#x, want 0x1338", i, genesis.Nonce)
+		}
+		if !bytes.Equal(genesis.ExtraData, want.ExtraData()) {
+			t.Errorf("test %d: extra-data mismatch: have %x, want %x", i, genesis.ExtraData, want.ExtraData())
+		}
+		if genesis.Config == nil || genesis.Config.Clique == nil || !genesis.Config.Clique.IsFootprintStorage(common.Big0) {
+			t.Fatalf("test %d: footprint storage fork not scheduled at genesis", i)
+		}
+		if !genesis.Config.Clique.PoCR {
+			t.Errorf("test %d: PoCR engine not enabled", i)
+		}
+		for address, account := range wantAlloc {
+			have := genesis.Alloc[address]
+			if !bytes.Equal(have.Code, account.Code) {
+				t.Errorf("test %d: code of %x mismatch", i, address)
+			}
+			if have.Balance == nil || have.Balance.Cmp(account.Balance) != 0 {
+				t.Errorf("test %d: balance of %x mismatch: have %v, want %v", i, address, have.Balance, account.Balance)
+			}
+			if !reflect.DeepEqual(have.Storage, account.Storage) {
+				t.Errorf("test %d: storage of %x mismatch: have %v, want %v", i, address, have.Storage, account.Storage)
+			}
+		}
+		// Read the registry of the genesis back through its getters
+		backend := backends.NewSimulatedBackend(genesis.Alloc, 10000000)
+		registry, _ := contracts.NewCliquePocr(contracts.CliquePocrAddress, backend)
+		for _, sealer := range want.Sealers {
+			if have, err := registry.Footprint(nil, sealer.Address); err != nil || have.Cmp(sealer.Footprint) != 0 {
+				t.Errorf("test %d: footprint of %x mismatch: have %v (%v), want %v", i, sealer.Address, have, err, sealer.Footprint)
+			}
+		}
+		if have, err := registry.NbNodes(nil); err != nil || have.Int64() != 2 {
+			t.Errorf("test %d: registry nbNodes mismatch: have %v (%v), want 2", i, have, err)
+		}
+		if have, err := registry.TotalFootprint(nil); err != nil || have.Int64() != 4000 {
+			t.Errorf("test %d: total footprint mismatch: have %v (%v), want 4000", i, have, err)
+		}
+		if have, err := registry.Owner(nil); err != nil || have != owner {
+			t.Errorf("test %d: owner mismatch: have %x (%v), want %x", i, have, err, owner)
+		}
+		backend.Close()
+	}
+}
+
+// Tests that the genesis command rejects the invalid descriptions.
+func TestPoCRGenesisInvalid(t *testing.T) {
+	t.Parallel()
+
+	for i, description := range []string{
+		"sealers: []\n",
+		"sealers:\n  - address: \"0x0000000000000000000000000000000000000001\"\n    footprnt: 1000\n",
+		"sealers:\n  - address: \"0x0000000000000000000000000000000000000001\"\ngovernance:\n  AuditPenalty: 101\n",
+		"sealers:\n  - address: \"0x0000000000000000000000000000000000000001\"\n    auditBlock: 5\n",
+		"sealers:\n  - address: \"0x0000000000000000000000000000000000000001\"\nauditors:\n  - address: \"0x00000000000000000000000000000000000000a1\"\n",
+	} {
+		datadir := t.TempDir()
+
+		descriptionPath := filepath.Join(datadir, "pocr.yml")
+		if err := os.WriteFile(descriptionPath, []byte(description), 0600); err != nil {
+			t.Fatalf("test %d: failed to write description: %v", i, err)
+		}
+		genesisPath := filepath.Join(datadir, "genesis.json")
+		if err := os.WriteFile(genesisPath, []byte(customGenesisTests[0].genesis), 0600); err != nil {
+			t.Fatalf("test %d: failed to write genesis file: %v", i, err)
+		}
+		geth := runGeth(t, "--datadir", datadir, "pocr", "genesis", descriptionPath, genesisPath)
+		geth.ExpectRegexp("Fatal: Invalid PoCR description")
+		geth.WaitExit()
+		if geth.ExitStatus() == 0 {
+			t.Errorf("test %d: invalid description accepted", i)
+		}
+	}
+}
+
+// writePoCRArchive writes into the datadir an archive PoCR chain of the given
+// length, sealed by the given key, and returns its blocks.
+func writePoCRArchive(t *testing.T, datadir string, sealer common.Address, sign func(common.Hash) []byte, length int) []*types.Block {
+	chaindata := filepath.Join(datadir, "geth", "chaindata")
+	if err := os.MkdirAll(filepath.Join(chaindata, "ancient", "chain"), 0700); err != nil {
+		t.Fatalf("failed to create freezer directory: %v", err)
+	}
+	db, err := rawdb.NewLevelDBDatabaseWithFreezer(chaindata, 0, 0, filepath.Join(chaindata, "ancient"), "", false)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	config := *params.AllCliqueProtocolChanges
+	// The built contracts are read from their storage, from the footprint storage fork on
+	config.Clique = &params.CliqueConfig{Period: 0, Epoch: 30000, PoCR: true, FootprintStorageBlock: new(big.Int)}
+	contracts := &cliquepocr.GenesisContracts{
+		Sealers: []cliquepocr.GenesisSealer{{Address: sealer, Footprint: big.NewInt(1000)}},
+	}
+	alloc, err := contracts.Alloc()
+	if err != nil {
+		t.Fatalf("failed to build the genesis alloc: %v", err)
+	}
+	genesis := &core.Genesis{Config: &config, ExtraData: contracts.ExtraData(), Alloc: alloc, BaseFee: big.NewInt(params.InitialBaseFee)}
+	parent := genesis.MustCommit(db)
+
+	engine := cliquepocr.New(config.Clique, db)
+	engine.Authorize(sealer, nil)
+	chain, err := core.NewBlockChain(db, &core.CacheConfig{TrieCleanLimit: 16, TrieDirtyDisabled: true}, &config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	var blocks []*types.Block
+	for i := 0; i < length; i++ {
+		generated, _ := core.GenerateChain(&config, parent, engine, db, 1, func(_ int, block *core.BlockGen) {
+			block.SetDifficulty(big.NewInt(2))
+			block.SetExtra(make([]byte, 32+crypto.SignatureLength))
+		})
+		header := generated[0].Header()
+		copy(header.Extra[len(header.Extra)-crypto.SignatureLength:], sign(engine.SealHash(header)))
+		parent = generated[0].WithSeal(header)
+
+		if _, err := chain.InsertChain(types.Blocks{parent}); err != nil {
+			t.Fatalf("failed to insert block %d: %v", parent.NumberU64(), err)
+		}
+		blocks = append(blocks, parent)
+	}
+	return blocks
+}
+
+// Tests that the audit command replays the stored chain, reports the altered
+// reward records and signs its report.
+func TestPoCRAudit(t *testing.T) {
+	t.Parallel()
+
+	var (
+		datadir  = t.TempDir()
+		password = filepath.Join(datadir, "password.txt")
+	)
+	if err := os.WriteFile(password, []byte("foobar"), 0600); err != nil {
+		t.Fatalf("failed to write password file: %v", err)
+	}
+	// The sealer and the auditor are distinct accounts of the keystore
+	sealer, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	auditor, err := keystore.StoreKey(filepath.Join(datadir, "keystore"), "foobar", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	blocks := writePoCRArchive(t, datadir, crypto.PubkeyToAddress(sealer.PublicKey), func(hash common.Hash) []byte {
+		sig, _ := crypto.Sign(hash.Bytes(), sealer)
+		return sig
+	}, 3)
+
+	// Alter the reward record of the second block
+	chaindata := filepath.Join(datadir, "geth", "chaindata")
+	db, err := rawdb.NewLevelDBDatabaseWithFreezer(chaindata, 0, 0, filepath.Join(chaindata, "ancient"), "", false)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	record := rawdb.ReadPoCRReward(db, blocks[1].Hash(), 2)
+	record.BlockReward = new(big.Int).Add(record.BlockReward, big.NewInt(1))
+	rawdb.WritePoCRReward(db, blocks[1].Hash(), 2, record)
+	db.Close()
+
+	// checkSignature checks that the report is signed by the auditor
+	checkSignature := func(report string) []byte {
+		data, err := os.ReadFile(report)
+		if err != nil {
+			t.Fatalf("failed to read report: %v", err)
+		}
+		sig, err := os.ReadFile(report + ".sig")
+		if err != nil {
+			t.Fatalf("failed to read report signature: %v", err)
+		}
+		pub, err := crypto.SigToPub(accounts.TextHash(data), hexutil.MustDecode(strings.TrimSpace(string(sig))))
+		if err != nil {
+			t.Fatalf("invalid report signature: %v", err)
+		}
+		if signer := crypto.PubkeyToAddress(*pub); signer != auditor.Address {
+			t.Errorf("report signer mismatch: have %x, want %x", signer, auditor.Address)
+		}
+		return data
+	}
+	// The full range holds the altered record
+	report := filepath.Join(datadir, "audit.csv")
+	geth := runGeth(t, "--datadir", datadir, "pocr", "audit", "--output", report, "--auditor", auditor.Address.Hex(), "--password", password)
+	geth.WaitExit()
+	if geth.ExitStatus() == 0 {
+		t.Fatalf("audit of an altered record succeeded")
+	}
+	rows, err := csv.NewReader(bytes.NewReader(checkSignature(report))).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV report: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("report rows mismatch: have %d, want 4", len(rows))
+	}
+	for i, row := range rows[1:] {
+		if row[1] != blocks[i].Hash().Hex() {
+			t.Errorf("row %d: hash mismatch: have %s, want %x", i, row[1], blocks[i].Hash())
+		}
+		if mismatch := row[len(row)-1]; (i == 1) != strings.HasPrefix(mismatch, "recorded block reward") {
+			t.Errorf("row %d: unexpected mismatches %q", i, mismatch)
+		}
+	}
+	// The last block alone is fine
+	report = filepath.Join(datadir, "audit.json")
+	geth = runGeth(t, "--datadir", datadir, "pocr", "audit", "--from", "3", "--to", "3", "--format", "json", "--output", report, "--auditor", auditor.Address.Hex(), "--password", password)
+	geth.WaitExit()
+	if geth.ExitStatus() != 0 {
+		t.Fatalf("audit failed: %s", geth.StderrText())
+	}
+	var result pocrAuditReport
+	if err := json.Unmarshal(checkSignature(report), &result); err != nil {
+		t.Fatalf("invalid JSON report: %v", err)
+	}
+	if result.From != 3 || result.To != 3 || result.Mismatches != 0 || len(result.Blocks) != 1 {
+		t.Fatalf("report mismatch: %+v", result)
+	}
+	if result.Blocks[0].Hash != blocks[2].Hash() || result.Blocks[0].BlockReward == "" || result.Blocks[0].BlockReward == "0" {
+		t.Errorf("block report mismatch: %+v", result.Blocks[0])
+	}
+}
+
+// pocrCommittingBackend is a simulated backend mining every transaction at once,
+// so the governance commands can wait for their inclusion.
+type pocrCommittingBackend struct {
+	*backends.SimulatedBackend
+}
+
+func (b pocrCommittingBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := b.SimulatedBackend.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	b.Commit()
+	return nil
+}
+
+// Tests the governance commands: the footprint submissions, the hand over of
+// the contract and its status.
+func TestPoCRGovernance(t *testing.T) {
+	var (
+		ks       = keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+		keys     = make(map[string]accounts.Account)
+		alloc    = make(core.GenesisAlloc)
+		contract cliquepocr.GenesisContracts
+	)
+	for _, name := range []string{"sealer", "sealer2", "owner", "outsider"} {
+		key, _ := crypto.GenerateKey()
+		account, err := ks.ImportECDSA(key, "")
+		if err != nil {
+			t.Fatalf("failed to import key: %v", err)
+		}
+		ks.Unlock(account, "")
+		keys[name] = account
+		alloc[account.Address] = core.GenesisAccount{Balance: new(big.Int).Mul(big.NewInt(10000), big.NewInt(params.Ether))}
+	}
+	contract.Sealers = []cliquepocr.GenesisSealer{
+		{Address: keys["sealer"].Address},
+		{Address: keys["sealer2"].Address},
+	}
+	contractAlloc, err := contract.Alloc()
+	if err != nil {
+		t.Fatalf("failed to create alloc: %v", err)
+	}
+	for address, account := range contractAlloc {
+		alloc[address] = account
+	}
+	backend := pocrCommittingBackend{backends.NewSimulatedBackend(alloc, 10000000)}
+	defer backend.Close()
+
+	governance := func(name string) *pocrGovernance {
+		opts, err := bind.NewKeyStoreTransactorWithChainID(ks, keys[name], backend.Blockchain().Config().ChainID)
+		if err != nil {
+			t.Fatalf("failed to create transactor: %v", err)
+		}
+		g, err := newPoCRGovernance(backend, opts)
+		if err != nil {
+			t.Fatalf("failed to bind contract: %v", err)
+		}
+		return g
+	}
+	transact := func(name string, send func(g *pocrGovernance, opts *bind.TransactOpts) (*types.Transaction, error)) {
+		t.Helper()
+		g := governance(name)
+		if _, _, err := g.transact(func(opts *bind.TransactOpts) (*types.Transaction, error) { return send(g, opts) }); err != nil {
+			t.Fatalf("transaction of %s failed: %v", name, err)
+		}
+	}
+	// The owner takes the contract, which ignores the later changes of others
+	transact("owner", func(g *pocrGovernance, opts *bind.TransactOpts) (*types.Transaction, error) {
+		return g.contract.SetOwner(opts, keys["owner"].Address)
+	})
+	transact("outsider", func(g *pocrGovernance, opts *bind.TransactOpts) (*types.Transaction, error) {
+		return g.contract.SetOwner(opts, keys["outsider"].Address)
+	})
+	// The footprints are submitted, updated and removed
+	for _, update := range []struct {
+		sealer    string
+		footprint int64
+	}{{"sealer", 1000}, {"sealer2", 500}, {"sealer2", 2000}, {"sealer", 0}} {
+		transact("owner", func(g *pocrGovernance, opts *bind.TransactOpts) (*types.Transaction, error) {
+			return g.contract.SetFootprint(opts, keys[update.sealer].Address, big.NewInt(update.footprint))
+		})
+	}
+	status, err := governance("sealer").status(keys["sealer2"].Address)
+	if err != nil {
+		t.Fatalf("failed to read the status: %v", err)
+	}
+	if status.Owner != keys["owner"].Address {
+		t.Errorf("owner mismatch: have %x, want %x", status.Owner, keys["owner"].Address)
+	}
+	if status.NbNodes.Int64() != 1 || status.TotalFootprint.Int64() != 2000 || status.Footprint.Int64() != 2000 {
+		t.Errorf("status mismatch: %d nodes, total %v, footprint %v, want 1, 2000 and 2000", status.NbNodes, status.TotalFootprint, status.Footprint)
+	}
+	var out bytes.Buffer
+	status.print(&out)
+	for _, want := range []string{"Owner: " + keys["owner"].Address.Hex(), "Nodes with a footprint: 1, total footprint 2000", keys["sealer2"].Address.Hex() + ": footprint 2000"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("status output misses %q:\n%s", want, out.String())
+		}
+	}
+}
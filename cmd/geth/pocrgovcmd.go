@@ -0,0 +1,326 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/consensus/cliquepocr/contracts"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	pocrEndpointFlag = &cli.StringFlag{
+		Name:  "endpoint",
+		Usage: "RPC endpoint of the node (default = IPC endpoint of the datadir)",
+	}
+	pocrAccountFlag = &cli.StringFlag{
+		Name:  "account",
+		Usage: "Account of the keystore sending the transactions",
+	}
+	pocrNoWaitFlag = &cli.BoolFlag{
+		Name:  "nowait",
+		Usage: "Do not wait for the transactions to be included in a block",
+	}
+
+	// pocrReadFlags are the flags of the commands reading the PoCR contract.
+	pocrReadFlags = []cli.Flag{
+		utils.DataDirFlag,
+		pocrEndpointFlag,
+		pocrAccountFlag,
+	}
+	// pocrTransactFlags are the flags of the commands sending transactions to
+	// the PoCR contract.
+	pocrTransactFlags = flags.Merge(pocrReadFlags, []cli.Flag{
+		utils.KeyStoreDirFlag,
+		utils.PasswordFileFlag,
+		utils.LightKDFFlag,
+		pocrNoWaitFlag,
+	})
+
+	pocrFootprintCommand = &cli.Command{
+		Name:      "footprint",
+		Usage:     "Submit the audited carbon footprint of a node",
+		ArgsUsage: "<node> <footprint>",
+		Action:    pocrSetFootprint,
+		Flags:     pocrTransactFlags,
+		Description: `
+geth pocr footprint <node> <footprint>
+sets the carbon footprint of a node, zero removing it. The genesis contract does
+not restrict the accounts allowed to set the footprints.`,
+	}
+	pocrOwnerCommand = &cli.Command{
+		Name:      "owner",
+		Usage:     "Hand over the PoCR contract",
+		ArgsUsage: "<owner>",
+		Action:    pocrSetOwner,
+		Flags:     pocrTransactFlags,
+		Description: `
+geth pocr owner <owner>
+sets the owner of the PoCR contract. The account must be the current owner, or
+any account while the contract has no owner; the contract ignores the other
+accounts without reverting.`,
+	}
+	pocrStatusCommand = &cli.Command{
+		Name:   "status",
+		Usage:  "Show the content of the PoCR contract",
+		Action: pocrShowStatus,
+		Flags:  pocrReadFlags,
+		Description: `
+geth pocr status
+shows the owner of the PoCR contract, the number of nodes having a footprint
+and their total footprint. With --account, it also shows the footprint of the
+account.`,
+	}
+)
+
+// pocrBackend is the connection to the node the governance commands use.
+type pocrBackend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+}
+
+// pocrGovernance drives the PoCR contract through the bindings.
+type pocrGovernance struct {
+	backend  pocrBackend
+	contract *contracts.CliquePocr
+	opts     *bind.TransactOpts // Signer of the transactions, nil if read only
+	wait     bool               // Whether to wait for the inclusion of the transactions
+}
+
+func newPoCRGovernance(backend pocrBackend, opts *bind.TransactOpts) (*pocrGovernance, error) {
+	contract, err := contracts.NewCliquePocr(contracts.CliquePocrAddress, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &pocrGovernance{
+		backend:  backend,
+		contract: contract,
+		opts:     opts,
+		wait:     true,
+	}, nil
+}
+
+// transact sends a transaction to the PoCR contract and waits for its inclusion.
+// The gas estimation runs the transaction first, so the transactions the
+// contract rejects are not sent and the reason of the revert is returned.
+func (g *pocrGovernance) transact(send func(opts *bind.TransactOpts) (*types.Transaction, error)) (*types.Transaction, *types.Receipt, error) {
+	if g.opts == nil {
+		return nil, nil, errors.New("no account to send the transaction")
+	}
+	opts := *g.opts
+	tx, err := send(&opts)
+	if err != nil {
+		return nil, nil, contracts.UnpackRevertError(err)
+	}
+	if !g.wait {
+		return tx, nil, nil
+	}
+	receipt, err := bind.WaitMined(context.Background(), g.backend, tx)
+	if err != nil {
+		return tx, nil, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return tx, receipt, fmt.Errorf("transaction %x failed in block %d", tx.Hash(), receipt.BlockNumber)
+	}
+	return tx, receipt, nil
+}
+
+// pocrStatus is the content of the PoCR contract, and the footprint of an
+// account.
+type pocrStatus struct {
+	Owner          common.Address
+	NbNodes        *big.Int // Number of nodes having a footprint
+	TotalFootprint *big.Int
+	Account        common.Address
+	Footprint      *big.Int
+}
+
+// status reads the content of the PoCR contract, with the footprint of the given
+// account.
+func (g *pocrGovernance) status(account common.Address) (*pocrStatus, error) {
+	var (
+		opts   = &bind.CallOpts{From: account}
+		status = &pocrStatus{Account: account}
+		err    error
+	)
+	if status.Owner, err = g.contract.Owner(opts); err != nil {
+		return nil, err
+	}
+	if status.NbNodes, err = g.contract.NbNodes(opts); err != nil {
+		return nil, err
+	}
+	if status.TotalFootprint, err = g.contract.TotalFootprint(opts); err != nil {
+		return nil, err
+	}
+	if status.Footprint, err = g.contract.Footprint(opts, account); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// print writes the status in a human readable form.
+func (status *pocrStatus) print(w io.Writer) {
+	fmt.Fprintf(w, "Owner: %s\n", status.Owner.Hex())
+	fmt.Fprintf(w, "Nodes with a footprint: %v, total footprint %v\n", status.NbNodes, status.TotalFootprint)
+	if status.Account != (common.Address{}) {
+		fmt.Fprintf(w, "Account %s: footprint %v\n", status.Account.Hex(), status.Footprint)
+	}
+}
+
+// pocrDialGovernance connects to the node, and unlocks the account of the
+// keystore if the command sends transactions.
+func pocrDialGovernance(ctx *cli.Context, transact bool) *pocrGovernance {
+	cfg := defaultNodeConfig()
+	utils.SetDataDir(ctx, &cfg)
+
+	endpoint := ctx.String(pocrEndpointFlag.Name)
+	if endpoint == "" {
+		endpoint = cfg.IPCEndpoint()
+	}
+	client, err := dialRPC(endpoint)
+	if err != nil {
+		utils.Fatalf("Unable to attach to the node: %v", err)
+	}
+	backend := ethclient.NewClient(client)
+
+	var opts *bind.TransactOpts
+	if transact {
+		address := ctx.String(pocrAccountFlag.Name)
+		if address == "" {
+			utils.Fatalf("The account sending the transactions (--%s) is required.", pocrAccountFlag.Name)
+		}
+		if ctx.IsSet(utils.KeyStoreDirFlag.Name) {
+			cfg.KeyStoreDir = ctx.String(utils.KeyStoreDirFlag.Name)
+		}
+		keydir, err := cfg.KeyDirConfig()
+		if err != nil {
+			utils.Fatalf("Failed to locate the keystore: %v", err)
+		}
+		scryptN, scryptP := keystore.StandardScryptN, keystore.StandardScryptP
+		if ctx.Bool(utils.LightKDFFlag.Name) {
+			scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
+		}
+		ks := keystore.NewKeyStore(keydir, scryptN, scryptP)
+		account, _ := unlockAccount(ks, address, 0, utils.MakePasswordList(ctx))
+
+		chainID, err := backend.ChainID(context.Background())
+		if err != nil {
+			utils.Fatalf("Failed to retrieve the chain id: %v", err)
+		}
+		if opts, err = bind.NewKeyStoreTransactorWithChainID(ks, account, chainID); err != nil {
+			utils.Fatalf("Failed to create the transactor: %v", err)
+		}
+	}
+	governance, err := newPoCRGovernance(backend, opts)
+	if err != nil {
+		utils.Fatalf("Failed to bind the PoCR contract: %v", err)
+	}
+	governance.wait = !ctx.Bool(pocrNoWaitFlag.Name)
+	return governance
+}
+
+// pocrTransact connects to the node and sends the transaction of a command.
+func pocrTransact(ctx *cli.Context, action string, send func(g *pocrGovernance, opts *bind.TransactOpts) (*types.Transaction, error)) error {
+	g := pocrDialGovernance(ctx, true)
+	tx, receipt, err := g.transact(func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return send(g, opts)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+	if receipt == nil {
+		fmt.Printf("Transaction %s sent\n", tx.Hash().Hex())
+	} else {
+		fmt.Printf("Transaction %s included in block %v\n", tx.Hash().Hex(), receipt.BlockNumber)
+	}
+	return nil
+}
+
+// pocrArgs checks the number of arguments of a command.
+func pocrArgs(ctx *cli.Context, n int) {
+	if ctx.Args().Len() != n {
+		utils.Fatalf("This command requires %d arguments, see --help.", n)
+	}
+}
+
+// pocrAddressArg parses an address argument.
+func pocrAddressArg(ctx *cli.Context, i int) common.Address {
+	arg := ctx.Args().Get(i)
+	if !common.IsHexAddress(arg) {
+		utils.Fatalf("Invalid address %q", arg)
+	}
+	return common.HexToAddress(arg)
+}
+
+// pocrNumberArg parses a decimal or hexadecimal number argument.
+func pocrNumberArg(ctx *cli.Context, i int) *big.Int {
+	arg := ctx.Args().Get(i)
+	number, ok := math.ParseBig256(arg)
+	if !ok {
+		utils.Fatalf("Invalid number %q", arg)
+	}
+	return number
+}
+
+func pocrSetFootprint(ctx *cli.Context) error {
+	pocrArgs(ctx, 2)
+	node, footprint := pocrAddressArg(ctx, 0), pocrNumberArg(ctx, 1)
+	return pocrTransact(ctx, "set the footprint", func(g *pocrGovernance, opts *bind.TransactOpts) (*types.Transaction, error) {
+		return g.contract.SetFootprint(opts, node, footprint)
+	})
+}
+
+func pocrSetOwner(ctx *cli.Context) error {
+	pocrArgs(ctx, 1)
+	owner := pocrAddressArg(ctx, 0)
+	return pocrTransact(ctx, "set the owner", func(g *pocrGovernance, opts *bind.TransactOpts) (*types.Transaction, error) {
+		return g.contract.SetOwner(opts, owner)
+	})
+}
+
+func pocrShowStatus(ctx *cli.Context) error {
+	pocrArgs(ctx, 0)
+	g := pocrDialGovernance(ctx, false)
+
+	var account common.Address
+	if address := ctx.String(pocrAccountFlag.Name); address != "" {
+		if !common.IsHexAddress(address) {
+			utils.Fatalf("Invalid account %q", address)
+		}
+		account = common.HexToAddress(address)
+	}
+	status, err := g.status(account)
+	if err != nil {
+		return fmt.Errorf("failed to read the contract: %w", contracts.UnpackRevertError(err))
+	}
+	status.print(os.Stdout)
+	return nil
+}
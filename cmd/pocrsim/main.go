@@ -0,0 +1,122 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// pocrsim projects the proof-of-carbon-reduction rewards and the crypto supply
+// of a chain over a synthetic schedule of sealers, audits and fees.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/urfave/cli/v2"
+)
+
+// Git SHA1 commit hash of the release (set via linker flags)
+var gitCommit = ""
+var gitDate = ""
+
+var app *cli.App
+
+var (
+	formatFlag = &cli.StringFlag{
+		Name:  "format",
+		Usage: "output format of the time series (csv or json)",
+		Value: "csv",
+	}
+	outputFlag = &cli.StringFlag{
+		Name:  "out",
+		Usage: "file to write the time series to (default stdout)",
+	}
+)
+
+func init() {
+	app = flags.NewApp(gitCommit, gitDate, "a PoCR reward and supply simulator")
+	app.ArgsUsage = "<schedule.json>"
+	app.Flags = []cli.Flag{formatFlag, outputFlag}
+	app.Action = simulate
+	app.Description = `
+Simulates the PoCR rewards of a chain sealed by the sealers of the given
+schedule, with the reward algorithms of the node and without running one.
+
+The schedule is a JSON file:
+
+  {
+    "config":      chain configuration (as in a genesis file, defaults to a 4s clique chain),
+    "blocks":      number of blocks to simulate,
+    "step":        blocks simulated at once sharing the same state (default 1, exact),
+    "sample":      blocks between two rows of the time series (default step),
+    "totalCrypto": initial GeneratedPocRTotal in wei,
+    "fees":        fees received by the sealer of every block in wei,
+    "burnt":       fees burnt by every block in wei,
+    "sealers": [{
+      "address": sealer address,
+      "join":    first block sealed (default 0),
+      "leave":   block the sealer is removed at (default never),
+      "audits":  [{"block": audit block, "footprint": audited footprint}]
+    }]
+  }
+
+The time series gives every sample the inflation control factor, the total
+generated crypto (GeneratedPocRTotal) and the cumulated reward of each sealer.`
+}
+
+func main() {
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func simulate(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("need the schedule file as the only argument")
+	}
+	data, err := os.ReadFile(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	sched := new(schedule)
+	if err := json.Unmarshal(data, sched); err != nil {
+		return fmt.Errorf("invalid schedule: %v", err)
+	}
+	var out io.Writer = os.Stdout
+	if path := ctx.String(outputFlag.Name); path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	var w seriesWriter
+	switch format := ctx.String(formatFlag.Name); format {
+	case "csv":
+		w = newCSVWriter(out, sched.addresses())
+	case "json":
+		w = newJSONWriter(out)
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+	if err := sched.run(w); err != nil {
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,267 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/consensus/cliquepocr"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// ratPrecision is the number of decimals of the inflation factor in the output.
+const ratPrecision = 18
+
+// schedule is the synthetic life of a chain to simulate.
+type schedule struct {
+	Config      *params.ChainConfig   `json:"config"`
+	Blocks      uint64                `json:"blocks"`
+	Step        uint64                `json:"step"`
+	Sample      uint64                `json:"sample"`
+	TotalCrypto *math.HexOrDecimal256 `json:"totalCrypto"`
+	Fees        *math.HexOrDecimal256 `json:"fees"`
+	Burnt       *math.HexOrDecimal256 `json:"burnt"`
+	Sealers     []*sealerSchedule     `json:"sealers"`
+}
+
+// sealerSchedule is the membership and the audits of a simulated sealer.
+type sealerSchedule struct {
+	Address common.Address `json:"address"`
+	Join    uint64         `json:"join"`
+	Leave   uint64         `json:"leave"` // 0 if the sealer never leaves
+	Audits  []audit        `json:"audits"`
+}
+
+// audit is a footprint registered for a sealer from a given block on.
+type audit struct {
+	Block     uint64                `json:"block"`
+	Footprint *math.HexOrDecimal256 `json:"footprint"`
+}
+
+// sample is a row of the simulated time series.
+type sample struct {
+	Block           uint64                    `json:"block"`
+	Time            uint64                    `json:"time"` // seconds since the genesis
+	InflationFactor string                    `json:"inflationFactor"`
+	TotalCrypto     *big.Int                  `json:"totalCrypto"`
+	Rewards         map[common.Address]string `json:"rewards"` // cumulated block rewards per sealer
+}
+
+// seriesWriter outputs the samples of a simulation.
+type seriesWriter interface {
+	Write(s *sample) error
+	Close() error
+}
+
+// addresses returns the addresses of the scheduled sealers, in clique order.
+func (s *schedule) addresses() []common.Address {
+	addrs := make([]common.Address, 0, len(s.Sealers))
+	for _, sealer := range s.Sealers {
+		addrs = append(addrs, sealer.Address)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+	return addrs
+}
+
+// sealersAt returns the sealers of the given block in clique order, with the
+// footprint of their last audit.
+func (s *schedule) sealersAt(number uint64) []cliquepocr.SimSealer {
+	var sealers []cliquepocr.SimSealer
+	for _, sealer := range s.Sealers {
+		if number < sealer.Join || (sealer.Leave != 0 && number >= sealer.Leave) {
+			continue
+		}
+		current := cliquepocr.SimSealer{Address: sealer.Address, Footprint: new(big.Int), FootprintBlock: new(big.Int)}
+		for _, audit := range sealer.Audits {
+			if audit.Block <= number && audit.Block >= current.FootprintBlock.Uint64() {
+				current.Footprint = (*big.Int)(audit.Footprint)
+				current.FootprintBlock = new(big.Int).SetUint64(audit.Block)
+			}
+		}
+		sealers = append(sealers, current)
+	}
+	sort.Slice(sealers, func(i, j int) bool { return bytes.Compare(sealers[i].Address[:], sealers[j].Address[:]) < 0 })
+	return sealers
+}
+
+// validate checks the schedule and fills in the defaults.
+func (s *schedule) validate() error {
+	if s.Config == nil {
+		config := *params.AllCliqueProtocolChanges
+		config.Clique = &params.CliqueConfig{Period: params.DefaultPoCRPeriod, Epoch: 30000}
+		s.Config = &config
+	}
+	if s.Blocks == 0 {
+		return errors.New("no block to simulate")
+	}
+	if s.Step == 0 {
+		s.Step = 1
+	}
+	if s.Sample == 0 {
+		s.Sample = s.Step
+	}
+	for _, value := range []**math.HexOrDecimal256{&s.TotalCrypto, &s.Fees, &s.Burnt} {
+		if *value == nil {
+			*value = new(math.HexOrDecimal256)
+		}
+	}
+	for _, sealer := range s.Sealers {
+		for _, audit := range sealer.Audits {
+			if audit.Footprint == nil || (*big.Int)(audit.Footprint).Sign() < 0 {
+				return fmt.Errorf("invalid footprint of sealer %x at block %d", sealer.Address, audit.Block)
+			}
+		}
+	}
+	return nil
+}
+
+// run simulates the schedule, writing a sample every s.Sample blocks. Every step,
+// the blocks are shared between the sealers in turn.
+func (s *schedule) run(w seriesWriter) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	sim, err := cliquepocr.NewSimulator(s.Config, (*big.Int)(s.TotalCrypto))
+	if err != nil {
+		return err
+	}
+	period := s.Config.Clique.Period
+	if period == 0 {
+		period = params.DefaultPoCRPeriod
+	}
+	var (
+		rewards    = make(map[common.Address]*big.Int)
+		inflation  = big.NewRat(1, 1)
+		nextSample = s.Sample
+	)
+	for _, addr := range s.addresses() {
+		rewards[addr] = new(big.Int)
+	}
+	for number := uint64(1); number <= s.Blocks; number += s.Step {
+		count := s.Step
+		if number+count-1 > s.Blocks {
+			count = s.Blocks - number + 1
+		}
+		sealers := s.sealersAt(number)
+		if len(sealers) == 0 {
+			return fmt.Errorf("no sealer at block %d", number)
+		}
+		// the in-turn sealer of block n is the n-th modulo the number of sealers
+		turns := make([]uint64, len(sealers))
+		for i := range turns {
+			turns[i] = count / uint64(len(sealers))
+		}
+		for i := uint64(0); i < count%uint64(len(sealers)); i++ {
+			turns[(number+i)%uint64(len(sealers))]++
+		}
+		for i, sealer := range sealers {
+			if turns[i] == 0 {
+				continue
+			}
+			block, err := sim.Blocks(number, turns[i], sealer.Address, sealers, (*big.Int)(s.Fees), (*big.Int)(s.Burnt))
+			if err != nil {
+				return fmt.Errorf("block %d: %v", number, err)
+			}
+			rewards[sealer.Address].Add(rewards[sealer.Address], block.BlockReward)
+			inflation = block.InflationFactor
+		}
+		last := number + count - 1
+		if last >= nextSample || last == s.Blocks {
+			row := &sample{
+				Block:           last,
+				Time:            last * period,
+				InflationFactor: inflation.FloatString(ratPrecision),
+				TotalCrypto:     sim.TotalCrypto(),
+				Rewards:         make(map[common.Address]string, len(rewards)),
+			}
+			for addr, reward := range rewards {
+				row.Rewards[addr] = reward.String()
+			}
+			if err := w.Write(row); err != nil {
+				return err
+			}
+			for nextSample <= last {
+				nextSample += s.Sample
+			}
+		}
+	}
+	return nil
+}
+
+// csvWriter outputs the samples as CSV, with a reward column per sealer.
+type csvWriter struct {
+	w       *csv.Writer
+	sealers []common.Address
+	header  bool
+}
+
+func newCSVWriter(out io.Writer, sealers []common.Address) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(out), sealers: sealers}
+}
+
+func (c *csvWriter) Write(s *sample) error {
+	if !c.header {
+		header := []string{"block", "time", "inflationFactor", "totalCrypto"}
+		for _, sealer := range c.sealers {
+			header = append(header, sealer.Hex())
+		}
+		if err := c.w.Write(header); err != nil {
+			return err
+		}
+		c.header = true
+	}
+	record := []string{fmt.Sprint(s.Block), fmt.Sprint(s.Time), s.InflationFactor, s.TotalCrypto.String()}
+	for _, sealer := range c.sealers {
+		record = append(record, s.Rewards[sealer])
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonWriter outputs the samples as a JSON array.
+type jsonWriter struct {
+	out     io.Writer
+	samples []*sample
+}
+
+func newJSONWriter(out io.Writer) *jsonWriter {
+	return &jsonWriter{out: out, samples: []*sample{}}
+}
+
+func (j *jsonWriter) Write(s *sample) error {
+	j.samples = append(j.samples, s)
+	return nil
+}
+
+func (j *jsonWriter) Close() error {
+	enc := json.NewEncoder(j.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(j.samples)
+}
@@ -0,0 +1,114 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const testSchedule = `{
+  "blocks": 100,
+  "sample": 25,
+  "totalCrypto": "1000000000000000000000",
+  "fees": "1000000000000000",
+  "sealers": [
+    {"address": "0x0000000000000000000000000000000000000001", "audits": [{"block": 0, "footprint": "1000"}]},
+    {"address": "0x0000000000000000000000000000000000000002", "audits": [{"block": 50, "footprint": "2000"}]},
+    {"address": "0x0000000000000000000000000000000000000003", "join": 20, "leave": 60, "audits": [{"block": 0, "footprint": "500"}]}
+  ]
+}`
+
+type recorder struct{ samples []*sample }
+
+func (r *recorder) Write(s *sample) error { r.samples = append(r.samples, s); return nil }
+func (r *recorder) Close() error          { return nil }
+
+func runSchedule(t *testing.T, step uint64) []*sample {
+	sched := new(schedule)
+	if err := json.Unmarshal([]byte(testSchedule), sched); err != nil {
+		t.Fatalf("failed to parse schedule: %v", err)
+	}
+	sched.Step = step
+	r := new(recorder)
+	if err := sched.run(r); err != nil {
+		t.Fatalf("failed to run schedule: %v", err)
+	}
+	return r.samples
+}
+
+func TestSchedule(t *testing.T) {
+	samples := runSchedule(t, 1)
+	if len(samples) != 4 || samples[3].Block != 100 || samples[3].Time != 400 {
+		t.Fatalf("samples mismatch: have %d ending at %d", len(samples), samples[len(samples)-1].Block)
+	}
+	var (
+		first  = common.HexToAddress("0x01")
+		second = common.HexToAddress("0x02")
+	)
+	// The second sealer is only rewarded once audited
+	if samples[1].Rewards[second] != "0" {
+		t.Errorf("unaudited sealer rewarded: %v", samples[1].Rewards[second])
+	}
+	if samples[3].Rewards[second] == "0" {
+		t.Errorf("audited sealer not rewarded")
+	}
+	// The supply only grows with the rewards, the unaudited sealer fees being taken away
+	total, _ := new(big.Int).SetString("1000000000000000000000", 10)
+	for _, reward := range samples[3].Rewards {
+		value, _ := new(big.Int).SetString(reward, 10)
+		total.Add(total, value)
+	}
+	if total.Cmp(samples[3].TotalCrypto) <= 0 {
+		t.Errorf("total crypto %v above the initial amount plus the rewards %v", samples[3].TotalCrypto, total)
+	}
+	if samples[0].Rewards[first] == "0" {
+		t.Errorf("audited sealer not rewarded")
+	}
+	// Simulating several blocks at once stays close to the exact simulation
+	approx := runSchedule(t, 5)
+	diff := new(big.Int).Sub(approx[3].TotalCrypto, samples[3].TotalCrypto)
+	if diff.Abs(diff).Cmp(new(big.Int).Div(samples[3].TotalCrypto, big.NewInt(20))) > 0 {
+		t.Errorf("approximation too far: have %v, want %v", approx[3].TotalCrypto, samples[3].TotalCrypto)
+	}
+}
+
+func TestCSVOutput(t *testing.T) {
+	sched := new(schedule)
+	if err := json.Unmarshal([]byte(testSchedule), sched); err != nil {
+		t.Fatalf("failed to parse schedule: %v", err)
+	}
+	var out bytes.Buffer
+	w := newCSVWriter(&out, sched.addresses())
+	if err := sched.run(w); err != nil {
+		t.Fatalf("failed to run schedule: %v", err)
+	}
+	w.Close()
+
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(records) != 5 || len(records[0]) != 7 {
+		t.Fatalf("CSV shape mismatch: have %dx%d, want 5x7", len(records), len(records[0]))
+	}
+}
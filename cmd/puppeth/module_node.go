@@ -19,6 +19,7 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"path/filepath"
@@ -27,6 +28,8 @@ import (
 	"text/template"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/cliquepocr"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -167,6 +170,7 @@ type nodeInfos struct {
 	gasTarget  float64
 	gasLimit   float64
 	gasPrice   float64
+	pocr       *cliquepocr.SignerRanking
 }
 
 // Report converts the typed struct into a plain string->string map, containing
@@ -201,6 +205,15 @@ func (info *nodeInfos) Report() map[string]string {
 				log.Error("Failed to retrieve signer address", "err", err)
 			}
 		}
+		if info.pocr != nil {
+			// Proof-of-carbon-reduction sealer, the standing reported to ethstats
+			report["PoCR footprint"] = info.pocr.Footprint.ToInt().String()
+			if info.pocr.FootprintBlock != nil {
+				report["PoCR last audit block"] = info.pocr.FootprintBlock.ToInt().String()
+			}
+			report["PoCR rank"] = info.pocr.Rank
+			report["PoCR next block reward"] = fmt.Sprintf("%s wei", info.pocr.Reward.ToInt())
+		}
 	}
 	return report
 }
@@ -246,6 +259,15 @@ func checkNode(client *sshClient, network string, boot bool) (*nodeInfos, error)
 	if out, err = client.Run(fmt.Sprintf("docker exec %s_%s_1 cat /signer.pass", network, kind)); err == nil {
 		keyPass = string(bytes.TrimSpace(out))
 	}
+	// Retrieve the standing of the signer of a PoCR sealnode
+	var pocr *cliquepocr.SignerRanking
+	if !boot && keyJSON != "" && isPoCRGenesis(genesis) {
+		if out, err = client.Run(fmt.Sprintf("docker exec %s_%s_1 geth --exec 'JSON.stringify(pocr.getSealerStanding(null))' --cache=16 attach", network, kind)); err == nil {
+			if pocr, err = parseSealerStanding(out); err != nil {
+				log.Warn("Failed to parse PoCR sealer standing", "server", client.server, "err", err)
+			}
+		}
+	}
 	// Run a sanity check to see if the devp2p is reachable
 	port := infos.portmap[infos.envvars["PORT"]]
 	if err = checkPort(client.server, port); err != nil {
@@ -266,8 +288,36 @@ func checkNode(client *sshClient, network string, boot bool) (*nodeInfos, error)
 		gasTarget:  gasTarget,
 		gasLimit:   gasLimit,
 		gasPrice:   gasPrice,
+		pocr:       pocr,
 	}
 	stats.enode = string(enode)
 
 	return stats, nil
 }
+
+// isPoCRGenesis reports whether the genesis is the one of a proof-of-carbon-
+// reduction chain.
+func isPoCRGenesis(genesis []byte) bool {
+	g := new(core.Genesis)
+	if err := json.Unmarshal(genesis, g); err != nil {
+		return false
+	}
+	return g.Config != nil && g.Config.Clique != nil && g.Config.Clique.PoCR
+}
+
+// parseSealerStanding decodes the sealer standing printed by the console, a
+// JSON document stringified as a JavaScript string.
+func parseSealerStanding(out []byte) (*cliquepocr.SignerRanking, error) {
+	var doc string
+	if err := json.Unmarshal(bytes.TrimSpace(out), &doc); err != nil {
+		return nil, err
+	}
+	standing := new(cliquepocr.SignerRanking)
+	if err := json.Unmarshal([]byte(doc), standing); err != nil {
+		return nil, err
+	}
+	if standing.Footprint == nil || standing.Reward == nil {
+		return nil, errors.New("incomplete sealer standing")
+	}
+	return standing, nil
+}
@@ -29,6 +29,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/cliquepocr"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
@@ -58,6 +59,7 @@ func (w *wizard) makeGenesis() {
 	fmt.Println("Which consensus engine to use? (default = clique)")
 	fmt.Println(" 1. Ethash - proof-of-work")
 	fmt.Println(" 2. Clique - proof-of-authority")
+	fmt.Println(" 3. Clique PoCR - proof-of-carbon-reduction")
 
 	choice := w.read()
 	switch {
@@ -81,16 +83,8 @@ func (w *wizard) makeGenesis() {
 		fmt.Println()
 		fmt.Println("Which accounts are allowed to seal? (mandatory at least one)")
 
-		var signers []common.Address
-		for {
-			if address := w.readAddress(); address != nil {
-				signers = append(signers, *address)
-				continue
-			}
-			if len(signers) > 0 {
-				break
-			}
-		}
+		signers := w.readSigners()
+
 		// Sort the signers and embed into the extra-data section
 		for i := 0; i < len(signers); i++ {
 			for j := i + 1; j < len(signers); j++ {
@@ -104,6 +98,63 @@ func (w *wizard) makeGenesis() {
 			copy(genesis.ExtraData[32+i*common.AddressLength:], signer[:])
 		}
 
+	case choice == "3":
+		// In the case of clique PoCR, configure clique along with the PoCR contracts
+		genesis.Difficulty = big.NewInt(1)
+		// The footprints are read from the storage of the contracts written
+		genesis.Config.Clique = &params.CliqueConfig{
+			Period:                4,
+			Epoch:                 30000,
+			PoCR:                  true,
+			FootprintStorageBlock: new(big.Int),
+		}
+		fmt.Println()
+		fmt.Println("How many seconds should blocks take? (default = 4)")
+		genesis.Config.Clique.Period = uint64(w.readDefaultInt(4))
+
+		fmt.Println()
+		fmt.Println("Which accounts are allowed to seal? (mandatory at least one)")
+
+		contracts := new(cliquepocr.GenesisContracts)
+		for _, signer := range w.readSigners() {
+			fmt.Println()
+			fmt.Printf("What is the audited carbon footprint of %s? (default = not audited)\n", signer.Hex())
+			contracts.Sealers = append(contracts.Sealers, cliquepocr.GenesisSealer{
+				Address:   signer,
+				Footprint: w.readDefaultBigInt(new(big.Int)),
+			})
+		}
+		fmt.Println()
+		fmt.Println("Should the governance parameters be set in the genesis? (default = no)")
+		if w.readDefaultYesNo(false) {
+			contracts.Governance = make(map[string]*big.Int)
+			for _, parameter := range cliquepocr.GovernanceParameters() {
+				fmt.Println()
+				fmt.Printf("What is the %s, from %v to %v? (default = from the chain configuration)\n", parameter.Name, parameter.Min, parameter.Max)
+				for {
+					value := w.readDefaultBigInt(new(big.Int))
+					if value.Sign() == 0 {
+						break
+					}
+					if value.Cmp(parameter.Min) < 0 || value.Cmp(parameter.Max) > 0 {
+						log.Error("Invalid input, out of range", "min", parameter.Min, "max", parameter.Max)
+						continue
+					}
+					contracts.Governance[parameter.Name] = value
+					break
+				}
+			}
+		}
+		// Deploy the contracts and embed the sealers into the extra-data section
+		alloc, err := contracts.Alloc()
+		if err != nil {
+			log.Crit("Invalid PoCR contracts", "err", err)
+		}
+		for address, account := range alloc {
+			genesis.Alloc[address] = account
+		}
+		genesis.ExtraData = contracts.ExtraData()
+
 	default:
 		log.Crit("Invalid consensus engine choice", "choice", choice)
 	}
@@ -140,6 +191,21 @@ func (w *wizard) makeGenesis() {
 	w.conf.flush()
 }
 
+// readSigners reads the list of the initial signers of a clique chain, at least
+// one.
+func (w *wizard) readSigners() []common.Address {
+	var signers []common.Address
+	for {
+		if address := w.readAddress(); address != nil {
+			signers = append(signers, *address)
+			continue
+		}
+		if len(signers) > 0 {
+			return signers
+		}
+	}
+}
+
 // importGenesis imports a Geth genesis spec into puppeth.
 func (w *wizard) importGenesis() {
 	// Request the genesis JSON spec URL from the user
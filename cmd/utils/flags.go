@@ -326,6 +326,11 @@ var (
 		Usage:    "Enables serving light clients before syncing",
 		Category: flags.LightCategory,
 	}
+	LightPoCRProofsFlag = &cli.StringFlag{
+		Name:     "light.pocrproofs",
+		Usage:    "RPC endpoint of a full node serving the eth_getProof proofs the PoCR rewards are verified with",
+		Category: flags.LightCategory,
+	}
 
 	// Ethash settings
 	EthashCacheDirFlag = &flags.DirectoryFlag{
@@ -1285,6 +1290,9 @@ func setLes(ctx *cli.Context, cfg *ethconfig.Config) {
 	if ctx.IsSet(LightNoSyncServeFlag.Name) {
 		cfg.LightNoSyncServe = ctx.Bool(LightNoSyncServeFlag.Name)
 	}
+	if ctx.IsSet(LightPoCRProofsFlag.Name) {
+		cfg.LightPoCRProofs = ctx.String(LightPoCRProofsFlag.Name)
+	}
 }
 
 // MakeDatabaseHandles raises out the number of allowed file handles per process
@@ -321,16 +321,16 @@ func (beacon *Beacon) Prepare(chain consensus.ChainHeaderReader, header *types.H
 }
 
 // Finalize implements consensus.Engine, setting the final state on the header
-func (beacon *Beacon) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header) {
+func (beacon *Beacon) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) error {
 	// Finalize is different with Prepare, it can be used in both block generation
 	// and verification. So determine the consensus rules by header type.
 	if !beacon.IsPoSHeader(header) {
-		beacon.ethone.Finalize(chain, header, state, txs, uncles)
-		return
+		return beacon.ethone.Finalize(chain, header, state, txs, uncles, receipts)
 	}
 	// The block reward is no longer handled here. It's done by the
 	// external consensus engine.
 	header.Root = state.IntermediateRoot(true)
+	return nil
 }
 
 // FinalizeAndAssemble implements consensus.Engine, setting the final state and
@@ -342,7 +342,9 @@ func (beacon *Beacon) FinalizeAndAssemble(chain consensus.ChainHeaderReader, hea
 		return beacon.ethone.FinalizeAndAssemble(chain, header, state, txs, uncles, receipts)
 	}
 	// Finalize and assemble the block
-	beacon.Finalize(chain, header, state, txs, uncles)
+	if err := beacon.Finalize(chain, header, state, txs, uncles, receipts); err != nil {
+		return nil, err
+	}
 	return types.NewBlock(header, txs, uncles, receipts, trie.NewStackTrie(nil)), nil
 }
 
@@ -414,6 +416,15 @@ func (beacon *Beacon) SetThreads(threads int) {
 	}
 }
 
+// RewardRecord returns the reward record of a block finalized by the eth1 engine,
+// if it keeps any. The post-merge blocks are rewarded by the consensus layer.
+func (beacon *Beacon) RewardRecord(hash common.Hash) *types.PoCRReward {
+	if recorder, ok := beacon.ethone.(consensus.RewardRecorder); ok {
+		return recorder.RewardRecord(hash)
+	}
+	return nil
+}
+
 // IsTTDReached checks if the TotalTerminalDifficulty has been surpassed on the `parentHash` block.
 // It depends on the parentHash already being stored in the database.
 // If the parentHash is not stored in the database a UnknownAncestor error is returned.
@@ -17,38 +17,69 @@
 // Package clique implements the proof-of-authority consensus engine.
 package cliquepocr
 
+//go:generate solc contracts/CliquePocr.sol --optimize --combined-json bin,bin-runtime,srcmap,srcmap-runtime,abi,userdoc,devdoc,metadata,hashes -o ./contracts/registry --overwrite
+//go:generate go run ../../cmd/abigen --pkg contracts --out contracts/cliquepocr.go --combined-json ./contracts/registry/combined.json
+//go:generate solc contracts/CliquePocrSessionStorage.sol --combined-json bin,bin-runtime,srcmap,srcmap-runtime,abi,userdoc,devdoc,metadata,hashes -o ./contracts/session --overwrite
+//go:generate go run ../../cmd/abigen --pkg contracts --out contracts/sessionstorage.go --combined-json ./contracts/session/combined.json
+
 import (
 	// "errors"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/cliquepocr/contracts"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm/runtime"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
+	lru "github.com/hashicorp/golang-lru"
 )
 
-// address of the PoCR smart contract, with the governance, the footprint, the auditors and the auditor's pledged amount
+// address of the PoCR smart contract, the registry of the footprints of the
+// sealers (contracts/CliquePocr.sol). The footprint, nbFootprints, totalFootprint
+// and owner slots are the ones of the state variables of the contract. The engine
+// keeps the sealers and the audit records in the storage of the contract too, at
+// locations the contract never writes: plain slot 0, unused by the footprint
+// mapping, and the entries of the mappings at slots 1, 2, 4 and 5.
 var (
 	proofOfCarbonReductionContractAddress = "0x0000000000000000000000000000000000000100"
+	slotFootprint                         = uint(0)
+	slotNbFootprints                      = uint(1)
+	slotTotalFootprint                    = uint(2)
+	slotOwner                             = uint(3)
 	slotNbNodes                           = uint(0)
 	slotSealers                           = uint(1)
 	slotIsSealer                          = uint(2)
+	slotAuditBlock                        = uint(4)
+	slotAuditedFootprint                  = uint(5)
 )
 
+// signerSlots are the mappings, keyed by the signer, the ranking reads from the
+// footprint storage fork on: the footprint of the registry and the audit record
+// of the engine.
+var signerSlots = []uint{slotFootprint, slotAuditBlock, slotAuditedFootprint}
+
 type CarbonFootprintContract struct {
 	ContractAddress common.Address
 	RuntimeConfig   *runtime.Config
+	cache           *lru.ARCCache  // Footprints already read from the storage, by contract storage root and node (optional)
+	callState       *state.StateDB // Copy of the state the calls run on, made by the first call
+}
+
+// footprintKey identifies the footprint of a node in a given content of the
+// contract storage.
+type footprintKey struct {
+	root common.Hash
+	node common.Address
 }
 
 func NewCarbonFootPrintContract(nodeAddress common.Address, config *params.ChainConfig, state *state.StateDB, header *types.Header) CarbonFootprintContract {
 	contract := CarbonFootprintContract{}
 	contract.ContractAddress = common.HexToAddress(proofOfCarbonReductionContractAddress)
 	block := big.NewInt(0).Sub(header.Number, big.NewInt(1))
-	stateCopy := state.Copy() // necessary to work on the copy of the state when performing a call
-	cfg := runtime.Config{ChainConfig: config, Origin: nodeAddress, GasLimit: 1000000, State: stateCopy, BlockNumber: block}
+	// the contract is only read from its storage, no need to work on a copy of the state
+	cfg := runtime.Config{ChainConfig: config, Origin: nodeAddress, GasLimit: 1000000, State: state, BlockNumber: block}
 	contract.RuntimeConfig = &cfg
 	return contract
 }
@@ -62,31 +93,76 @@ func NewCarbonFootPrintContractForUpdate(nodeAddress common.Address, config *par
 }
 
 /**
-* "footprint(address)": "79f85816",
-* "footprintBlock(address)": "db80d723",
+* Reads the footprint(address) public mapping straight from the contract storage,
+* the way the footprints are read from the footprint storage fork on. When the
+* contract has a cache and its storage is unchanged since the last root
+* computation, the values are cached by storage root: any change of the storage
+* gives a new root.
  */
-func (contract *CarbonFootprintContract) footprint(ofNode common.Address) (*big.Int, *big.Int, error) {
-	addressString := ofNode.String()
-	addressString = addressString[2:]
+func (contract *CarbonFootprintContract) footprint(ofNode common.Address) *big.Int {
+	root, clean := contract.RuntimeConfig.State.GetStorageRoot(contract.ContractAddress)
+	key := footprintKey{root: root, node: ofNode}
+	if contract.cache != nil && clean {
+		if cached, ok := contract.cache.Get(key); ok {
+			return new(big.Int).Set(cached.(*big.Int))
+		}
+	}
+	footprint := contract.getMapping(slotFootprint, common.BytesToHash(ofNode.Bytes())).Big()
 
-	input := common.Hex2Bytes("79f85816000000000000000000000000" + addressString)
-	result, _, err := runtime.Call(contract.ContractAddress, input, contract.RuntimeConfig)
+	if contract.cache != nil && clean {
+		contract.cache.Add(key, new(big.Int).Set(footprint))
+	}
+	return footprint
+}
 
+/**
+* Calls the footprint(address) getter of the contract, the way the footprints
+* are read before the footprint storage fork. The call fails if the contract
+* reverts. The output is read as a raw word, an empty output reading as zero.
+ */
+func (contract *CarbonFootprintContract) callFootprint(ofNode common.Address) (*big.Int, error) {
+	pocrABI, err := contracts.CliquePocrMetaData.GetAbi()
 	if err != nil {
-		log.Error("Impossible to get the environmental footprint", "err", err.Error(), "node", ofNode.String(), "block", contract.RuntimeConfig.BlockNumber.Int64())
-		return nil, nil, err
+		return nil, err
 	}
-	footprint := common.BytesToHash(result).Big()
-
-	input = common.Hex2Bytes("db80d723000000000000000000000000" + addressString)
-	result, _, err = runtime.Call(contract.ContractAddress, input, contract.RuntimeConfig)
+	input, err := pocrABI.Pack("footprint", ofNode)
+	if err != nil {
+		return nil, err
+	}
+	footprint, err := contract.callRaw(input)
 	if err != nil {
-		log.Error("Impossible to get the environmental footprint", "err", err.Error(), "node", ofNode.String(), "block", contract.RuntimeConfig.BlockNumber.Int64())
-		return nil, nil, err
+		return nil, err
+	}
+	return common.BytesToHash(footprint).Big(), nil
+}
+
+/**
+* Returns the block of the last audit of a node, whose footprint is the given
+* one at the given block. The contract does not store when a footprint was
+* audited: from the footprint storage fork on, the engine records the block at
+* which the footprint of each signer changes (see recordAudit). A footprint
+* differing from the recorded one is audited at the given block, the record
+* being written when the block is finalized.
+ */
+func (contract *CarbonFootprintContract) auditBlock(ofNode common.Address, footprint *big.Int, number *big.Int) *big.Int {
+	key := common.BytesToHash(ofNode.Bytes())
+	if contract.getMapping(slotAuditedFootprint, key).Big().Cmp(footprint) != 0 {
+		return new(big.Int).Set(number)
 	}
-	footprintBlock := common.BytesToHash(result).Big()
+	return contract.getMapping(slotAuditBlock, key).Big()
+}
 
-	return footprint, footprintBlock, nil
+/**
+* Records the given footprint of a node as audited at the given block, when it
+* differs from the recorded one.
+ */
+func (contract *CarbonFootprintContract) recordAudit(ofNode common.Address, footprint *big.Int, number *big.Int) {
+	key := common.BytesToHash(ofNode.Bytes())
+	if contract.getMapping(slotAuditedFootprint, key).Big().Cmp(footprint) == 0 {
+		return
+	}
+	contract.setMapping(slotAuditedFootprint, key, common.BigToHash(footprint))
+	contract.setMapping(slotAuditBlock, key, common.BigToHash(number))
 }
 
 func mappingLocation(slot uint, key common.Hash) common.Hash {
@@ -140,3 +216,36 @@ func (contract *CarbonFootprintContract) setNbNodes(nbNodes int64) {
 	v := big.NewInt(int64(nbNodes))
 	contract.RuntimeConfig.State.SetState(contract.ContractAddress, _slot, common.BigToHash(v))
 }
+
+// call runs a read only method of the PoCR contract with the typed ABI of its
+// bindings and returns the unpacked results. The call works on a copy of the
+// state, so that it leaves no trace in the state being processed.
+func (contract *CarbonFootprintContract) call(method string, args ...interface{}) ([]interface{}, error) {
+	pocrABI, err := contracts.CliquePocrMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	input, err := pocrABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	output, err := contract.callRaw(input)
+	if err != nil {
+		return nil, err
+	}
+	return pocrABI.Unpack(method, output)
+}
+
+// callRaw runs a read only call of the PoCR contract with the given input on a
+// copy of the state. The copy is made by the first call and reused by the next
+// ones: a contract reads a single block, and the calls leave no trace but the
+// access lists and the touched accounts of the copy.
+func (contract *CarbonFootprintContract) callRaw(input []byte) ([]byte, error) {
+	if contract.callState == nil {
+		contract.callState = contract.RuntimeConfig.State.Copy()
+	}
+	cfg := *contract.RuntimeConfig
+	cfg.State = contract.callState
+	output, _, err := runtime.Call(contract.ContractAddress, input, &cfg)
+	return output, err
+}
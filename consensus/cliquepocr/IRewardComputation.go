@@ -1,7 +1,9 @@
 package cliquepocr
 
 import (
+	"fmt"
 	"math/big"
+	"sort"
 )
 
 type IRewardComputation interface {
@@ -11,3 +13,38 @@ type IRewardComputation interface {
 	CalculateGlobalInflationControlFactor(M *big.Int) (*big.Rat, error)
 	CalculateCarbonFootprintReward(rank *big.Rat, nbNodes int, totalCryptoAmount *big.Int) (*big.Int, error)
 }
+
+// Ids of the built-in reward algorithms
+const (
+	RaceRankAlgorithmId     = 3 // Rank decreasing by 10% per better node (the white paper)
+	ProportionalAlgorithmId = 4 // Rank proportional to the best footprint
+)
+
+// RewardAlgorithmConstructor creates a reward algorithm using the given parameters
+type RewardAlgorithmConstructor func(params *RewardParams) IRewardComputation
+
+// rewardAlgorithms is the registry of the reward algorithm constructors, by id
+var rewardAlgorithms = map[uint64]RewardAlgorithmConstructor{
+	RaceRankAlgorithmId:     NewRaceRankComputation,
+	ProportionalAlgorithmId: NewProportionalComputation,
+}
+
+// RegisterRewardAlgorithm adds a reward algorithm to the ones a chain can select
+// in its configuration. It is meant to be called from an init function and
+// panics if the id is already taken.
+func RegisterRewardAlgorithm(id uint64, constructor RewardAlgorithmConstructor) {
+	if _, ok := rewardAlgorithms[id]; ok {
+		panic(fmt.Sprintf("reward algorithm %d already registered", id))
+	}
+	rewardAlgorithms[id] = constructor
+}
+
+// RewardAlgorithms returns the ids of the registered reward algorithms.
+func RewardAlgorithms() []uint64 {
+	ids := make([]uint64, 0, len(rewardAlgorithms))
+	for id := range rewardAlgorithms {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
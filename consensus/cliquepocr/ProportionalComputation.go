@@ -0,0 +1,46 @@
+package cliquepocr
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// The proportional computation : the node with the lowest footprint gets a rank
+// of 1 and the others a rank proportional to it (lowest footprint / footprint).
+// The inflation control and the reward are the white paper ones.
+type ProportionalComputation struct {
+	RaceRankComputation
+}
+
+func NewProportionalComputation(params *RewardParams) IRewardComputation {
+	return &ProportionalComputation{RaceRankComputation{params: params}}
+}
+
+func (pc *ProportionalComputation) GetAlgorithmId() int {
+	return ProportionalAlgorithmId
+}
+
+func (pc *ProportionalComputation) CalculateRanking(footprint *big.Int, nodesFootprint []*big.Int) (rank *big.Rat, nbNodes int, err error) {
+	if footprint.Cmp(zero) <= 0 {
+		return nil, 0, errors.New("cannot proceed with zero or negative footprint")
+	}
+	nbNodes = len(nodesFootprint)
+
+	if nbNodes == 0 {
+		return nil, 0, errors.New("cannot rank zero node")
+	}
+	// look for the lowest footprint, ignoring the nodes without footprint
+	lowest := footprint
+	for i := 0; i < nbNodes; i++ {
+		if nodesFootprint[i].Cmp(lowest) == -1 && nodesFootprint[i].Cmp(zero) > 0 {
+			lowest = nodesFootprint[i]
+		}
+	}
+	rank = new(big.Rat).SetFrac(lowest, footprint)
+
+	log.Debug("Ranking calculation", "node footprint", footprint, "all footprints", nodesFootprint, "lowest footprint", lowest, "rank", rank)
+
+	return rank, nbNodes, nil
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	// "math"
 	"math/big"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/log"
 	// "sort"
@@ -12,28 +13,34 @@ import (
 
 // The standard WhitePaper computation
 type RaceRankComputation struct {
+	params    *RewardParams
 	rankArray []*big.Rat
+	lock      sync.Mutex // Protects the rank array, shared between the block processing and the APIs
 }
 
-func NewRaceRankComputation() IRewardComputation {
+func NewRaceRankComputation(params *RewardParams) IRewardComputation {
 	return &RaceRankComputation{
+		params:    params,
 		rankArray: []*big.Rat{big.NewRat(1, 1)},
 	}
 }
 
 func (wp *RaceRankComputation) getRanking(rank int) *big.Rat {
+	wp.lock.Lock()
+	defer wp.lock.Unlock()
+
 	// calculate the rational value for the given index if it does not already exists
 	previous := wp.rankArray[len(wp.rankArray)-1]
 	for i := len(wp.rankArray); i <= rank; i++ {
-		// multiply the previous one by 0,9
-		previous = new(big.Rat).Mul(previous, big.NewRat(9, 10))
+		// multiply the previous one by the decay (0,9 by default)
+		previous = new(big.Rat).Mul(previous, wp.params.RankDecay)
 		wp.rankArray = append(wp.rankArray, previous)
 	}
 	return wp.rankArray[rank]
 }
 
 func (wp *RaceRankComputation) GetAlgorithmId() int {
-	return 3
+	return RaceRankAlgorithmId
 }
 
 func (wp *RaceRankComputation) CalculateRanking(footprint *big.Int, nodesFootprint []*big.Int) (rank *big.Rat, nbNodes int, err error) {
@@ -61,13 +68,6 @@ func (wp *RaceRankComputation) CalculateRanking(footprint *big.Int, nodesFootpri
 	return rank, nbNodes, nil
 }
 
-// On an annual basis, what is the minimimum amount of CRC tokens that have to be generated each year
-// Inflation control speed at 10^6
-var inflationDenominator = big.NewInt(10000000)
-
-// Minimum creation of CRC per bloc 10^5 per year
-var minCreationPerBlock = new(big.Rat).Mul(big.NewRat(100000, 365*24*3600/4), new(big.Rat).SetInt(CTCUnit))
-
 func (wp *RaceRankComputation) CalculateGlobalInflationControlFactor(M *big.Int) (*big.Rat, error) {
 	// L = TotalCRC / InflationDenominator
 	// D = pow(alpha, L)
@@ -83,9 +83,13 @@ func (wp *RaceRankComputation) CalculateGlobalInflationControlFactor(M *big.Int)
 		return big.NewRat(1, 1), nil
 	}
 
-	L := new(big.Rat).SetFrac(M, new(big.Int).Mul(CTCUnit, inflationDenominator))
+	L := new(big.Rat).SetFrac(M, new(big.Int).Mul(CTCUnit, wp.params.InflationDenominator))
 
-	L = L.Mul(L, big.NewRat(72, 100)) // mul by 0,72 to be able to apply the limited devt on alpha = 2
+	L = L.Mul(L, wp.params.AlphaFactor) // mul by 0,72 (by default) to be able to apply the limited devt on alpha = 2
+	if wp.params.ExactInflation {
+		// 1/D = e^-L, with a bounded error and a bounded rational size
+		return inverseExp(L), nil
+	}
 	// resolve the alpha^L in big.Int by using limited development formula
 	// 𝛴 (x^k)/k! with 4 levels only
 	D := big.NewRat(1, 1) // D = 1
@@ -116,8 +120,8 @@ func (wp *RaceRankComputation) CalculateCarbonFootprintReward(rank *big.Rat, nbN
 	rewardCRCUnit = rewardCRCUnit.Mul(rewardCRCUnit, inflationFactor)
 
 	// apply the minimum reward if needed
-	if rewardCRCUnit.Cmp(minCreationPerBlock) == -1 {
-		rewardCRCUnit = minCreationPerBlock
+	if rewardCRCUnit.Cmp(wp.params.MinCreationPerBlock) == -1 {
+		rewardCRCUnit = wp.params.MinCreationPerBlock
 	}
 
 	u := new(big.Int).Div(rewardCRCUnit.Num(), rewardCRCUnit.Denom())
@@ -0,0 +1,516 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package cliquepocr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	// errUnknownBlock is returned when the rewards are requested for a block
+	// that is not part of the local blockchain.
+	errUnknownBlock = errors.New("unknown block")
+
+	// errNoStateAccess is returned when the rewards are requested from a chain
+	// that cannot give access to the blocks and their state (e.g. light client).
+	errNoStateAccess = errors.New("chain does not give access to the state")
+
+	// errStateUnavailable is returned when the rewards of a block are recomputed
+	// while the state of the block or of its parent is missing (e.g. pruned).
+	errStateUnavailable = errors.New("state unavailable")
+
+	// errGenesisReward is returned when the rewards of the genesis block are
+	// requested.
+	errGenesisReward = errors.New("genesis block has no reward")
+)
+
+// proofTimeout is the time allowed to retrieve the proofs of the rewards of a
+// block, on the chains without access to the state.
+const proofTimeout = 10 * time.Second
+
+// ratPrecision is the number of decimals used to report the rationals (rank and
+// inflation factor) through the API.
+const ratPrecision = 18
+
+// stateChainReader is the part of the full blockchain needed to recompute the
+// rewards of an already imported block.
+type stateChainReader interface {
+	consensus.ChainHeaderReader
+	GetBlock(hash common.Hash, number uint64) *types.Block
+	GetReceiptsByHash(hash common.Hash) types.Receipts
+	StateAt(root common.Hash) (*state.StateDB, error)
+}
+
+// API is a user facing RPC API exposing the proof-of-carbon-reduction footprints,
+// rankings and rewards of the sealers.
+type API struct {
+	chain consensus.ChainHeaderReader
+	pocr  *CliquePoCR
+}
+
+// SignerRanking is the footprint and the ranking of a single sealer at a block.
+type SignerRanking struct {
+	Address            common.Address `json:"address"`
+	Footprint          *hexutil.Big   `json:"footprint"`          // Audited footprint
+	FootprintBlock     *hexutil.Big   `json:"footprintBlock"`     // Block of the last audit, null before the footprint storage fork
+	PenalizedFootprint *hexutil.Big   `json:"penalizedFootprint"` // Footprint once the audit age penalty is applied
+	Rank               string         `json:"rank"`               // Rank as a decimal value between 0 and 1
+	Reward             *hexutil.Big   `json:"reward"`             // Block reward the sealer gets for sealing this block
+}
+
+// SealerStatus is the registration of a signer in the PoCR contract at a block.
+type SealerStatus struct {
+	Address        common.Address `json:"address"`
+	Footprint      *hexutil.Big   `json:"footprint"`      // Audited footprint
+	FootprintBlock *hexutil.Big   `json:"footprintBlock"` // Block of the last audit, null before the footprint storage fork
+	IsSealer       bool           `json:"isSealer"`       // Whether the contract lists the signer as a sealer
+}
+
+// BlockRewards is the breakdown of the PoCR reward of a block.
+type BlockRewards struct {
+	Number          hexutil.Uint64   `json:"number"`
+	Hash            common.Hash      `json:"hash"`
+	Author          common.Address   `json:"author"`
+	NbNodes         int              `json:"nbNodes"`
+	TotalCrypto     *hexutil.Big     `json:"totalCrypto"`     // Total crypto generated before the block
+	InflationFactor string           `json:"inflationFactor"` // Global inflation control factor as a decimal value
+	Rank            string           `json:"rank"`            // Rank of the author
+	BlockReward     *hexutil.Big     `json:"blockReward"`     // Reward minted for the author
+	FeeAdjustment   *hexutil.Big     `json:"feeAdjustment"`   // Fees removed from (negative) the author because of its rank
+	Burnt           *hexutil.Big     `json:"burnt"`           // Fees burnt by the EIP-1559
+	Signers         []*SignerRanking `json:"signers"`
+
+	rank *big.Rat // Exact rank of the author, for the system logs
+}
+
+// Logs returns the PoCR system logs of the rewards, placed after the txCount
+// transactions and the logCount logs of the block.
+func (r *BlockRewards) Logs(txCount, logCount uint) []*types.Log {
+	reward := &types.PoCRReward{
+		Author:        r.Author,
+		Rank:          r.rank,
+		BlockReward:   r.BlockReward.ToInt(),
+		FeeAdjustment: (*big.Int)(r.FeeAdjustment),
+	}
+	return reward.Logs(r.Hash, uint64(r.Number), txCount, logCount)
+}
+
+// header returns the header of the given block number, the head if none.
+func (api *API) header(number *rpc.BlockNumber) (*types.Header, error) {
+	var header *types.Header
+	if number == nil || *number == rpc.LatestBlockNumber {
+		header = api.chain.CurrentHeader()
+	} else {
+		header = api.chain.GetHeaderByNumber(uint64(number.Int64()))
+	}
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return header, nil
+}
+
+// GetRewards retrieves the reward breakdown of the given block.
+func (api *API) GetRewards(number *rpc.BlockNumber) (*BlockRewards, error) {
+	header, err := api.header(number)
+	if err != nil {
+		return nil, err
+	}
+	return api.rewards(header)
+}
+
+// GetRewardsAtHash retrieves the reward breakdown of the given block.
+func (api *API) GetRewardsAtHash(hash common.Hash) (*BlockRewards, error) {
+	header := api.chain.GetHeaderByHash(hash)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return api.rewards(header)
+}
+
+// GetRewardLogs retrieves the PoCR system logs of the given block.
+func (api *API) GetRewardLogs(number *rpc.BlockNumber) ([]*types.Log, error) {
+	header, err := api.header(number)
+	if err != nil {
+		return nil, err
+	}
+	return api.rewardLogs(header)
+}
+
+// GetRewardLogsAtHash retrieves the PoCR system logs of the given block.
+func (api *API) GetRewardLogsAtHash(hash common.Hash) ([]*types.Log, error) {
+	header := api.chain.GetHeaderByHash(hash)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return api.rewardLogs(header)
+}
+
+// rewardLogs derives the system logs of a block from its stored reward record,
+// as eth_getLogs does, or from its recomputed rewards if the node did not process
+// the block (e.g. it was snap synced). They follow the logs of the transactions
+// of the block.
+func (api *API) rewardLogs(header *types.Header) ([]*types.Log, error) {
+	chain, ok := api.chain.(stateChainReader)
+	if !ok {
+		return nil, errNoStateAccess
+	}
+	var (
+		hash     = header.Hash()
+		number   = header.Number.Uint64()
+		receipts = chain.GetReceiptsByHash(hash)
+		count    uint
+		logs     []*types.Log
+	)
+	for _, receipt := range receipts {
+		count += uint(len(receipt.Logs))
+	}
+	if reward := rawdb.ReadPoCRReward(api.pocr.db, hash, number); reward != nil {
+		logs = reward.Logs(hash, number, uint(len(receipts)), count)
+	} else {
+		rewards, err := api.rewards(header)
+		if err != nil {
+			return nil, err
+		}
+		logs = rewards.Logs(uint(len(receipts)), count)
+	}
+	if logs == nil {
+		logs = []*types.Log{}
+	}
+	return logs, nil
+}
+
+// rewards returns the rewards of an imported block. The reward of the author is
+// the one of the reward record the node stored when it processed the block; the
+// ranking of the signers and the inflation factor, which the record lacks, are
+// recomputed if the state of the block and of its parent is available. Without
+// record (e.g. the block was snap synced), the rewards are recomputed.
+func (api *API) rewards(header *types.Header) (*BlockRewards, error) {
+	record := rawdb.ReadPoCRReward(api.pocr.db, header.Hash(), header.Number.Uint64())
+	if record == nil {
+		return api.recomputeRewards(header)
+	}
+	result := &BlockRewards{
+		Number:        hexutil.Uint64(header.Number.Uint64()),
+		Hash:          header.Hash(),
+		Author:        record.Author,
+		NbNodes:       int(record.NbNodes),
+		TotalCrypto:   (*hexutil.Big)(record.TotalCryptoBefore),
+		Rank:          record.Rank.FloatString(ratPrecision),
+		BlockReward:   (*hexutil.Big)(record.BlockReward),
+		FeeAdjustment: (*hexutil.Big)(record.FeeAdjustment),
+		Burnt:         (*hexutil.Big)(record.Burnt),
+		rank:          record.Rank,
+	}
+	recomputed, err := api.recomputeRewards(header)
+	switch {
+	case err == nil:
+		result.InflationFactor, result.Signers = recomputed.InflationFactor, recomputed.Signers
+	case !errors.Is(err, errStateUnavailable):
+		return nil, err
+	}
+	return result, nil
+}
+
+// recomputeRewards recomputes the rewards of an imported block with the same
+// code as the block processing. On the chains without access to the state, the
+// rewards are computed from the proofs of the storage they depend on if the
+// engine has a source of proofs.
+func (api *API) recomputeRewards(header *types.Header) (*BlockRewards, error) {
+	chain, ok := api.chain.(stateChainReader)
+	if !ok {
+		fetch := api.pocr.proofSource()
+		if fetch == nil {
+			return nil, errNoStateAccess
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), proofTimeout)
+		defer cancel()
+		return api.pocr.ProvenRewards(ctx, api.chain, header, nil, fetch)
+	}
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil, errGenesisReward
+	}
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	block := chain.GetBlock(header.Hash(), number)
+	if parent == nil || block == nil {
+		return nil, errUnknownBlock
+	}
+	// The state of the blocks below the pivot of a snap sync was never built
+	statedb, err := chain.StateAt(header.Root)
+	if err != nil {
+		return nil, fmt.Errorf("%w: block %d: %v", errStateUnavailable, number, err)
+	}
+	parentState, err := chain.StateAt(parent.Root)
+	if err != nil {
+		return nil, fmt.Errorf("%w: block %d: %v", errStateUnavailable, number-1, err)
+	}
+	return api.pocr.BlockRewards(chain, header, statedb, parentState, chain.GetReceiptsByHash(header.Hash()))
+}
+
+// BlockRewards computes the rewards of a block. The footprints are read from the
+// state of the block (the reward does not alter them) while the total crypto
+// amount and the governance variables are read from the state of its parent, as
+// they were before the reward was applied. The fee adjustment and the burnt fees
+// are left out if the receipts are not given.
+func (c *CliquePoCR) BlockRewards(chain consensus.ChainHeaderReader, header *types.Header, statedb, parentState *state.StateDB, receipts types.Receipts) (*BlockRewards, error) {
+	author, err := c.Author(header)
+	if err != nil {
+		return nil, err
+	}
+	signers, err := c.getSigners(chain, header, nil)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		governance  = readGovernanceValues(parentState)
+		computation = c.computationAt(chain.Config(), header.Number, governance)
+		// no footprint cache, a slot missing from a proof would be cached as zero
+		contract    = NewCarbonFootPrintContract(author, chain.Config(), statedb, header)
+		footprints  = collectFootprints(&contract, signers, header.Number, rewardParamsAt(chain.Config(), header.Number, governance))
+		totalCrypto = getTotalCryptoBalance(parentState)
+	)
+	// the storage reads do not fail but record the missing trie nodes in the state
+	for _, db := range []*state.StateDB{statedb, parentState} {
+		if err := db.Error(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrContractCall, err)
+		}
+	}
+	inflation, err := computation.CalculateGlobalInflationControlFactor(totalCrypto)
+	if err != nil {
+		return nil, err
+	}
+	allNodesFootprint := c.rankedFootprints(header.Number, footprints)
+	result := &BlockRewards{
+		Number:          hexutil.Uint64(header.Number.Uint64()),
+		Hash:            header.Hash(),
+		Author:          author,
+		NbNodes:         len(allNodesFootprint),
+		TotalCrypto:     (*hexutil.Big)(totalCrypto),
+		InflationFactor: inflation.FloatString(ratPrecision),
+		Signers:         make([]*SignerRanking, 0, len(footprints)),
+	}
+	authorRank, blockReward := new(big.Rat), new(big.Int)
+	for _, f := range footprints {
+		ranking, rank, err := rankFootprint(computation, f, allNodesFootprint, totalCrypto)
+		if err != nil {
+			return nil, err
+		}
+		if f.address == author {
+			authorRank, blockReward = rank, ranking.Reward.ToInt()
+		}
+		result.Signers = append(result.Signers, ranking)
+	}
+	result.Rank = authorRank.FloatString(ratPrecision)
+	result.rank = authorRank
+	result.BlockReward = (*hexutil.Big)(blockReward)
+	if receipts != nil {
+		received, burnt := calcReceiptsTxFee(receipts)
+		result.FeeAdjustment = (*hexutil.Big)(calcCarbonFootprintFeeAdjustment(authorRank, received))
+		result.Burnt = (*hexutil.Big)(burnt)
+	}
+	return result, nil
+}
+
+// GetSealerStanding retrieves the standing of a sealer (the signer of this node
+// if none is given) on top of the current head: its footprint, its rank and
+// the reward it gets for sealing the next block.
+func (api *API) GetSealerStanding(sealer *common.Address) (*SignerRanking, error) {
+	chain, ok := api.chain.(stateChainReader)
+	if !ok {
+		return nil, errNoStateAccess
+	}
+	address := api.pocr.Signer()
+	if sealer != nil {
+		address = *sealer
+	}
+	if address == (common.Address{}) {
+		return nil, errors.New("no sealer given and the node has no signer")
+	}
+	header := chain.CurrentHeader()
+	statedb, err := chain.StateAt(header.Root)
+	if err != nil {
+		return nil, err
+	}
+	return api.pocr.SealerStanding(chain.Config(), header, statedb, address)
+}
+
+// SealerStanding ranks a sealer among the sealers of the PoCR contract, as it
+// would be ranked sealing the block following the given header, whose state is
+// statedb. The engine keeps the sealers of the contract in line with the clique
+// signers, so the standing needs no access to the signer snapshots.
+func (c *CliquePoCR) SealerStanding(config *params.ChainConfig, header *types.Header, statedb *state.StateDB, sealer common.Address) (*SignerRanking, error) {
+	var (
+		number     = new(big.Int).Add(header.Number, common.Big1)
+		governance = readGovernanceValues(statedb)
+		contract   = c.footprintContract(sealer, config, statedb, header)
+		sealers    = []common.Address{sealer}
+	)
+	for i := int64(0); i < int64(contract.getNbNodes()); i++ {
+		if address := contract.getSealerAt(i); address != sealer {
+			sealers = append(sealers, address)
+		}
+	}
+	footprints := collectFootprints(&contract, sealers, number, rewardParamsAt(config, number, governance))
+	if err := statedb.Error(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrContractCall, err)
+	}
+	allNodesFootprint := c.rankedFootprints(number, footprints)
+	ranking, _, err := rankFootprint(c.computationAt(config, number, governance), footprints[0], allNodesFootprint, getTotalCryptoBalance(statedb))
+	return ranking, err
+}
+
+// Sealers returns the signers of the clique snapshot at the given block, whose
+// state is statedb, with their registration in the PoCR contract.
+func (c *CliquePoCR) Sealers(chain consensus.ChainHeaderReader, header *types.Header, statedb *state.StateDB) ([]*SealerStatus, error) {
+	snap, err := c.EngineInstance.Snapshot(chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSnapshotUnavailable, err)
+	}
+	var (
+		signers    = snap.GetSigners()
+		contract   = NewCarbonFootPrintContract(common.Address{}, chain.Config(), statedb, header)
+		governance = readGovernanceValues(statedb)
+		footprints = collectFootprints(&contract, signers, header.Number, rewardParamsAt(chain.Config(), header.Number, governance))
+		sealers    = make([]*SealerStatus, 0, len(footprints))
+	)
+	for _, f := range footprints {
+		sealers = append(sealers, &SealerStatus{
+			Address:        f.address,
+			Footprint:      (*hexutil.Big)(f.footprint),
+			FootprintBlock: (*hexutil.Big)(f.block),
+			IsSealer:       contract.getIsSealerOf(f.address),
+		})
+	}
+	if err := statedb.Error(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrContractCall, err)
+	}
+	return sealers, nil
+}
+
+// GeneratedPocRTotal returns the total crypto generated by the PoCR rewards, net
+// of the burnt and confiscated fees, in the given state.
+func GeneratedPocRTotal(statedb *state.StateDB) *big.Int {
+	return getTotalCryptoBalance(statedb)
+}
+
+// rankFootprint ranks a signer among all the penalized footprints and computes
+// the reward it gets. A signer without footprint is not ranked and does not get
+// any reward.
+func rankFootprint(computation IRewardComputation, f *signerFootprint, allNodesFootprint []*big.Int, totalCrypto *big.Int) (*SignerRanking, *big.Rat, error) {
+	rank, reward := new(big.Rat), new(big.Int)
+	if f.penalized.Sign() > 0 {
+		r, nbNodes, err := computation.CalculateRanking(f.penalized, allNodesFootprint)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrRewardComputation, err)
+		}
+		rank = r
+		if reward, err = computation.CalculateCarbonFootprintReward(rank, nbNodes, totalCrypto); err != nil {
+			return nil, nil, err
+		}
+	}
+	return &SignerRanking{
+		Address:            f.address,
+		Footprint:          (*hexutil.Big)(f.footprint),
+		FootprintBlock:     (*hexutil.Big)(f.block),
+		PenalizedFootprint: (*hexutil.Big)(f.penalized),
+		Rank:               rank.FloatString(ratPrecision),
+		Reward:             (*hexutil.Big)(reward),
+	}, rank, nil
+}
+
+// cliqueAPI is the clique RPC API of a PoCR chain.
+type cliqueAPI struct {
+	*clique.API
+	pocr *CliquePoCR
+}
+
+// cliqueStatus is the clique status of the last blocks, along with the signers
+// that cannot seal for lack of footprint.
+type cliqueStatus struct {
+	InturnPercent   float64                `json:"inturnPercent"`
+	SigningStatus   map[common.Address]int `json:"sealerActivity"`
+	NumBlocks       uint64                 `json:"numBlocks"`
+	ExcludedSigners []common.Address       `json:"excludedSigners"` // Signers without footprint, null if the state is not available
+}
+
+// Status returns the clique status of the last 64 blocks, and the signers that
+// cannot seal the next block for lack of footprint from the footprint required
+// fork on.
+func (api *cliqueAPI) Status() (*cliqueStatus, error) {
+	status, err := api.API.Status()
+	if err != nil {
+		return nil, err
+	}
+	excluded, err := api.pocr.ExcludedSigners(api.Chain, api.Chain.CurrentHeader())
+	if err != nil && err != errNoStateAccess {
+		return nil, err
+	}
+	return &cliqueStatus{
+		InturnPercent:   status.InturnPercent,
+		SigningStatus:   status.SigningStatus,
+		NumBlocks:       status.NumBlocks,
+		ExcludedSigners: excluded,
+	}, nil
+}
+
+// ExcludedSigners returns the signers of the clique snapshot at the given block
+// that cannot seal the next block, having no footprint in the state of the block
+// once the footprint required fork is active. The footprints are read the way
+// verifyFootprint reads them.
+func (c *CliquePoCR) ExcludedSigners(chain consensus.ChainHeaderReader, header *types.Header) ([]common.Address, error) {
+	excluded := []common.Address{}
+	number := new(big.Int).Add(header.Number, common.Big1)
+	if !c.config.IsFootprintRequired(number) {
+		return excluded, nil
+	}
+	reader, ok := chain.(stateReader)
+	if !ok {
+		return nil, errNoStateAccess
+	}
+	statedb, err := reader.StateAt(header.Root)
+	if err != nil {
+		return nil, fmt.Errorf("state of block %d unavailable: %v", header.Number, err)
+	}
+	snap, err := c.EngineInstance.Snapshot(chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSnapshotUnavailable, err)
+	}
+	contract := c.footprintContract(common.Address{}, chain.Config(), statedb, header)
+	for _, signer := range snap.GetSigners() {
+		if footprint := auditedFootprint(&contract, signer, number); footprint.Sign() <= 0 {
+			excluded = append(excluded, signer)
+		}
+	}
+	if err := statedb.Error(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrContractCall, err)
+	}
+	return excluded, nil
+}
@@ -0,0 +1,417 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package cliquepocr
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testFootprintCode is a minimal PoCR contract only answering the footprint(address)
+// getter from the mapping at slot 0.
+var testFootprintCode = common.Hex2Bytes("600435600052600035" + "60e01c806379f8581614601a57" + "600080fd" +
+	"5b600060205260406000205460005260206000f3")
+
+// testFootprintStorage returns the PoCR contract storage giving a footprint to
+// each of the sealers, recorded by the engine as audited at the given block.
+func testFootprintStorage(footprints map[common.Address]int64, block int64) map[common.Hash]common.Hash {
+	storage := make(map[common.Hash]common.Hash)
+	for sealer, footprint := range footprints {
+		key := common.BytesToHash(sealer.Bytes())
+		storage[mappingLocation(slotFootprint, key)] = common.BigToHash(big.NewInt(footprint))
+		storage[mappingLocation(slotAuditedFootprint, key)] = common.BigToHash(big.NewInt(footprint))
+		storage[mappingLocation(slotAuditBlock, key)] = common.BigToHash(big.NewInt(block))
+	}
+	return storage
+}
+
+// testSealerKey is the key of the sealer of the test chains.
+var testSealerKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+
+// testChain is a single sealer PoCR chain used to exercise the engine.
+type testChain struct {
+	db     ethdb.Database
+	key    *ecdsa.PrivateKey
+	addr   common.Address
+	config *params.ChainConfig
+	engine *CliquePoCR
+	chain  *core.BlockChain
+	head   *types.Block
+}
+
+// newTestChain creates a PoCR chain sealed by a single signer having the given
+// footprint. A zero footprint means the signer has never been audited.
+func newTestChain(t testing.TB, footprint int64, alloc core.GenesisAlloc) *testChain {
+	return newTestChainWithConfig(t, params.AllCliqueProtocolChanges, footprint, alloc)
+}
+
+// newForkedTestChain creates a single sealer PoCR chain reading the footprints
+// from the storage of the contract, the footprint storage fork being scheduled
+// at genesis.
+func newForkedTestChain(t testing.TB, footprint int64, alloc core.GenesisAlloc) *testChain {
+	config := *params.AllCliqueProtocolChanges
+	clique := *config.Clique
+	clique.FootprintStorageBlock = new(big.Int)
+	config.Clique = &clique
+	return newTestChainWithConfig(t, &config, footprint, alloc)
+}
+
+// newTestChainWithConfig creates a single sealer PoCR chain with the given chain
+// configuration.
+func newTestChainWithConfig(t testing.TB, config *params.ChainConfig, footprint int64, alloc core.GenesisAlloc) *testChain {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		key     = testSealerKey
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		engine  = New(config.Clique, db)
+		genesis = &core.Genesis{
+			Config:    config,
+			ExtraData: make([]byte, extraVanity+common.AddressLength+extraSeal),
+			Alloc: core.GenesisAlloc{
+				common.HexToAddress(proofOfCarbonReductionContractAddress): {
+					Balance: big.NewInt(0),
+					Code:    testFootprintCode,
+					Storage: testFootprintStorage(map[common.Address]int64{addr: footprint}, 0),
+				},
+				// The session variables are only kept by an account with code
+				common.HexToAddress(sessionVariablesContractAddress): {
+					Balance: big.NewInt(0),
+					Code:    common.Hex2Bytes("608060"),
+				},
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+	)
+	for account, data := range alloc {
+		genesis.Alloc[account] = data
+	}
+	copy(genesis.ExtraData[extraVanity:], addr[:])
+	engine.Authorize(addr, nil)
+
+	head := genesis.MustCommit(db)
+	chain, err := core.NewBlockChain(db, nil, config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	return &testChain{db: db, key: key, addr: addr, config: config, engine: engine, chain: chain, head: head}
+}
+
+// extend generates, seals and imports new blocks on top of the current head.
+// Blocks are generated one by one, so the engine can find the snapshot of the
+// parent of the block it is finalizing.
+func (tc *testChain) extend(t testing.TB, n int, gen func(int, *core.BlockGen)) []*types.Block {
+	var blocks []*types.Block
+	for i := 0; i < n; i++ {
+		generated, _ := core.GenerateChain(tc.config, tc.head, tc.engine, tc.db, 1, func(_ int, block *core.BlockGen) {
+			block.SetDifficulty(diffInTurn)
+			block.SetExtra(make([]byte, extraVanity+extraSeal))
+			if gen != nil {
+				gen(len(blocks), block)
+			}
+		})
+		header := generated[0].Header()
+		header.Extra = make([]byte, extraVanity+extraSeal)
+		header.Coinbase = common.Address{} // no clique vote
+		sig, _ := crypto.Sign(tc.engine.SealHash(header).Bytes(), tc.key)
+		copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+		block := generated[0].WithSeal(header)
+
+		if _, err := tc.chain.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatalf("failed to insert block %d: %v", block.NumberU64(), err)
+		}
+		tc.head = block
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+func (tc *testChain) api() *API {
+	for _, api := range tc.engine.APIs(tc.chain) {
+		if api.Namespace == "pocr" {
+			return api.Service.(*API)
+		}
+	}
+	return nil
+}
+
+// cliqueStatus returns the status of the clique API of the chain.
+func (tc *testChain) cliqueStatus() (*cliqueStatus, error) {
+	for _, api := range tc.engine.APIs(tc.chain) {
+		if api.Namespace == "clique" {
+			return api.Service.(*cliqueAPI).Status()
+		}
+	}
+	return nil, errors.New("no clique API")
+}
+
+func TestAPIRewards(t *testing.T) {
+	var (
+		key, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		sender = crypto.PubkeyToAddress(key.PublicKey)
+		signer = types.LatestSigner(params.AllCliqueProtocolChanges)
+	)
+	tc := newTestChain(t, 1000, core.GenesisAlloc{sender: {Balance: big.NewInt(params.Ether)}})
+	defer tc.chain.Stop()
+
+	// The transactions pay no tip: the chain maker credits the tips to the coinbase
+	// while the import credits them to the signer
+	blocks := tc.extend(t, 2, func(i int, block *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(sender), common.Address{0x01}, big.NewInt(1), params.TxGas, block.BaseFee(), nil), signer, key)
+		block.AddTx(tx)
+	})
+	api := tc.api()
+
+	first, err := api.GetRewardsAtHash(blocks[0].Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve rewards: %v", err)
+	}
+	if first.Author != tc.addr {
+		t.Errorf("author mismatch: have %x, want %x", first.Author, tc.addr)
+	}
+	if first.NbNodes != 1 || len(first.Signers) != 1 {
+		t.Fatalf("signers mismatch: have %d/%d, want 1", first.NbNodes, len(first.Signers))
+	}
+	if have := first.Signers[0].Footprint.ToInt(); have.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("footprint mismatch: have %v, want %v", have, 1000)
+	}
+	// A single ranked node with no crypto generated yet gets exactly one unit
+	if have := first.BlockReward.ToInt(); have.Cmp(CTCUnit) != 0 {
+		t.Errorf("block reward mismatch: have %v, want %v", have, CTCUnit)
+	}
+	if first.Rank != big.NewRat(1, 1).FloatString(ratPrecision) {
+		t.Errorf("rank mismatch: have %v, want 1", first.Rank)
+	}
+	if first.FeeAdjustment.ToInt().Sign() != 0 {
+		t.Errorf("fee adjustment mismatch: have %v, want 0", first.FeeAdjustment)
+	}
+	burnt := new(big.Int).Mul(blocks[0].BaseFee(), new(big.Int).SetUint64(params.TxGas))
+	if have := first.Burnt.ToInt(); have.Cmp(burnt) != 0 {
+		t.Errorf("burnt mismatch: have %v, want %v", have, burnt)
+	}
+	// The rewards must be the ones applied on the state
+	prev, _ := tc.chain.StateAt(tc.chain.Genesis().Root())
+	post, _ := tc.chain.StateAt(blocks[0].Root())
+	if delta := new(big.Int).Sub(post.GetBalance(tc.addr), prev.GetBalance(tc.addr)); delta.Cmp(first.BlockReward.ToInt()) != 0 {
+		t.Errorf("balance delta mismatch: have %v, want %v", delta, first.BlockReward)
+	}
+	// The next block sees the crypto generated by the first one
+	number := rpc.BlockNumber(2)
+	second, err := api.GetRewards(&number)
+	if err != nil {
+		t.Fatalf("failed to retrieve rewards: %v", err)
+	}
+	total := new(big.Int).Sub(first.BlockReward.ToInt(), first.Burnt.ToInt())
+	if have := second.TotalCrypto.ToInt(); have.Cmp(total) != 0 {
+		t.Errorf("total crypto mismatch: have %v, want %v", have, total)
+	}
+	if have := second.BlockReward.ToInt(); have.Cmp(CTCUnit) >= 0 {
+		t.Errorf("block reward not reduced by inflation: have %v", have)
+	}
+}
+
+// Tests that the rewards of a block are the ones of its stored reward record,
+// and recomputed from the state without record.
+func TestAPIRewardsRecord(t *testing.T) {
+	tc := newTestChain(t, 1000, nil)
+	defer tc.chain.Stop()
+
+	block := tc.extend(t, 1, nil)[0]
+	recomputed, err := tc.api().GetRewardsAtHash(block.Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve rewards: %v", err)
+	}
+	// Tamper with the record to tell it apart from the recomputed rewards
+	record := rawdb.ReadPoCRReward(tc.db, block.Hash(), block.NumberU64())
+	record.BlockReward = big.NewInt(12345)
+	record.Rank = big.NewRat(1, 2)
+	rawdb.WritePoCRReward(tc.db, block.Hash(), block.NumberU64(), record)
+
+	stored, err := tc.api().GetRewardsAtHash(block.Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve rewards: %v", err)
+	}
+	if have := stored.BlockReward.ToInt(); have.Cmp(record.BlockReward) != 0 {
+		t.Errorf("block reward mismatch: have %v, want the recorded %v", have, record.BlockReward)
+	}
+	if want := record.Rank.FloatString(ratPrecision); stored.Rank != want {
+		t.Errorf("rank mismatch: have %v, want the recorded %v", stored.Rank, want)
+	}
+	// The ranking of the signers is not recorded, it is still recomputed
+	have, _ := json.Marshal(stored.Signers)
+	want, _ := json.Marshal(recomputed.Signers)
+	if !bytes.Equal(have, want) || stored.InflationFactor != recomputed.InflationFactor {
+		t.Errorf("breakdown mismatch: have %s/%v, want %s/%v", have, stored.InflationFactor, want, recomputed.InflationFactor)
+	}
+	rawdb.DeletePoCRReward(tc.db, block.Hash(), block.NumberU64())
+	fallback, err := tc.api().GetRewardsAtHash(block.Hash())
+	if err != nil {
+		t.Fatalf("failed to recompute rewards: %v", err)
+	}
+	have, _ = json.Marshal(fallback)
+	want, _ = json.Marshal(recomputed)
+	if !bytes.Equal(have, want) {
+		t.Errorf("recomputed rewards mismatch: have %s, want %s", have, want)
+	}
+}
+
+// Tests that a ranking failure is reported instead of ranking the signer zero.
+func TestRankFootprintError(t *testing.T) {
+	f := &signerFootprint{address: common.Address{0x01}, footprint: big.NewInt(1000), penalized: big.NewInt(1000)}
+	if _, _, err := rankFootprint(NewRaceRankComputation(DefaultRewardParams()), f, nil, new(big.Int)); !errors.Is(err, ErrRewardComputation) {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrRewardComputation)
+	}
+}
+
+func TestAPIRewardsNoFootprint(t *testing.T) {
+	tc := newTestChain(t, 0, nil)
+	defer tc.chain.Stop()
+
+	blocks := tc.extend(t, 1, nil)
+	rewards, err := tc.api().GetRewardsAtHash(blocks[0].Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve rewards: %v", err)
+	}
+	if rewards.BlockReward.ToInt().Sign() != 0 {
+		t.Errorf("block reward mismatch: have %v, want 0", rewards.BlockReward)
+	}
+	if rewards.Rank != new(big.Rat).FloatString(ratPrecision) {
+		t.Errorf("rank mismatch: have %v, want 0", rewards.Rank)
+	}
+	if _, err := tc.api().GetRewardsAtHash(common.Hash{0x01}); err != errUnknownBlock {
+		t.Errorf("error mismatch: have %v, want %v", err, errUnknownBlock)
+	}
+}
+
+// Tests that the system logs of the rewards are derived from the stored reward
+// record, and recomputed from the state of the block without record.
+func TestAPIRewardLogs(t *testing.T) {
+	var (
+		key, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		sender = crypto.PubkeyToAddress(key.PublicKey)
+		signer = types.LatestSigner(params.AllCliqueProtocolChanges)
+		alloc  = core.GenesisAlloc{sender: {Balance: big.NewInt(params.Ether)}}
+	)
+	tc := newTestChain(t, 1000, alloc)
+	defer tc.chain.Stop()
+
+	blocks := tc.extend(t, 1, func(i int, block *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(sender), common.Address{0x01}, big.NewInt(1), params.TxGas, block.BaseFee(), nil), signer, key)
+		block.AddTx(tx)
+	})
+	block := blocks[0]
+	rewards, err := tc.api().GetRewardsAtHash(block.Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve rewards: %v", err)
+	}
+	logs, err := tc.api().GetRewardLogsAtHash(block.Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve reward logs: %v", err)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("reward logs mismatch: have %d, want 1", len(logs))
+	}
+	log := logs[0]
+	if log.Address != types.PoCRLogAddress || log.Topics[0] != types.RewardMintedTopic || log.BlockHash != block.Hash() {
+		t.Errorf("unexpected reward log: %+v", log)
+	}
+	if log.TxIndex != 1 || log.Index != 0 {
+		t.Errorf("reward log position mismatch: have tx %d log %d, want tx 1 log 0", log.TxIndex, log.Index)
+	}
+	if sealer := common.BytesToAddress(log.Topics[1].Bytes()); sealer != tc.addr {
+		t.Errorf("sealer mismatch: have %x, want %x", sealer, tc.addr)
+	}
+	if amount := new(big.Int).SetBytes(log.Data[:32]); amount.Cmp(rewards.BlockReward.ToInt()) != 0 {
+		t.Errorf("minted amount mismatch: have %v, want %v", amount, rewards.BlockReward)
+	}
+	if rank := new(big.Int).SetBytes(log.Data[32:]); rank.Cmp(big.NewInt(params.Ether)) != 0 {
+		t.Errorf("rank mismatch: have %v, want %v", rank, params.Ether)
+	}
+	if stored := rawdb.ReadPoCRLogs(tc.db, block.Hash(), block.NumberU64(), 1, 0); !reflect.DeepEqual(logs, stored) {
+		t.Errorf("reward logs mismatch: have %+v, want the stored ones %+v", logs, stored)
+	}
+	// The logs are recomputed the same without reward record
+	rawdb.DeletePoCRReward(tc.db, block.Hash(), block.NumberU64())
+	number := rpc.BlockNumber(1)
+	derived, err := tc.api().GetRewardLogs(&number)
+	if err != nil {
+		t.Fatalf("failed to retrieve reward logs: %v", err)
+	}
+	if !reflect.DeepEqual(derived, logs) {
+		t.Errorf("reward logs mismatch without record: have %+v, want %+v", derived, logs)
+	}
+	// An unranked sealer gets no reward, hence no log
+	unranked := newTestChain(t, 0, nil)
+	defer unranked.chain.Stop()
+
+	empty := unranked.extend(t, 1, nil)[0]
+	if logs, err := unranked.api().GetRewardLogsAtHash(empty.Hash()); err != nil || len(logs) != 0 {
+		t.Errorf("unranked reward logs mismatch: have %v, %v, want none", logs, err)
+	}
+}
+
+// Tests that the standing of a sealer on top of the head is the ranking and the
+// reward it gets sealing the next block.
+func TestAPISealerStanding(t *testing.T) {
+	tc := newTestChain(t, 1000, nil)
+	defer tc.chain.Stop()
+
+	tc.extend(t, 1, nil)
+	standing, err := tc.api().GetSealerStanding(nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve the standing: %v", err)
+	}
+	if standing.Address != tc.addr {
+		t.Errorf("sealer mismatch: have %x, want %x", standing.Address, tc.addr)
+	}
+	if have := standing.Footprint.ToInt(); have.Int64() != 1000 {
+		t.Errorf("footprint mismatch: have %v, want 1000", have)
+	}
+	next := tc.extend(t, 1, nil)[0]
+	rewards, err := tc.api().GetRewardsAtHash(next.Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve rewards: %v", err)
+	}
+	if standing.Rank != rewards.Rank {
+		t.Errorf("rank mismatch: have %v, want %v", standing.Rank, rewards.Rank)
+	}
+	if standing.Reward.ToInt().Cmp(rewards.BlockReward.ToInt()) != 0 {
+		t.Errorf("reward mismatch: have %v, want %v", standing.Reward, rewards.BlockReward)
+	}
+	// A sealer unknown to the contract is not ranked
+	other := common.Address{0x01}
+	if standing, err = tc.api().GetSealerStanding(&other); err != nil {
+		t.Fatalf("failed to retrieve the standing: %v", err)
+	}
+	if standing.Reward.ToInt().Sign() != 0 || standing.Footprint.ToInt().Sign() != 0 {
+		t.Errorf("unknown sealer ranked: %+v", standing)
+	}
+}
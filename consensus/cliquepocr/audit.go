@@ -0,0 +1,146 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package cliquepocr
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// errNotPoCRChain is returned when auditing a chain that is not a PoCR chain.
+var errNotPoCRChain = errors.New("not a PoCR chain")
+
+// BlockAudit is the outcome of the replay of a block against the state of its
+// parent: the rewards the engine computes and their differences with the state
+// and the reward record stored by the node.
+type BlockAudit struct {
+	Number            uint64
+	Hash              common.Hash
+	Author            common.Address
+	Rank              *big.Rat
+	BlockReward       *big.Int // Reward minted for the author
+	FeeAdjustment     *big.Int // Fees added to (positive) or removed from (negative) the author
+	Burnt             *big.Int // Fees burnt by the EIP-1559
+	TotalCryptoBefore *big.Int // Total crypto generated before the block
+	TotalCryptoAfter  *big.Int // Total crypto generated once the block is replayed
+	ExpectedBalance   *big.Int // Balance of the author once the block is replayed
+	Balance           *big.Int // Balance of the author in the stored state
+	Mismatches        []string // Differences with the stored state and reward record
+}
+
+// Auditor replays the blocks of a stored PoCR chain with the engine code, to
+// check the rewards the sealers were paid. The replay needs the state of the
+// parent of every audited block, so the historical blocks can only be audited
+// on an archive node.
+type Auditor struct {
+	chain   *core.BlockChain
+	chainDb ethdb.Database // Database of the chain, holding the reward records of the node
+	db      ethdb.Database // Database of the replaying engine, the chain one is left untouched
+	engine  *CliquePoCR
+}
+
+// NewAuditor creates an auditor of the given chain, stored in the given database.
+func NewAuditor(chain *core.BlockChain, chainDb ethdb.Database) (*Auditor, error) {
+	config := chain.Config()
+	if config.Clique == nil || !config.Clique.PoCR {
+		return nil, errNotPoCRChain
+	}
+	// The replaying engine keeps the clique snapshots in a database of its own,
+	// and the rewards of the replayed blocks in memory
+	db := rawdb.NewMemoryDatabase()
+	return &Auditor{chain: chain, chainDb: chainDb, db: db, engine: New(config.Clique, db)}, nil
+}
+
+// AuditBlock replays the canonical block of the given number against the state
+// of its parent, and compares the outcome with the state stored for the block
+// and with the reward record of the node, if any. A block the engine rejects is
+// reported as a mismatch; an error is only returned if the block or the state
+// of its parent is missing.
+func (a *Auditor) AuditBlock(number uint64) (*BlockAudit, error) {
+	if number == 0 {
+		return nil, errGenesisReward
+	}
+	block := a.chain.GetBlockByNumber(number)
+	if block == nil {
+		return nil, fmt.Errorf("block %d not found", number)
+	}
+	parent := a.chain.GetHeader(block.ParentHash(), number-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent of block %d not found", number)
+	}
+	statedb, err := a.chain.StateAt(parent.Root)
+	if err != nil {
+		return nil, fmt.Errorf("state of block %d unavailable: %v", number-1, err)
+	}
+	stored, err := a.chain.StateAt(block.Root())
+	if err != nil {
+		return nil, fmt.Errorf("state of block %d unavailable: %v", number, err)
+	}
+	audit := &BlockAudit{Number: number, Hash: block.Hash()}
+
+	processor := core.NewStateProcessor(a.chain.Config(), a.chain, a.engine)
+	if _, _, _, err := processor.Process(block, statedb, vm.Config{}, a.engine); err != nil {
+		audit.Mismatches = append(audit.Mismatches, fmt.Sprintf("replay failed: %v", err))
+		return audit, nil
+	}
+	reward := a.engine.RewardRecord(block.Hash())
+	if reward == nil {
+		return nil, fmt.Errorf("block %d replayed without reward", number)
+	}
+	audit.Author = reward.Author
+	audit.Rank = reward.Rank
+	audit.BlockReward = reward.BlockReward
+	audit.FeeAdjustment = reward.FeeAdjustment
+	audit.Burnt = reward.Burnt
+	audit.TotalCryptoBefore = reward.TotalCryptoBefore
+	audit.TotalCryptoAfter = reward.TotalCryptoAfter
+	audit.ExpectedBalance = statedb.GetBalance(reward.Author)
+	audit.Balance = stored.GetBalance(reward.Author)
+
+	if audit.Balance.Cmp(audit.ExpectedBalance) != 0 {
+		audit.Mismatches = append(audit.Mismatches, fmt.Sprintf("balance of %x: stored %v, expected %v", reward.Author, audit.Balance, audit.ExpectedBalance))
+	}
+	if total := getTotalCryptoBalance(stored); total.Cmp(reward.TotalCryptoAfter) != 0 {
+		audit.Mismatches = append(audit.Mismatches, fmt.Sprintf("total crypto: stored %v, expected %v", total, reward.TotalCryptoAfter))
+	}
+	if root := statedb.IntermediateRoot(a.chain.Config().IsEIP158(block.Number())); root != block.Root() {
+		audit.Mismatches = append(audit.Mismatches, fmt.Sprintf("state root: stored %x, expected %x", block.Root(), root))
+	}
+	// The reward record is missing on the blocks the node did not process
+	if record := rawdb.ReadPoCRReward(a.chainDb, block.Hash(), number); record != nil {
+		for _, field := range []struct {
+			name           string
+			stored, replay *big.Int
+		}{
+			{"recorded block reward", record.BlockReward, reward.BlockReward},
+			{"recorded fee adjustment", record.FeeAdjustment, reward.FeeAdjustment},
+			{"recorded total crypto", record.TotalCryptoAfter, reward.TotalCryptoAfter},
+		} {
+			if field.stored.Cmp(field.replay) != 0 {
+				audit.Mismatches = append(audit.Mismatches, fmt.Sprintf("%s: stored %v, expected %v", field.name, field.stored, field.replay))
+			}
+		}
+	}
+	return audit, nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package cliquepocr
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the replay of the blocks of a chain matches their stored state and
+// reward records, and that the altered reward records are reported.
+func TestAuditor(t *testing.T) {
+	var (
+		key, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		sender = crypto.PubkeyToAddress(key.PublicKey)
+		signer = types.LatestSigner(params.AllCliqueProtocolChanges)
+		config = *params.AllCliqueProtocolChanges
+	)
+	config.Clique = &params.CliqueConfig{Period: 0, Epoch: 30000, PoCR: true}
+
+	tc := newTestChainWithConfig(t, &config, 1000, core.GenesisAlloc{sender: {Balance: big.NewInt(params.Ether)}})
+	defer tc.chain.Stop()
+
+	blocks := tc.extend(t, 3, func(i int, block *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(sender), common.Address{0x01}, big.NewInt(1), params.TxGas, block.BaseFee(), nil), signer, key)
+		block.AddTx(tx)
+	})
+	auditor, err := NewAuditor(tc.chain, tc.db)
+	if err != nil {
+		t.Fatalf("failed to create auditor: %v", err)
+	}
+	for _, block := range blocks {
+		audit, err := auditor.AuditBlock(block.NumberU64())
+		if err != nil {
+			t.Fatalf("block %d: failed to audit: %v", block.NumberU64(), err)
+		}
+		if len(audit.Mismatches) != 0 {
+			t.Errorf("block %d: unexpected mismatches: %v", block.NumberU64(), audit.Mismatches)
+		}
+		want := rawdb.ReadPoCRReward(tc.db, block.Hash(), block.NumberU64())
+		if audit.Author != tc.addr {
+			t.Errorf("block %d: author mismatch: have %x, want %x", block.NumberU64(), audit.Author, tc.addr)
+		}
+		if audit.BlockReward.Cmp(want.BlockReward) != 0 || audit.BlockReward.Sign() <= 0 {
+			t.Errorf("block %d: reward mismatch: have %v, want %v", block.NumberU64(), audit.BlockReward, want.BlockReward)
+		}
+		if audit.FeeAdjustment.Cmp(want.FeeAdjustment) != 0 {
+			t.Errorf("block %d: fee adjustment mismatch: have %v, want %v", block.NumberU64(), audit.FeeAdjustment, want.FeeAdjustment)
+		}
+		if audit.TotalCryptoAfter.Cmp(want.TotalCryptoAfter) != 0 {
+			t.Errorf("block %d: total crypto mismatch: have %v, want %v", block.NumberU64(), audit.TotalCryptoAfter, want.TotalCryptoAfter)
+		}
+		if audit.Balance.Cmp(audit.ExpectedBalance) != 0 {
+			t.Errorf("block %d: balance mismatch: have %v, want %v", block.NumberU64(), audit.Balance, audit.ExpectedBalance)
+		}
+	}
+	// Alter the reward record of a block
+	record := rawdb.ReadPoCRReward(tc.db, blocks[1].Hash(), 2)
+	record.BlockReward = new(big.Int).Add(record.BlockReward, big.NewInt(1))
+	rawdb.WritePoCRReward(tc.db, blocks[1].Hash(), 2, record)
+
+	audit, err := auditor.AuditBlock(2)
+	if err != nil {
+		t.Fatalf("failed to audit: %v", err)
+	}
+	if len(audit.Mismatches) != 1 || !strings.HasPrefix(audit.Mismatches[0], "recorded block reward") {
+		t.Errorf("mismatches of the altered record: have %v, want the recorded block reward", audit.Mismatches)
+	}
+	if _, err := auditor.AuditBlock(0); err != errGenesisReward {
+		t.Errorf("genesis audit error mismatch: have %v, want %v", err, errGenesisReward)
+	}
+	if _, err := auditor.AuditBlock(4); err == nil {
+		t.Errorf("unknown block audited")
+	}
+}
+
+// Tests that only the PoCR chains can be audited.
+func TestAuditorNotPoCR(t *testing.T) {
+	tc := newTestChain(t, 1000, nil)
+	defer tc.chain.Stop()
+
+	if _, err := NewAuditor(tc.chain, tc.db); err != errNotPoCRChain {
+		t.Errorf("error mismatch: have %v, want %v", err, errNotPoCRChain)
+	}
+}
@@ -56,7 +56,7 @@ func TestCalcReward(t *testing.T) {
 			totalCRC = totalCRC.Mul(totalCRC, CTCUnit)
 			footprint := big.NewInt(test.footprint)
 
-			rewardComputation := NewRaceRankComputation()
+			rewardComputation := NewRaceRankComputation(DefaultRewardParams())
 			cf := make([]*big.Int, nbNodes.Int64())
 
 			for i := 0; i < int(nbNodes.Int64()); i++ {
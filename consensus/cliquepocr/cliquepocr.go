@@ -20,7 +20,9 @@ package cliquepocr
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"math/big"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -41,6 +43,10 @@ const (
 	checkpointInterval = 1024 // Number of blocks after which to save the vote snapshot to the database
 	inmemorySnapshots  = 128  // Number of recent vote snapshots to keep in memory
 	inmemorySignatures = 4096 // Number of recent block signatures to keep in memory
+	inmemoryRewards    = 64   // Number of reward records of blocks being sealed to keep in memory
+	inmemoryRecords    = 64   // Number of reward records of finalized blocks to keep in memory until committed
+	inmemoryFootprints = 1024 // Number of footprints read from the PoCR contract to keep in memory
+	inmemoryGovernance = 128  // Number of governance parameter sets read from the session variables to keep in memory
 
 	wiggleTime  = 500 * time.Millisecond // Random delay (per signer) to allow concurrent signers
 	extraVanity = 32
@@ -59,9 +65,39 @@ var sessionVariablesContractAddress = "0x000000000000000000000000000000000000010
 var sessionVariableTotalPocRCoins = "GeneratedPocRTotal"
 var zero = big.NewInt(0)
 var CTCUnit = big.NewInt(1e+18)
-var MinBlockBetweenAudit = big.NewInt((3600 / 4) * 24 * 365) // 1 year
-// var MinBlockBetweenAudit = big.NewInt(10) // 10 block for debugging
-var PenaltyOnOldFootprint int64 = 5 // In percent of the footprint to be added to the footprint value
+
+// Errors of the PoCR reward processing. A sealer without footprint is expected
+// and simply not rewarded, the other errors are faults rejecting the block.
+var (
+	// ErrMissingFootprint is returned when the sealer of a block has no footprint
+	// registered in the PoCR contract.
+	ErrMissingFootprint = errors.New("sealer does not have a footprint")
+
+	// ErrContractCall is returned when the PoCR contract could not be read.
+	ErrContractCall = errors.New("PoCR contract call failed")
+
+	// ErrSnapshotUnavailable is returned when the signers of a block cannot be
+	// retrieved from the clique snapshots.
+	ErrSnapshotUnavailable = errors.New("signers snapshot unavailable")
+
+	// ErrRewardComputation is returned when the reward algorithm fails to rank
+	// the sealer or to compute its reward.
+	ErrRewardComputation = errors.New("PoCR reward computation failed")
+
+	// ErrUnknownSealer is returned when the sealer of an imported block cannot
+	// be recovered from its signature.
+	ErrUnknownSealer = errors.New("unknown block sealer")
+
+	// ErrUnauditedSigner is returned from the footprint required fork on when
+	// a block is sealed by a signer without footprint.
+	ErrUnauditedSigner = errors.New("unauthorized signer without footprint")
+)
+
+// stateReader is the part of the full blockchain giving access to the states of
+// the imported blocks.
+type stateReader interface {
+	StateAt(root common.Hash) (*state.StateDB, error)
+}
 
 // var raceRankComputation = NewRaceRankComputation()
 
@@ -71,19 +107,33 @@ type CliquePoCR struct {
 
 	recents    *lru.ARCCache // Snapshots for recent block to speed up reorgs
 	signatures *lru.ARCCache // Signatures of recent blocks to speed up mining
+	rewards    *lru.ARCCache // Reward records of the blocks being sealed, by seal hash
+	records    *lru.ARCCache // Reward records of the finalized blocks, by block hash, until the chain commits them
+	footprints *lru.ARCCache // Footprints read from the PoCR contract, by contract storage root and sealer
+	governance *lru.ARCCache // Governance values read from the session variables, by state root
 
 	proposals map[common.Address]bool // Current list of proposals we are pushing
 
-	// signer common.Address  // Ethereum address of the signing key
+	signer common.Address // Ethereum address of the signing key
 	// signFn clique.SignerFn // Signer function to authorize hashes with
-	// lock   sync.RWMutex    // Protects the signer fields
+	proofs ProofFetcher // Source of the proofs the rewards are verified with, without state access
+	lock   sync.RWMutex // Protects the signer and proofs fields
 
 	// The fields below are for testing only
 	// fakeDiff             bool // Skip difficulty verifications
 	EngineInstance *clique.Clique
 	// signersList          []common.Address
 	// signersListLastBlock uint64
-	computation IRewardComputation
+	computations     map[computationKey]IRewardComputation // Reward algorithms instantiated with their parameters
+	computationsLock sync.Mutex                            // Protects the computations
+}
+
+// computationKey identifies a reward algorithm along with its parameters
+type computationKey struct {
+	algorithm  uint64
+	fork       *params.PoCRFork
+	period     uint64
+	governance governanceValues
 }
 
 func New(config *params.CliqueConfig, db ethdb.Database) *CliquePoCR {
@@ -94,14 +144,33 @@ func New(config *params.CliqueConfig, db ethdb.Database) *CliquePoCR {
 	// Allocate the snapshot caches and create the engine
 	recents, _ := lru.NewARC(inmemorySnapshots)
 	signatures, _ := lru.NewARC(inmemorySignatures)
+	rewards, _ := lru.NewARC(inmemoryRewards)
+	records, _ := lru.NewARC(inmemoryRecords)
+	footprints, _ := lru.NewARC(inmemoryFootprints)
+	governance, _ := lru.NewARC(inmemoryGovernance)
+
+	// Ensure the reward algorithms the chain goes through are all known
+	ids := []uint64{conf.RewardAlgorithmAt(common.Big0)}
+	for _, fork := range conf.RewardAlgorithmForks {
+		ids = append(ids, conf.RewardAlgorithmAt(fork.Block))
+	}
+	for _, id := range ids {
+		if _, ok := rewardAlgorithms[id]; !ok {
+			log.Crit("Unknown PoCR reward algorithm", "id", id, "supported", RewardAlgorithms())
+		}
+	}
 	return &CliquePoCR{
 		config:         &conf,
 		db:             db,
 		recents:        recents,
 		signatures:     signatures,
+		rewards:        rewards,
+		records:        records,
+		footprints:     footprints,
+		governance:     governance,
 		proposals:      make(map[common.Address]bool),
 		EngineInstance: clique.New(config, db),
-		computation:    NewRaceRankComputation(),
+		computations:   make(map[computationKey]IRewardComputation),
 	}
 }
 
@@ -123,9 +192,22 @@ func (c *CliquePoCR) Author(header *types.Header) (common.Address, error) {
 // VerifyHeader checks whether a header conforms to the consensus rules of a
 // given EngineInstance. Verifying the seal may be done optionally here, or explicitly
 // via the VerifySeal method.
+//
+// The PoCR rewards are not part of the header, they only show in the state root
+// checked once the block is processed. The header-only paths (light clients, the
+// headers and the blocks below the pivot of a snap sync, the beacon engine before
+// the merge) thus only check the clique rules: the rewards of these blocks are
+// verified with ProvenRewards, or trusted through the state root of the pivot.
+//
+// From the footprint required fork on, the headers sealed by a signer without
+// footprint are rejected when the state of their parent is available, the other
+// blocks being checked when processed.
 func (c *CliquePoCR) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, seal bool) error {
 	// log.Info("VerifyHeader", "number", header.Number)
-	return c.EngineInstance.VerifyHeader(chain, header, seal)
+	if err := c.EngineInstance.VerifyHeader(chain, header, seal); err != nil {
+		return err
+	}
+	return c.verifyHeaderFootprint(chain, header, nil)
 }
 
 // VerifyHeaders is similar to VerifyHeader, but verifies a batch of headers
@@ -135,7 +217,100 @@ func (c *CliquePoCR) VerifyHeader(chain consensus.ChainHeaderReader, header *typ
 
 func (c *CliquePoCR) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
 	// log.Info("VerifyHeaders", "number[0]", headers[0].Number, "nb", len(headers))
-	return c.EngineInstance.VerifyHeaders(chain, headers, seals)
+	cliqueAbort, cliqueResults := c.EngineInstance.VerifyHeaders(chain, headers, seals)
+	if len(headers) == 0 || !c.config.IsFootprintRequired(headers[len(headers)-1].Number) {
+		return cliqueAbort, cliqueResults
+	}
+	var (
+		abort   = make(chan struct{})
+		results = make(chan error, len(headers))
+	)
+	go func() {
+		for i, header := range headers {
+			var err error
+			select {
+			case <-abort:
+				close(cliqueAbort)
+				return
+			case err = <-cliqueResults:
+			}
+			if err == nil {
+				err = c.verifyHeaderFootprint(chain, header, headers[:i])
+			}
+			select {
+			case <-abort:
+				close(cliqueAbort)
+				return
+			case results <- err:
+			}
+		}
+	}()
+	return abort, results
+}
+
+// verifyHeaderFootprint checks that the sealer of a header has a footprint from
+// the footprint required fork on, if the state of its parent is available: the
+// headers of a batch whose parent is not imported yet, and the ones verified
+// without state, are checked when the block is processed.
+//
+// The method accepts an optional list of parent headers that aren't yet part of
+// the local blockchain, the preceding headers of the batch.
+func (c *CliquePoCR) verifyHeaderFootprint(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header) error {
+	number := header.Number.Uint64()
+	if number == 0 || !c.config.IsFootprintRequired(header.Number) {
+		return nil
+	}
+	reader, ok := chain.(stateReader)
+	if !ok {
+		return nil
+	}
+	var parent *types.Header
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+	} else {
+		parent = chain.GetHeader(header.ParentHash, number-1)
+	}
+	if parent == nil || parent.Number.Uint64() != number-1 || parent.Hash() != header.ParentHash {
+		return consensus.ErrUnknownAncestor
+	}
+	parentState, err := reader.StateAt(parent.Root)
+	if err != nil {
+		return nil
+	}
+	author, err := c.Author(header)
+	if err != nil {
+		return err
+	}
+	return c.verifyFootprint(chain, header, author, parentState)
+}
+
+// parentStateOf opens the state of the parent of a block from the database of
+// the state of the block.
+func parentStateOf(chain consensus.ChainHeaderReader, header *types.Header, statedb *state.StateDB) (*state.StateDB, error) {
+	parent := chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	if parent == nil {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	parentState, err := state.New(parent.Root, statedb.Database(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrContractCall, err)
+	}
+	return parentState, nil
+}
+
+// verifyFootprint checks that the sealer of a block has a non-zero footprint in
+// the state of the parent of the block, read from the registry the way the
+// ranking reads it (auditedFootprint).
+func (c *CliquePoCR) verifyFootprint(chain consensus.ChainHeaderReader, header *types.Header, author common.Address, parentState *state.StateDB) error {
+	contract := c.footprintContract(author, chain.Config(), parentState, header)
+	footprint := auditedFootprint(&contract, author, header.Number)
+	if err := parentState.Error(); err != nil {
+		return fmt.Errorf("%w: %v", ErrContractCall, err)
+	}
+	if footprint.Sign() <= 0 {
+		return fmt.Errorf("%w: %x", ErrUnauditedSigner, author)
+	}
+	return nil
 }
 
 // VerifyUncles verifies that the given block's uncles conform to the consensus
@@ -160,13 +335,22 @@ func (c *CliquePoCR) Prepare(chain consensus.ChainHeaderReader, header *types.He
 // Note: The block header and state database might be updated to reflect any
 // consensus rules that happen at finalization (e.g. block rewards).
 // This function is called when the block is imported from another node
-// It does not receive the transaction receipt (that'a shame because it contains the gas used)
-// Hence the reason for putting the extra fields in the tx
-func (c *CliquePoCR) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header) {
+// The fees of the transactions are read from their receipts
+// A failure of the reward processing rejects the block.
+// The reward record is only kept in memory, the chain stores it along with the
+// block once validated and committed (RewardRecord).
+func (c *CliquePoCR) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) error {
 	// log.Info("Finalize", "number", header.Number)
-	blockPostProcessing(c, chain, state, header, txs, false)
+	reward, err := blockPostProcessing(c, chain, state, header, receipts, false)
+	if err != nil {
+		return err
+	}
+	if reward != nil {
+		// The block is already sealed, so its hash is final
+		c.records.Add(header.Hash(), reward)
+	}
 	// Finalize
-	c.EngineInstance.Finalize(chain, header, state, txs, uncles)
+	return c.EngineInstance.Finalize(chain, header, state, txs, uncles, receipts)
 }
 
 // FinalizeAndAssemble runs any post-transaction state modifications (e.g. block
@@ -175,12 +359,20 @@ func (c *CliquePoCR) Finalize(chain consensus.ChainHeaderReader, header *types.H
 // Note: The block header and state database might be updated to reflect any
 // consensus rules that happen at finalization (e.g. block rewards).
 // This function is called when the block is created by this node
-// It receive the transaction receipt but since the Finalize receive the fee info from the tx , we'll do the same
+// The fees of the transactions are read from their receipts, as in Finalize
 func (c *CliquePoCR) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
 	// log.Info("FinalizeAndAssemble", "number", header.Number)
-	blockPostProcessing(c, chain, state, header, txs, true)
+	reward, err := blockPostProcessing(c, chain, state, header, receipts, true)
+	if err != nil {
+		return nil, err
+	}
 	// Finalize block
-	return c.EngineInstance.FinalizeAndAssemble(chain, header, state, txs, uncles, receipts)
+	block, err := c.EngineInstance.FinalizeAndAssemble(chain, header, state, txs, uncles, receipts)
+	if err == nil && reward != nil {
+		// The hash of the block is only known once sealed, keep the record until then
+		c.rewards.Add(c.SealHash(block.Header()), reward)
+	}
+	return block, err
 }
 
 // Seal generates a new sealing request for the given input block and pushes
@@ -189,10 +381,87 @@ func (c *CliquePoCR) FinalizeAndAssemble(chain consensus.ChainHeaderReader, head
 // Note, the method returns immediately and will send the result async. More
 // than one result may also be returned depending on the consensus algorithm.
 
+//
+// From the ranked sealing fork on, the sealed block is held back by a delay
+// growing as the rank of the sealer worsens (rankDelay), so the better ranked
+// signers, in turn or not, get their blocks out first and the worse ranked ones
+// give up theirs when a new head arrives. The delay is only a hint: the fork
+// choice stays the clique one, by total difficulty, so an in-turn block still
+// beats a better ranked out-of-turn block arriving first. The clique rules, the
+// recent signers limit included, are still checked by the clique engine before
+// signing.
 func (c *CliquePoCR) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
 	// log.Info("Seal", "number", block.Number())
 
-	return c.EngineInstance.Seal(chain, block, results, stop)
+	// Intercept the sealed block to record its reward under its final hash
+	sealed := make(chan *types.Block, 1)
+	if err := c.EngineInstance.Seal(chain, block, sealed, stop); err != nil {
+		return err
+	}
+	var delay time.Duration
+	if c.config.IsRankedSealing(block.Number()) {
+		signers, err := c.getSigners(chain, block.Header(), nil)
+		if err != nil {
+			return err
+		}
+		var rank *big.Rat
+		if reward, ok := c.rewards.Get(c.SealHash(block.Header())); ok {
+			rank = reward.(*types.PoCRReward).Rank
+		}
+		delay = rankDelay(len(signers), rank)
+		log.Trace("Ranked sealing requested", "rank", rank, "delay", common.PrettyDuration(delay))
+	}
+	go func() {
+		select {
+		case result := <-sealed:
+			if delay > 0 {
+				select {
+				case <-time.After(delay):
+				case <-stop:
+					return
+				}
+			}
+			if reward, ok := c.rewards.Get(c.SealHash(result.Header())); ok {
+				c.records.Add(result.Hash(), reward)
+			}
+			select {
+			case results <- result:
+			default:
+				log.Warn("Sealing result is not read by miner", "sealhash", c.SealHash(result.Header()))
+			}
+		case <-stop:
+		}
+	}()
+	return nil
+}
+
+// rankDelay returns how long a sealer of the given rank, between 0 and 1, holds
+// its sealed block back under the ranked sealing. The delay spans twice the
+// out-of-turn wiggle of clique, so that the rank prevails over the random
+// wiggle: the best ranked sealers release their blocks at once, the unranked
+// ones (no footprint, or no reward record) wait for the whole span.
+func rankDelay(signers int, rank *big.Rat) time.Duration {
+	span := big.NewInt(int64(2 * (signers/2 + 1) * int(wiggleTime)))
+	if rank == nil {
+		return time.Duration(span.Int64())
+	}
+	lag := new(big.Rat).Sub(big.NewRat(1, 1), rank)
+	if lag.Sign() <= 0 {
+		return 0
+	}
+	delay := span.Mul(span, lag.Num())
+	return time.Duration(delay.Div(delay, lag.Denom()).Int64())
+}
+
+// RewardRecord implements consensus.RewardRecorder, returning the reward record
+// of a block finalized or sealed by the engine, for the chain to store it once
+// the block is committed. The invalid blocks are never committed, hence never
+// recorded.
+func (c *CliquePoCR) RewardRecord(hash common.Hash) *types.PoCRReward {
+	if reward, ok := c.records.Get(hash); ok {
+		return reward.(*types.PoCRReward)
+	}
+	return nil
 }
 
 // SealHash returns the hash of a block prior to it being sealed.
@@ -208,8 +477,26 @@ func (c *CliquePoCR) CalcDifficulty(chain consensus.ChainHeaderReader, time uint
 }
 
 // APIs returns the RPC APIs this consensus engine provides.
+//
+// The clique API is extended so that its status reports the signers excluded
+// from sealing for lack of footprint.
 func (c *CliquePoCR) APIs(chain consensus.ChainHeaderReader) []rpc.API {
-	return c.EngineInstance.APIs(chain)
+	apis := c.EngineInstance.APIs(chain)
+	for i, api := range apis {
+		if service, ok := api.Service.(*clique.API); ok {
+			apis[i].Service = &cliqueAPI{API: service, pocr: c}
+		}
+	}
+	api := &API{chain: chain, pocr: c}
+	return append(apis, rpc.API{
+		Namespace: "pocr",
+		Version:   "1.0",
+		Service:   api,
+	}, rpc.API{
+		Namespace: "debug",
+		Version:   "1.0",
+		Service:   &DebugAPI{api: api},
+	})
 }
 
 // Close terminates any background threads maintained by the consensus EngineInstance.
@@ -221,9 +508,22 @@ func (c *CliquePoCR) Close() error {
 // with.
 
 func (c *CliquePoCR) Authorize(signer common.Address, signFn clique.SignerFn) {
+	c.lock.Lock()
+	c.signer = signer
+	c.lock.Unlock()
+
 	c.EngineInstance.Authorize(signer, signFn)
 }
 
+// Signer returns the address of the signing key of the node, or the zero address
+// if the node does not seal.
+func (c *CliquePoCR) Signer() common.Address {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.signer
+}
+
 // ########################################################################################################################
 // ########################################################################################################################
 
@@ -236,10 +536,12 @@ func (c *CliquePoCR) Authorize(signer common.Address, signFn clique.SignerFn) {
 // included transactions. The reward will depends on the environmental footprint of the node.
 // newBlock (bool) is true when called by FinalizeAndAssemble ie when the block is to be created and signed by this node
 // else newBlock will be false when called by Finalize ie when called for an imported block signed by another node
-func blockPostProcessing(c *CliquePoCR, chain consensus.ChainHeaderReader, state *state.StateDB, header *types.Header, txs []*types.Transaction, newBlock bool) {
+// It returns the record of the applied rewards (nil for the genesis block), or an
+// error if the rewards could not be processed, in which case the block must be rejected.
+func blockPostProcessing(c *CliquePoCR, chain consensus.ChainHeaderReader, state *state.StateDB, header *types.Header, receipts []*types.Receipt, newBlock bool) (*types.PoCRReward, error) {
 	// skip block 0
 	if header.Number.Int64() <= 0 {
-		return
+		return nil, nil
 	}
 
 	// author is the sealer address of the block being processed
@@ -252,28 +554,39 @@ func blockPostProcessing(c *CliquePoCR, chain consensus.ChainHeaderReader, state
 		// Get the block sealer when the block is signed by another node
 		author, err = c.Author(header)
 		if err != nil {
-			// the sealer is invalid in this received block, do not even bother processing anything
-			// the clique implementation VerifyHeader will cover that case
-			return
+			// the clique implementation VerifyHeader rejects such blocks before they are processed
+			return nil, fmt.Errorf("%w: %v", ErrUnknownSealer, err)
 		}
 	}
+	// From the footprint required fork on, a signer without footprint cannot seal
+	if c.config.IsFootprintRequired(header.Number) {
+		parentState, err := parentStateOf(chain, header, state)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.verifyFootprint(chain, header, author, parentState); err != nil {
+			return nil, err
+		}
+	}
+	totalCryptoBefore := getTotalCryptoBalance(state)
 
 	// blockReward is the reward for the sealer for creating that block. It does not contains the fees
 	blockReward := big.NewInt(0)
 
-	footprint, rank, nbNodes, totalCrypto, err := calcCarbonFootprintRanking(c, chain, author, state, header)
-	if err != nil {
-		// if it could not be calculated
-		log.Warn("Fail calculating the node ranking", "node", author.String(), "error", err)
+	footprint, rank, nbNodes, totalCrypto, governance, err := calcCarbonFootprintRanking(c, chain, author, state, header)
+	switch {
+	case errors.Is(err, ErrMissingFootprint):
+		// a sealer without footprint is not rewarded, and its zero rank takes all the fees away
+		log.Debug("Sealer without footprint", "node", author.String())
 
-	} else {
+	case err != nil:
+		return nil, err
+
+	default:
 		// ranking successfully calculated
-		r, err := calcCarbonFootprintReward(c, author, header, footprint, rank, nbNodes, totalCrypto)
-		// if it could not be calculated
+		blockReward, err = calcCarbonFootprintReward(c, chain, author, header, governance, footprint, rank, nbNodes, totalCrypto)
 		if err != nil {
-			log.Warn("Fail calculating the block reward", "node", author.String(), "error", err)
-		} else {
-			blockReward = r
+			return nil, err
 		}
 	}
 
@@ -288,7 +601,8 @@ func blockPostProcessing(c *CliquePoCR, chain consensus.ChainHeaderReader, state
 		// if the ranking was not successfully calculated, force it to a zero ranking so fees are zeroed
 		rank = big.NewRat(0, 1)
 	}
-	feeAdjustment, burnt := calcCarbonFootprintTxFee(c, author, header, rank, txs)
+	received, burnt := calcReceiptsTxFee(receipts)
+	feeAdjustment := calcCarbonFootprintFeeAdjustment(rank, received)
 
 	// Update the fees even if the block reward could not be calculated
 	if feeAdjustment.Sign() == 1 {
@@ -305,12 +619,29 @@ func blockPostProcessing(c *CliquePoCR, chain consensus.ChainHeaderReader, state
 
 	if burnt.Sign() != 0 {
 		// remove the burned fee from the EIP-1559 from the crypto counter
-		addTotalCryptoBalance(state, burnt.Neg(burnt))
+		addTotalCryptoBalance(state, new(big.Int).Neg(burnt))
 	}
 
-	synchronizeSealers(c, chain, author, state, header)
+	if err := recordAudits(c, chain, author, state, header); err != nil {
+		return nil, err
+	}
+	if err := synchronizeSealers(c, chain, author, state, header); err != nil {
+		return nil, err
+	}
 
 	log.Info("💵 Sealer earnings", "block", header.Number, "node", author.String(), "rank", rank.FloatString(4), "blockReward", blockReward.String(), "feeAdjustment", feeAdjustment.String(), "burnt", burnt.String())
+
+	return &types.PoCRReward{
+		Author:            author,
+		Footprint:         footprint,
+		Rank:              rank,
+		NbNodes:           uint64(nbNodes),
+		TotalCryptoBefore: totalCryptoBefore,
+		TotalCryptoAfter:  getTotalCryptoBalance(state),
+		BlockReward:       blockReward,
+		FeeAdjustment:     feeAdjustment,
+		Burnt:             burnt,
+	}, nil
 }
 
 func getTotalCryptoBalance(state *state.StateDB) *big.Int {
@@ -326,66 +657,166 @@ func addTotalCryptoBalance(state *state.StateDB, value *big.Int) *big.Int {
 	return newTotal
 }
 
-func calcCarbonFootprintRanking(c *CliquePoCR, chain consensus.ChainHeaderReader, author common.Address, state *state.StateDB, header *types.Header) (footprint *big.Int, rank *big.Rat, nbNodes int, totalCrypto *big.Int, err error) {
+// signerFootprint is the footprint of a sealer as registered in the PoCR contract
+// along with the value used for the ranking once the audit age penalty is applied.
+type signerFootprint struct {
+	address   common.Address
+	footprint *big.Int // audited footprint
+	block     *big.Int // block of the last audit, nil before the footprint storage fork
+	penalized *big.Int // footprint after the audit age penalty
+	skipped   bool     // whether the footprint getter failed, leaving the signer out of the ranking
+}
+
+// footprintContract returns a read only view of the PoCR contract in the given
+// state, sharing the footprint cache of the engine.
+func (c *CliquePoCR) footprintContract(author common.Address, config *params.ChainConfig, state *state.StateDB, header *types.Header) CarbonFootprintContract {
+	contract := NewCarbonFootPrintContract(author, config, state, header)
+	contract.cache = c.footprints
+	return contract
+}
+
+// collectFootprints retrieves the footprint of every given signer from the PoCR
+// contract. From the footprint storage fork on, the footprints are read from the
+// storage of the contract and penalized by the age of their audit, as recorded
+// by the engine. Before, they are read with the footprint getter, a signer whose
+// getter fails being skipped, and get no audit age penalty: the contract keeps
+// no audit block.
+func collectFootprints(contract *CarbonFootprintContract, signers []common.Address, number *big.Int, params *RewardParams) []*signerFootprint {
+	forked := contract.RuntimeConfig.ChainConfig.Clique.IsFootprintStorage(number)
+	footprints := make([]*signerFootprint, 0, len(signers))
+	for _, signerAddress := range signers {
+		if !forked {
+			f, err := contract.callFootprint(signerAddress)
+			if err != nil {
+				footprints = append(footprints, &signerFootprint{address: signerAddress, footprint: new(big.Int), penalized: new(big.Int), skipped: true})
+				continue
+			}
+			footprints = append(footprints, &signerFootprint{address: signerAddress, footprint: f, penalized: f})
+			continue
+		}
+		// retrieve the footprint and the block of its audit
+		f := contract.footprint(signerAddress)
+		block := contract.auditBlock(signerAddress, f, number)
+		footprints = append(footprints, &signerFootprint{
+			address:   signerAddress,
+			footprint: f,
+			block:     block,
+			// apply a penalty if the age of the audit is greater than a multiple of number of blocks to incentivize redoing audits
+			penalized: calcCarbonFootprintAuditIncentive(f, block, number, params),
+		})
+	}
+	return footprints
+}
+
+// auditedFootprint retrieves the footprint of a signer from the PoCR contract
+// the way collectFootprints does, zero if the getter fails, without penalty.
+func auditedFootprint(contract *CarbonFootprintContract, signer common.Address, number *big.Int) *big.Int {
+	if contract.RuntimeConfig.ChainConfig.Clique.IsFootprintStorage(number) {
+		return contract.footprint(signer)
+	}
+	footprint, err := contract.callFootprint(signer)
+	if err != nil {
+		return new(big.Int)
+	}
+	return footprint
+}
+
+// recordAudits records, from the footprint storage fork on, the block at which
+// the footprint of each signer of the block changes, the audit age penalty of
+// the next blocks running from it.
+func recordAudits(c *CliquePoCR, chain consensus.ChainHeaderReader, author common.Address, state *state.StateDB, header *types.Header) error {
+	if !chain.Config().Clique.IsFootprintStorage(header.Number) {
+		return nil
+	}
+	signers, err := c.getSigners(chain, header, nil)
+	if err != nil {
+		return err
+	}
+	contract := NewCarbonFootPrintContractForUpdate(author, chain.Config(), state, header)
+	for _, signer := range signers {
+		contract.recordAudit(signer, contract.footprint(signer), header.Number)
+	}
+	return nil
+}
+
+// rankedFootprints returns the footprints, audit age penalty included, the
+// sealers of the given block are ranked among. From the footprint required fork
+// on, the signers without footprint cannot seal: they are left out, and do not
+// count in nbNodes.
+func (c *CliquePoCR) rankedFootprints(number *big.Int, footprints []*signerFootprint) []*big.Int {
+	ranked := make([]*big.Int, 0, len(footprints))
+	for _, f := range footprints {
+		if f.skipped || (f.footprint.Sign() <= 0 && c.config.IsFootprintRequired(number)) {
+			continue
+		}
+		ranked = append(ranked, f.penalized)
+	}
+	return ranked
+}
+
+// calcCarbonFootprintRanking ranks the author among the signers of the block.
+// It also returns the governance values the ranking used, the reward is to be
+// computed with the same parameters.
+func calcCarbonFootprintRanking(c *CliquePoCR, chain consensus.ChainHeaderReader, author common.Address, state *state.StateDB, header *types.Header) (footprint *big.Int, rank *big.Rat, nbNodes int, totalCrypto *big.Int, governance governanceValues, err error) {
 	// log.Info("calcCarbonFootprintReward ", "header.Number", header.Number)
-	contract := NewCarbonFootPrintContract(author, chain.Config(), state, header)
+	contract := c.footprintContract(author, chain.Config(), state, header)
 
 	signers, err := c.getSigners(chain, header, nil)
 	if err != nil {
-		return nil, big.NewRat(0, 1), 0, nil, err
+		return nil, big.NewRat(0, 1), 0, nil, governance, err
+	}
+	if governance, err = c.governanceAt(chain, header, state); err != nil {
+		return nil, big.NewRat(0, 1), 0, nil, governance, err
 	}
 
 	// Define an array to store all nodes footprint
-	allNodesFootprint := []*big.Int{}
-	for _, signerAddress := range signers {
-		// log.Debug("Signer found", "address", signerAddress)
-		// retrieve the last block and the footprint
-		f, block, err := contract.footprint(signerAddress)
-		// apply a penalty if the age of the audit is greater than a multiple of number of blocks to incentivize redoing audits
-		f = calcCarbonFootprintAuditIncentive(f, block, header.Number)
-		if err == nil {
-			allNodesFootprint = append(allNodesFootprint, f)
-			// if the current sealer is our block author, keep its footprint
-			if bytes.Equal(signerAddress.Bytes(), author.Bytes()) {
-				footprint = f
-			}
+	footprints := collectFootprints(&contract, signers, header.Number, rewardParamsAt(chain.Config(), header.Number, governance))
+	allNodesFootprint := c.rankedFootprints(header.Number, footprints)
+	for _, f := range footprints {
+		// if the current sealer is our block author, keep its footprint
+		if bytes.Equal(f.address.Bytes(), author.Bytes()) {
+			footprint = f.penalized
 		}
 	}
+	// the storage reads do not fail but record the database errors in the state
+	if err := state.Error(); err != nil {
+		return nil, big.NewRat(0, 1), 0, nil, governance, fmt.Errorf("%w: %v", ErrContractCall, err)
+	}
 
 	// a Zero environmental footprint means no footprint at all
 	if footprint == nil || footprint.Cmp(zero) == 0 {
-		return nil, big.NewRat(0, 1), 0, nil, errors.New("sealer does not have a footprint")
+		return nil, big.NewRat(0, 1), 0, nil, governance, ErrMissingFootprint
 	}
 
 	// get the ranking as a value between 0 and 1
-	r, N, err := c.computation.CalculateRanking(footprint, allNodesFootprint)
+	r, N, err := c.computationAt(chain.Config(), header.Number, governance).CalculateRanking(footprint, allNodesFootprint)
 	if err != nil {
-		return nil, big.NewRat(0, 1), 0, nil, err
+		return nil, big.NewRat(0, 1), 0, nil, governance, fmt.Errorf("%w: %v", ErrRewardComputation, err)
 	}
 	log.Debug("Node ranking result", "signer", author, "rank", r)
 
 	M := getTotalCryptoBalance(state)
 
-	return footprint, r, N, M, nil
+	return footprint, r, N, M, governance, nil
 }
 
 /*
 	Returns the penalized footprint based on the age of the last footprint
-	Per full year of age, apply a % of increase on the footprint
+	Per full audit validity period (1 year by default) of age, apply a % of increase on the footprint
 
 	result = footprint x (1 + nb * penalty%)
 	Calculated as footprint x (100 + penalty x nb) / 100, so the integer division happens last
 */
-func calcCarbonFootprintAuditIncentive(footprint *big.Int, lastBlock *big.Int, currentBlock *big.Int) *big.Int {
+func calcCarbonFootprintAuditIncentive(footprint *big.Int, lastBlock *big.Int, currentBlock *big.Int, params *RewardParams) *big.Int {
 	// calulate the blocks elapsed since the audit
 	delta := new(big.Int).Sub(currentBlock, lastBlock)
 	if delta.Sign() <= 0 || footprint.Sign() <= 0 {
 		return footprint
 	}
-	// calculate the number of full years since the last audit. Result is zero
-	factor := new(big.Int).Div(delta, MinBlockBetweenAudit)
-	// calculate the ratio to apply : PenaltyOnOldFootprint% per years
-	factor.Mul(factor, big.NewInt(PenaltyOnOldFootprint))
+	// calculate the number of full validity periods since the last audit. Result is zero
+	factor := new(big.Int).Div(delta, params.BlocksBetweenAudit)
+	// calculate the ratio to apply : AuditPenalty% per validity period
+	factor.Mul(factor, big.NewInt(params.AuditPenalty))
 	factor.Add(factor, big.NewInt(100))
 	newFootprint := new(big.Int).Mul(footprint, factor)
 	newFootprint.Div(newFootprint, big.NewInt(100))
@@ -393,41 +824,97 @@ func calcCarbonFootprintAuditIncentive(footprint *big.Int, lastBlock *big.Int, c
 	return newFootprint
 }
 
-// Calculate the fees that needs to be rmoved from the sealer because of the ranking ie has and the fees that have been burt in the EIP 1559
-func calcCarbonFootprintTxFee(c *CliquePoCR, address common.Address, header *types.Header, rank *big.Rat, txs []*types.Transaction) (*big.Int, *big.Int) {
-	received := big.NewInt(0)
-	burnt := big.NewInt(0)
-	for _, tx := range txs {
-		received = received.Add(received, tx.FeeTransferred)
-		burnt = burnt.Add(burnt, tx.FeeBurnt)
-	}
-
+// calcCarbonFootprintFeeAdjustment returns the (negative) amount to apply on the fees
+// received by the sealer so it only keeps the share matching its rank.
+func calcCarbonFootprintFeeAdjustment(rank *big.Rat, received *big.Int) *big.Int {
 	expected := new(big.Rat).SetInt(received)
 	expected = expected.Mul(expected, rank)
 
 	adjustment := new(big.Rat).Sub(expected, new(big.Rat).SetInt(received))
 
-	return new(big.Int).Div(adjustment.Num(), adjustment.Denom()), burnt
+	return new(big.Int).Div(adjustment.Num(), adjustment.Denom())
+}
+
+// calcReceiptsTxFee sums the fees received by the sealer and burnt by the EIP-1559
+// over the receipts of a block.
+func calcReceiptsTxFee(receipts []*types.Receipt) (*big.Int, *big.Int) {
+	received := big.NewInt(0)
+	burnt := big.NewInt(0)
+	for _, receipt := range receipts {
+		if receipt.FeeTransferred != nil {
+			received.Add(received, receipt.FeeTransferred)
+		}
+		if receipt.FeeBurnt != nil {
+			burnt.Add(burnt, receipt.FeeBurnt)
+		}
+	}
+	return received, burnt
 }
 
-func calcCarbonFootprintReward(c *CliquePoCR, address common.Address, header *types.Header, footprint *big.Int, rank *big.Rat, nbNodes int, totalCrypto *big.Int) (*big.Int, error) {
+func calcCarbonFootprintReward(c *CliquePoCR, chain consensus.ChainHeaderReader, address common.Address, header *types.Header, governance governanceValues, footprint *big.Int, rank *big.Rat, nbNodes int, totalCrypto *big.Int) (*big.Int, error) {
 
-	reward, err := c.computation.CalculateCarbonFootprintReward(rank, nbNodes, totalCrypto)
+	reward, err := c.computationAt(chain.Config(), header.Number, governance).CalculateCarbonFootprintReward(rank, nbNodes, totalCrypto)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", ErrRewardComputation, err)
 	}
 
 	// log.Info("Calculated reward based on footprint", "block", header.Number, "node", address.String(), "total", totalCrypto, "nb", nbNodes, "rank", rank.FloatString(5), "reward", reward)
 	return reward, nil
 }
 
+// computationAt returns the reward algorithm in force at the given block, set
+// up with the parameters in force at that block.
+func (c *CliquePoCR) computationAt(config *params.ChainConfig, number *big.Int, governance governanceValues) IRewardComputation {
+	fork, period := config.PoCRForkAt(number)
+	key := computationKey{algorithm: c.config.RewardAlgorithmAt(number), fork: fork, period: period, governance: governance}
+
+	c.computationsLock.Lock()
+	defer c.computationsLock.Unlock()
+
+	computation, ok := c.computations[key]
+	if !ok {
+		computation = rewardAlgorithms[key.algorithm](NewRewardParams(governance.apply(fork), period))
+		c.computations[key] = computation
+	}
+	return computation
+}
+
+// rewardParamsAt returns the PoCR parameters in force at the given block.
+func rewardParamsAt(config *params.ChainConfig, number *big.Int, governance governanceValues) *RewardParams {
+	fork, period := config.PoCRForkAt(number)
+	return NewRewardParams(governance.apply(fork), period)
+}
+
+// governanceAt returns the governance values in force at the given block, read
+// from the state of its parent: a session variable changed while processing a
+// block applies from the next block on, whatever the order of the changes.
+func (c *CliquePoCR) governanceAt(chain consensus.ChainHeaderReader, header *types.Header, statedb *state.StateDB) (governanceValues, error) {
+	parent := chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	if parent == nil {
+		return governanceValues{}, consensus.ErrUnknownAncestor
+	}
+	if values, ok := c.governance.Get(parent.Root); ok {
+		return values.(governanceValues), nil
+	}
+	parentState, err := state.New(parent.Root, statedb.Database(), nil)
+	if err != nil {
+		return governanceValues{}, fmt.Errorf("%w: %v", ErrContractCall, err)
+	}
+	values := readGovernanceValues(parentState)
+	if err := parentState.Error(); err != nil {
+		return governanceValues{}, fmt.Errorf("%w: %v", ErrContractCall, err)
+	}
+	c.governance.Add(parent.Root, values)
+	return values, nil
+}
+
 func (c *CliquePoCR) getSigners(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header) ([]common.Address, error) {
 	number := header.Number.Uint64()
 
 	// Retrieve the snapshot needed to verify this header and cache it
 	snap, err := c.EngineInstance.Snapshot(chain, number-1, header.ParentHash, parents)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", ErrSnapshotUnavailable, err)
 	}
 	signersArray := snap.GetSigners()
 	return signersArray, nil
@@ -442,11 +929,111 @@ func contains(array []common.Address, value common.Address) bool {
 	return false
 }
 
+// synchronizeSealers reconciles the sealers of the PoCR contract with the signers
+// of the clique snapshot the block is sealed with. Before the sealer set fork,
+// every block rewrites the sorted list of the signers. From the fork, the signers
+// only change when the parent block applies a vote, so the reconciliation only
+// runs when they differ from the signers of the parent, and on the first block
+// as the genesis contract holds no sealer.
 func synchronizeSealers(c *CliquePoCR, chain consensus.ChainHeaderReader, author common.Address, state *state.StateDB, header *types.Header) error {
+	if !c.config.IsSealerSet(header.Number) {
+		signers, err := c.getSigners(chain, header, nil)
+		if err != nil {
+			return err
+		}
+		contract := NewCarbonFootPrintContractForUpdate(author, chain.Config(), state, header)
+		reconcileSealerList(&contract, signers)
+		return nil
+	}
+	signers, changed, err := c.signersChanged(chain, header)
+	if err != nil || !changed {
+		return err
+	}
+	contract := NewCarbonFootPrintContractForUpdate(author, chain.Config(), state, header)
+	reconcileSealerSet(&contract, signers)
+	return nil
+}
+
+// signersChanged returns the signers of the block and whether they differ from
+// the signers of its parent.
+func (c *CliquePoCR) signersChanged(chain consensus.ChainHeaderReader, header *types.Header) ([]common.Address, bool, error) {
 	signers, err := c.getSigners(chain, header, nil)
 	if err != nil {
-		return err
+		return nil, false, err
+	}
+	number := header.Number.Uint64()
+	if number < 2 {
+		return signers, true, nil
+	}
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return nil, false, consensus.ErrUnknownAncestor
+	}
+	previous, err := c.getSigners(chain, parent, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	// the snapshot signers are sorted
+	if len(previous) != len(signers) {
+		return signers, true, nil
+	}
+	for i := range signers {
+		if signers[i] != previous[i] {
+			return signers, true, nil
+		}
+	}
+	return signers, false, nil
+}
+
+// reconcileSealerSet updates the sealers of the contract as an unordered set:
+// the kept sealers stay at their index, a removed sealer is replaced by the last
+// one and the new sealers are appended. Only the slots of the changed sealers
+// are written, whatever the order of the signers.
+func reconcileSealerSet(contract *CarbonFootprintContract, signers []common.Address) {
+	wanted := make(map[common.Address]bool, len(signers))
+	for _, signer := range signers {
+		wanted[signer] = true
+	}
+	nbNodes := int64(contract.getNbNodes())
+	count := nbNodes
+
+	// Remove from the end, so that the sealer moved into a hole is a kept one
+	kept := make(map[common.Address]bool, len(signers))
+	for i := count - 1; i >= 0; i-- {
+		s := contract.getSealerAt(i)
+		if wanted[s] && !kept[s] {
+			kept[s] = true
+			continue
+		}
+		log.Info("Synchronizing the sealers", "deleting", s, "at", i)
+		if !wanted[s] {
+			contract.setIsSealerOf(s, false)
+		}
+		last := count - 1
+		if i != last {
+			contract.setSealerAt(i, contract.getSealerAt(last))
+		}
+		contract.setSealerAt(last, zeroAddress)
+		count--
+	}
+	for _, signer := range signers {
+		if kept[signer] {
+			continue
+		}
+		log.Info("Synchronizing the sealers", "adding", signer, "at", count)
+		contract.setSealerAt(count, signer)
+		contract.setIsSealerOf(signer, true)
+		count++
+	}
+	if count != nbNodes {
+		log.Info("Synchronizing the sealers", "nbNodes", count)
+		contract.setNbNodes(count)
 	}
+}
+
+// reconcileSealerList updates the sealers of the contract to the sorted list of
+// the signers, the layout before the sealer set fork.
+func reconcileSealerList(contract *CarbonFootprintContract, signers []common.Address) {
 	/*
 		- pseudo code
 		// start by removing missing sealers
@@ -469,8 +1056,6 @@ func synchronizeSealers(c *CliquePoCR, chain consensus.ChainHeaderReader, author
 		// finally update the number of nodes
 		nbNodes = snapshot.sealers.length
 	*/
-
-	contract := NewCarbonFootPrintContractForUpdate(author, chain.Config(), state, header)
 	nbNodes := contract.getNbNodes()
 
 	// log.Info("Synchronizing the sealers", "sc count", nbNodes, "actual", len(signers))
@@ -501,6 +1086,4 @@ func synchronizeSealers(c *CliquePoCR, chain consensus.ChainHeaderReader, author
 		log.Info("Synchronizing the sealers", "nbNodes", len(signers))
 		contract.setNbNodes(int64(len(signers)))
 	}
-
-	return nil
 }
@@ -0,0 +1,353 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package cliquepocr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/compiler"
+	"github.com/ethereum/go-ethereum/consensus/cliquepocr/contracts"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// loadNetworkGenesis reads the genesis of the published PoCR network.
+func loadNetworkGenesis(t testing.TB) *core.Genesis {
+	data, err := os.ReadFile("networkInit/genesis.yml")
+	if err != nil {
+		t.Fatalf("failed to read genesis: %v", err)
+	}
+	genesis := new(core.Genesis)
+	if err := json.Unmarshal(data, genesis); err != nil {
+		t.Fatalf("invalid genesis: %v", err)
+	}
+	return genesis
+}
+
+// mustDeployedCode returns the runtime code of a contract of the bindings.
+func mustDeployedCode(t testing.TB, meta *bind.MetaData) []byte {
+	code, err := deployedCode(meta)
+	if err != nil {
+		t.Fatalf("failed to deploy the contract: %v", err)
+	}
+	return code
+}
+
+// genesisContracts are the bindings of the contracts of the genesis builder.
+var genesisContracts = []struct {
+	name    string
+	address string
+	meta    *bind.MetaData
+}{
+	{"CliquePocr", proofOfCarbonReductionContractAddress, contracts.CliquePocrMetaData},
+	{"CliquePocrSessionStorage", sessionVariablesContractAddress, contracts.CliquePocrSessionStorageMetaData},
+}
+
+// contractSources are the Solidity sources of the bindings, with the solc version
+// and the options of their go:generate directive. The source of the PoCR contract
+// was recovered from its genesis bytecode: the metadata hash of the genesis code,
+// which covers the original source text, is left out of the comparison.
+var contractSources = []struct {
+	name     string
+	source   string
+	version  string
+	optimize bool
+	metadata bool // Whether the metadata hash is compared too
+	meta     *bind.MetaData
+}{
+	{"CliquePocr", "contracts/CliquePocr.sol", "0.8.7", true, false, contracts.CliquePocrMetaData},
+	{"CliquePocrSessionStorage", "contracts/CliquePocrSessionStorage.sol", "0.8.7", false, true, contracts.CliquePocrSessionStorageMetaData},
+}
+
+// withoutMetadata returns the code without the CBOR encoded metadata the
+// compiler appends to it, whose length is given by the last two bytes.
+func withoutMetadata(code []byte) []byte {
+	if len(code) < 2 {
+		return code
+	}
+	size := int(code[len(code)-2])<<8 | int(code[len(code)-1])
+	if size+2 > len(code) {
+		return code
+	}
+	return code[:len(code)-size-2]
+}
+
+// Tests that the bytecode of the bindings is the one compiled from their source,
+// when the solc version of the bindings is installed. Modifying the source
+// without regenerating the bindings makes it fail.
+func TestContractBytecode(t *testing.T) {
+	solc, err := exec.LookPath("solc")
+	if err != nil {
+		t.Skip("solc not found")
+	}
+	version, err := exec.Command(solc, "--version").Output()
+	if err != nil {
+		t.Fatalf("failed to read the solc version: %v", err)
+	}
+	for _, contract := range contractSources {
+		if !bytes.Contains(version, []byte("Version: "+contract.version+"+")) {
+			t.Logf("%s: skipped, solc %s needed", contract.name, contract.version)
+			continue
+		}
+		args := []string{"--combined-json", "bin,bin-runtime,srcmap,srcmap-runtime,abi,userdoc,devdoc,metadata,hashes"}
+		if contract.optimize {
+			args = append(args, "--optimize")
+		}
+		output, err := exec.Command(solc, append(args, contract.source)...).Output()
+		if err != nil {
+			t.Fatalf("%s: failed to compile: %v", contract.name, err)
+		}
+		compiled, err := compiler.ParseCombinedJSON(output, "", "", contract.version, "")
+		if err != nil {
+			t.Fatalf("%s: invalid solc output: %v", contract.name, err)
+		}
+		result, ok := compiled[contract.source+":"+contract.name]
+		if !ok {
+			t.Fatalf("%s: not in the solc output", contract.name)
+		}
+		compiledCode, boundCode := common.FromHex(result.Code), common.FromHex(contract.meta.Bin)
+		if !contract.metadata {
+			compiledCode, boundCode = withoutMetadata(compiledCode), withoutMetadata(boundCode)
+		}
+		if !bytes.Equal(compiledCode, boundCode) {
+			t.Errorf("%s: compiled bytecode drifted from the bindings", contract.name)
+		}
+	}
+}
+
+// Tests that the contracts of the published network genesis are the ones the
+// bindings deploy.
+func TestNetworkGenesisContracts(t *testing.T) {
+	genesis := loadNetworkGenesis(t)
+	for _, contract := range genesisContracts {
+		code := genesis.Alloc[common.HexToAddress(contract.address)].Code
+		if !bytes.Equal(code, mustDeployedCode(t, contract.meta)) {
+			t.Errorf("%s: genesis code differs from the bindings", contract.name)
+		}
+	}
+}
+
+// Tests that the footprints the engine reads from the contract of the published
+// network genesis are the ones of its getter, before the footprint storage fork
+// with the getter and from the fork on from the storage, and that the audit
+// blocks are the ones the engine records from the fork on.
+func TestNetworkGenesisFootprints(t *testing.T) {
+	genesis := loadNetworkGenesis(t)
+	config := *genesis.Config
+	clique := *config.Clique
+	clique.FootprintStorageBlock = big.NewInt(10)
+	config.Clique = &clique
+
+	db := rawdb.NewMemoryDatabase()
+	root := genesis.MustCommit(db).Root()
+	statedb, _ := state.New(root, state.NewDatabase(db), nil)
+
+	sealers := []common.Address{common.HexToAddress("0x01"), common.HexToAddress("0x02"), common.HexToAddress("0x03")}
+	footprints := []int64{1000, 0, 2500}
+	pocrABI, _ := contracts.CliquePocrMetaData.GetAbi()
+	for i, sealer := range sealers {
+		input, _ := pocrABI.Pack("setFootprint", sealer, big.NewInt(footprints[i]))
+		cfg := runtime.Config{ChainConfig: &config, GasLimit: 1000000, State: statedb, BlockNumber: big.NewInt(1)}
+		if _, _, err := runtime.Call(contracts.CliquePocrAddress, input, &cfg); err != nil {
+			t.Fatalf("setFootprint failed: %v", err)
+		}
+	}
+	check := func(number int64, auditBlock *big.Int) {
+		t.Helper()
+		contract := NewCarbonFootPrintContract(common.Address{}, &config, statedb, &types.Header{Number: big.NewInt(number)})
+		read := collectFootprints(&contract, sealers, big.NewInt(number), rewardParamsAt(&config, big.NewInt(number), governanceValues{}))
+		for i, f := range read {
+			out, err := contract.call("footprint", sealers[i])
+			if err != nil {
+				t.Fatalf("block %d: footprint getter failed: %v", number, err)
+			}
+			if f.skipped || f.footprint.Cmp(out[0].(*big.Int)) != 0 || f.footprint.Int64() != footprints[i] {
+				t.Errorf("block %d: footprint of %x mismatch: have %v (skipped %v), getter %v, want %d", number, sealers[i], f.footprint, f.skipped, out[0], footprints[i])
+			}
+			// a zero footprint is the one recorded for the nodes never audited
+			want := auditBlock
+			if want != nil && footprints[i] == 0 {
+				want = new(big.Int)
+			}
+			if (f.block == nil) != (want == nil) || (f.block != nil && f.block.Cmp(want) != 0) {
+				t.Errorf("block %d: audit block of %x mismatch: have %v, want %v", number, sealers[i], f.block, want)
+			}
+		}
+	}
+	check(9, nil)
+	check(10, big.NewInt(10))
+
+	contract := NewCarbonFootPrintContractForUpdate(common.Address{}, &config, statedb, &types.Header{Number: big.NewInt(10)})
+	for _, sealer := range sealers {
+		contract.recordAudit(sealer, contract.footprint(sealer), big.NewInt(10))
+	}
+	check(20, big.NewInt(10))
+}
+
+// Tests that the methods and the events of the bindings ABI are the ones of the
+// bindings bytecode.
+func TestContractABI(t *testing.T) {
+	for _, contract := range genesisContracts {
+		parsed, err := contract.meta.GetAbi()
+		if err != nil {
+			t.Fatalf("%s: invalid ABI: %v", contract.name, err)
+		}
+		code := mustDeployedCode(t, contract.meta)
+		for name, method := range parsed.Methods {
+			// the dispatcher pushes the selector with a PUSH4
+			if !bytes.Contains(code, append([]byte{0x63}, method.ID...)) {
+				t.Errorf("%s: method %s (%x) not in the deployed code", contract.name, name, method.ID)
+			}
+		}
+		for name, event := range parsed.Events {
+			if !bytes.Contains(code, event.ID.Bytes()) {
+				t.Errorf("%s: event %s (%x) not in the deployed code", contract.name, name, event.ID)
+			}
+		}
+	}
+}
+
+// Tests that the storage layout used by the engine is the one of the contract:
+// the footprints the contract writes are the ones the engine reads, and the
+// sealers the engine keeps in the storage of the contract do not disturb it.
+func TestContractStorageLayout(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	address := common.HexToAddress(proofOfCarbonReductionContractAddress)
+	statedb.SetCode(address, mustDeployedCode(t, contracts.CliquePocrMetaData))
+
+	contract := NewCarbonFootPrintContractForUpdate(common.Address{}, params.AllCliqueProtocolChanges, statedb, &types.Header{Number: big.NewInt(1)})
+	sealers := []common.Address{common.HexToAddress("0x01"), common.HexToAddress("0x02"), common.HexToAddress("0x03")}
+	for i, sealer := range sealers {
+		contract.setSealerAt(int64(i), sealer)
+		contract.setIsSealerOf(sealer, true)
+	}
+	contract.setNbNodes(int64(len(sealers)))
+
+	pocrABI, _ := contracts.CliquePocrMetaData.GetAbi()
+	transact := func(method string, args ...interface{}) {
+		t.Helper()
+		input, err := pocrABI.Pack(method, args...)
+		if err != nil {
+			t.Fatalf("failed to pack %s: %v", method, err)
+		}
+		if _, _, err := runtime.Call(address, input, contract.RuntimeConfig); err != nil {
+			t.Fatalf("%s failed: %v", method, err)
+		}
+	}
+	call := func(method string, args ...interface{}) interface{} {
+		t.Helper()
+		out, err := contract.call(method, args...)
+		if err != nil {
+			t.Fatalf("%s failed: %v", method, err)
+		}
+		return out[0]
+	}
+	// the first sealer has no footprint, the third one is updated
+	transact("setFootprint", sealers[1], big.NewInt(1000))
+	transact("setFootprint", sealers[2], big.NewInt(500))
+	transact("setFootprint", sealers[2], big.NewInt(2000))
+
+	for i, sealer := range sealers {
+		if have := contract.getSealerAt(int64(i)); have != sealer {
+			t.Errorf("sealer %d mismatch: have %x, want %x", i, have, sealer)
+		}
+		if !contract.getIsSealerOf(sealer) {
+			t.Errorf("sealer %x not registered", sealer)
+		}
+		footprint := contract.footprint(sealer)
+		if have := call("footprint", sealer).(*big.Int); have.Cmp(footprint) != 0 || have.Int64() != int64(1000*i) {
+			t.Errorf("footprint of %x mismatch: have %v, stored %v, want %d", sealer, have, footprint, 1000*i)
+		}
+	}
+	if nbNodes := contract.getNbNodes(); nbNodes != uint64(len(sealers)) {
+		t.Errorf("nbNodes mismatch: have %v, want %d", nbNodes, len(sealers))
+	}
+	if nbFootprints := call("nbNodes").(*big.Int); nbFootprints.Cmp(statedb.GetState(address, slotHash(slotNbFootprints)).Big()) != 0 || nbFootprints.Int64() != 2 {
+		t.Errorf("footprinted nodes mismatch: have %v, want 2", nbFootprints)
+	}
+	if total := call("totalFootprint").(*big.Int); total.Cmp(statedb.GetState(address, slotHash(slotTotalFootprint)).Big()) != 0 || total.Int64() != 3000 {
+		t.Errorf("total footprint mismatch: have %v, want 3000", total)
+	}
+	// The calls leave the state untouched
+	if statedb.GetNonce(common.Address{}) != 0 || !statedb.Empty(common.Address{}) {
+		t.Errorf("state modified by the calls")
+	}
+}
+
+// Tests that the ownership of the PoCR contract is taken by the first caller,
+// and that the reverts of the contract are decoded.
+func TestContractRevert(t *testing.T) {
+	var (
+		ownerKey, _    = crypto.GenerateKey()
+		outsiderKey, _ = crypto.GenerateKey()
+		owner          = crypto.PubkeyToAddress(ownerKey.PublicKey)
+		outsider       = crypto.PubkeyToAddress(outsiderKey.PublicKey)
+		sealer         = common.HexToAddress("0x5ea1e7")
+	)
+	// A footprint registered without the counters of the contract, whose update
+	// underflows
+	alloc := core.GenesisAlloc{
+		contracts.CliquePocrAddress: {
+			Balance: new(big.Int),
+			Code:    mustDeployedCode(t, contracts.CliquePocrMetaData),
+			Storage: map[common.Hash]common.Hash{mappingLocation(slotFootprint, common.BytesToHash(sealer.Bytes())): common.BigToHash(big.NewInt(1000))},
+		},
+		owner:    {Balance: big.NewInt(params.Ether)},
+		outsider: {Balance: big.NewInt(params.Ether)},
+	}
+	backend := backends.NewSimulatedBackend(alloc, 10000000)
+	defer backend.Close()
+
+	contract, _ := contracts.NewCliquePocr(contracts.CliquePocrAddress, backend)
+	chainID := backend.Blockchain().Config().ChainID
+	ownerOpts, _ := bind.NewKeyedTransactorWithChainID(ownerKey, chainID)
+	outsiderOpts, _ := bind.NewKeyedTransactorWithChainID(outsiderKey, chainID)
+
+	if _, err := contract.SetOwner(ownerOpts, owner); err != nil {
+		t.Fatalf("failed to take the ownership: %v", err)
+	}
+	backend.Commit()
+	if _, err := contract.SetOwner(outsiderOpts, outsider); err != nil {
+		t.Fatalf("failed to send the ownership change: %v", err)
+	}
+	backend.Commit()
+	if have, err := contract.Owner(nil); err != nil || have != owner {
+		t.Errorf("owner mismatch: have %x (%v), want %x", have, err, owner)
+	}
+	// The reverts of the gas estimations carry their reason
+	_, err := contract.SetFootprint(ownerOpts, sealer, big.NewInt(500))
+	if revert := new(contracts.RevertError); !errors.As(contracts.UnpackRevertError(err), &revert) || revert.Reason != "arithmetic underflow or overflow" {
+		t.Errorf("transaction revert mismatch: have %v, want arithmetic underflow or overflow", contracts.UnpackRevertError(err))
+	}
+	if err := errors.New("unrelated"); contracts.UnpackRevertError(err) != err {
+		t.Errorf("error without revert data altered")
+	}
+}
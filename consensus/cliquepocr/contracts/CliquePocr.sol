@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: GPL-3.0
+
+pragma solidity 0.8.7;
+
+/**
+ * @title CliquePocr
+ * @dev Registry of the audited carbon footprint of the sealers, deployed at
+ * 0x0000000000000000000000000000000000000100 in the network genesis.
+ *
+ * The consensus engine reads the footprints straight from the storage: the order
+ * of the state variables is part of the consensus and must not change. The engine
+ * also keeps the sealers of the chain and the audit records in the storage of the
+ * contract, at locations the contract never writes (see CarbonFootprintContract.go).
+ */
+contract CliquePocr {
+    mapping(address => uint256) public footprint;
+    uint256 public nbNodes;
+    uint256 public totalFootprint;
+    address public owner;
+
+    /**
+     * @dev Hand over the contract, the first caller taking it while there is no owner
+     * @param newOwner address of the new owner
+     */
+    function setOwner(address newOwner) public {
+        if (owner == address(0) || owner == msg.sender) {
+            owner = newOwner;
+        }
+    }
+
+    /**
+     * @dev Set the carbon footprint of a node, zero removing the node
+     * @param node address of the node
+     * @param value audited carbon footprint of the node
+     */
+    function setFootprint(address node, uint256 value) public {
+        uint256 previous = footprint[node];
+        if (previous != 0) {
+            totalFootprint -= previous;
+            nbNodes -= 1;
+            delete footprint[node];
+        }
+        if (value != 0) {
+            footprint[node] = value;
+            nbNodes += 1;
+            totalFootprint += value;
+        }
+    }
+}
@@ -0,0 +1,380 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contracts
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// CliquePocrMetaData contains all meta data concerning the CliquePocr contract.
+var CliquePocrMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"footprint\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"nbNodes\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"owner\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"node\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"setFootprint\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"setOwner\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"totalFootprint\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+	Sigs: map[string]string{
+		"79f85816": "footprint(address)",
+		"03b2ec98": "nbNodes()",
+		"8da5cb5b": "owner()",
+		"46c556cc": "setFootprint(address,uint256)",
+		"13af4035": "setOwner(address)",
+		"b6c3dcf8": "totalFootprint()",
+	},
+	Bin: "0x608060405234801561001057600080fd5b506102e9806100206000396000f3fe608060405234801561001057600080fd5b50600436106100625760003560e01c806303b2ec981461006757806313af40351461008357806346c556cc1461009857806379f85816146100ab5780638da5cb5b146100cb578063b6c3dcf8146100f6575b600080fd5b61007060015481565b6040519081526020015b60405180910390f35b610096610091366004610222565b6100ff565b005b6100966100a6366004610244565b610145565b6100706100b9366004610222565b60006020819052908152604090205481565b6003546100de906001600160a01b031681565b6040516001600160a01b03909116815260200161007a565b61007060025481565b6003546001600160a01b0316158061012157506003546001600160a01b031633145b1561014257600380546001600160a01b0319166001600160a01b0383161790555b50565b6001600160a01b03821660009081526020819052604090205480156101ae5780600260008282546101769190610286565b92505081905550600180600082825461018f9190610286565b90915550506001600160a01b0383166000908152602081905260408120555b8115610201576001600160a01b038316600090815260208190526040812083905560018054909182916101e290839061026e565b9250508190555081600260008282546101fb919061026e565b90915550505b505050565b80356001600160a01b038116811461021d57600080fd5b919050565b60006020828403121561023457600080fd5b61023d82610206565b9392505050565b6000806040838503121561025757600080fd5b61026083610206565b946020939093013593505050565b600082198211156102815761028161029d565b500190565b6000828210156102985761029861029d565b500390565b634e487b7160e01b600052601160045260246000fdfea26469706673582212205b00f20927149b4ca8bcff375dac436d3a1d8921b3eaf6e1010c7506c7f2338564736f6c63430008070033",
+}
+
+// CliquePocrABI is the input ABI used to generate the binding from.
+// Deprecated: Use CliquePocrMetaData.ABI instead.
+var CliquePocrABI = CliquePocrMetaData.ABI
+
+// Deprecated: Use CliquePocrMetaData.Sigs instead.
+// CliquePocrFuncSigs maps the 4-byte function signature to its string representation.
+var CliquePocrFuncSigs = CliquePocrMetaData.Sigs
+
+// CliquePocrBin is the compiled bytecode used for deploying new contracts.
+// Deprecated: Use CliquePocrMetaData.Bin instead.
+var CliquePocrBin = CliquePocrMetaData.Bin
+
+// DeployCliquePocr deploys a new Ethereum contract, binding an instance of CliquePocr to it.
+func DeployCliquePocr(auth *bind.TransactOpts, backend bind.ContractBackend) (common.Address, *types.Transaction, *CliquePocr, error) {
+	parsed, err := CliquePocrMetaData.GetAbi()
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if parsed == nil {
+		return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+	}
+
+	address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex(CliquePocrBin), backend)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &CliquePocr{CliquePocrCaller: CliquePocrCaller{contract: contract}, CliquePocrTransactor: CliquePocrTransactor{contract: contract}, CliquePocrFilterer: CliquePocrFilterer{contract: contract}}, nil
+}
+
+// CliquePocr is an auto generated Go binding around an Ethereum contract.
+type CliquePocr struct {
+	CliquePocrCaller     // Read-only binding to the contract
+	CliquePocrTransactor // Write-only binding to the contract
+	CliquePocrFilterer   // Log filterer for contract events
+}
+
+// CliquePocrCaller is an auto generated read-only Go binding around an Ethereum contract.
+type CliquePocrCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// CliquePocrTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type CliquePocrTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// CliquePocrFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type CliquePocrFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// CliquePocrSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type CliquePocrSession struct {
+	Contract     *CliquePocr       // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// CliquePocrCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type CliquePocrCallerSession struct {
+	Contract *CliquePocrCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts     // Call options to use throughout this session
+}
+
+// CliquePocrTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type CliquePocrTransactorSession struct {
+	Contract     *CliquePocrTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts     // Transaction auth options to use throughout this session
+}
+
+// CliquePocrRaw is an auto generated low-level Go binding around an Ethereum contract.
+type CliquePocrRaw struct {
+	Contract *CliquePocr // Generic contract binding to access the raw methods on
+}
+
+// CliquePocrCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type CliquePocrCallerRaw struct {
+	Contract *CliquePocrCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// CliquePocrTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type CliquePocrTransactorRaw struct {
+	Contract *CliquePocrTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewCliquePocr creates a new instance of CliquePocr, bound to a specific deployed contract.
+func NewCliquePocr(address common.Address, backend bind.ContractBackend) (*CliquePocr, error) {
+	contract, err := bindCliquePocr(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &CliquePocr{CliquePocrCaller: CliquePocrCaller{contract: contract}, CliquePocrTransactor: CliquePocrTransactor{contract: contract}, CliquePocrFilterer: CliquePocrFilterer{contract: contract}}, nil
+}
+
+// NewCliquePocrCaller creates a new read-only instance of CliquePocr, bound to a specific deployed contract.
+func NewCliquePocrCaller(address common.Address, caller bind.ContractCaller) (*CliquePocrCaller, error) {
+	contract, err := bindCliquePocr(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &CliquePocrCaller{contract: contract}, nil
+}
+
+// NewCliquePocrTransactor creates a new write-only instance of CliquePocr, bound to a specific deployed contract.
+func NewCliquePocrTransactor(address common.Address, transactor bind.ContractTransactor) (*CliquePocrTransactor, error) {
+	contract, err := bindCliquePocr(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &CliquePocrTransactor{contract: contract}, nil
+}
+
+// NewCliquePocrFilterer creates a new log filterer instance of CliquePocr, bound to a specific deployed contract.
+func NewCliquePocrFilterer(address common.Address, filterer bind.ContractFilterer) (*CliquePocrFilterer, error) {
+	contract, err := bindCliquePocr(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &CliquePocrFilterer{contract: contract}, nil
+}
+
+// bindCliquePocr binds a generic wrapper to an already deployed contract.
+func bindCliquePocr(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(CliquePocrABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_CliquePocr *CliquePocrRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _CliquePocr.Contract.CliquePocrCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_CliquePocr *CliquePocrRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _CliquePocr.Contract.CliquePocrTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_CliquePocr *CliquePocrRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _CliquePocr.Contract.CliquePocrTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_CliquePocr *CliquePocrCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _CliquePocr.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_CliquePocr *CliquePocrTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _CliquePocr.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_CliquePocr *CliquePocrTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _CliquePocr.Contract.contract.Transact(opts, method, params...)
+}
+
+// Footprint is a free data retrieval call binding the contract method 0x79f85816.
+//
+// Solidity: function footprint(address ) view returns(uint256)
+func (_CliquePocr *CliquePocrCaller) Footprint(opts *bind.CallOpts, arg0 common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _CliquePocr.contract.Call(opts, &out, "footprint", arg0)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// Footprint is a free data retrieval call binding the contract method 0x79f85816.
+//
+// Solidity: function footprint(address ) view returns(uint256)
+func (_CliquePocr *CliquePocrSession) Footprint(arg0 common.Address) (*big.Int, error) {
+	return _CliquePocr.Contract.Footprint(&_CliquePocr.CallOpts, arg0)
+}
+
+// Footprint is a free data retrieval call binding the contract method 0x79f85816.
+//
+// Solidity: function footprint(address ) view returns(uint256)
+func (_CliquePocr *CliquePocrCallerSession) Footprint(arg0 common.Address) (*big.Int, error) {
+	return _CliquePocr.Contract.Footprint(&_CliquePocr.CallOpts, arg0)
+}
+
+// NbNodes is a free data retrieval call binding the contract method 0x03b2ec98.
+//
+// Solidity: function nbNodes() view returns(uint256)
+func (_CliquePocr *CliquePocrCaller) NbNodes(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _CliquePocr.contract.Call(opts, &out, "nbNodes")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// NbNodes is a free data retrieval call binding the contract method 0x03b2ec98.
+//
+// Solidity: function nbNodes() view returns(uint256)
+func (_CliquePocr *CliquePocrSession) NbNodes() (*big.Int, error) {
+	return _CliquePocr.Contract.NbNodes(&_CliquePocr.CallOpts)
+}
+
+// NbNodes is a free data retrieval call binding the contract method 0x03b2ec98.
+//
+// Solidity: function nbNodes() view returns(uint256)
+func (_CliquePocr *CliquePocrCallerSession) NbNodes() (*big.Int, error) {
+	return _CliquePocr.Contract.NbNodes(&_CliquePocr.CallOpts)
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_CliquePocr *CliquePocrCaller) Owner(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _CliquePocr.contract.Call(opts, &out, "owner")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_CliquePocr *CliquePocrSession) Owner() (common.Address, error) {
+	return _CliquePocr.Contract.Owner(&_CliquePocr.CallOpts)
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_CliquePocr *CliquePocrCallerSession) Owner() (common.Address, error) {
+	return _CliquePocr.Contract.Owner(&_CliquePocr.CallOpts)
+}
+
+// TotalFootprint is a free data retrieval call binding the contract method 0xb6c3dcf8.
+//
+// Solidity: function totalFootprint() view returns(uint256)
+func (_CliquePocr *CliquePocrCaller) TotalFootprint(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _CliquePocr.contract.Call(opts, &out, "totalFootprint")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// TotalFootprint is a free data retrieval call binding the contract method 0xb6c3dcf8.
+//
+// Solidity: function totalFootprint() view returns(uint256)
+func (_CliquePocr *CliquePocrSession) TotalFootprint() (*big.Int, error) {
+	return _CliquePocr.Contract.TotalFootprint(&_CliquePocr.CallOpts)
+}
+
+// TotalFootprint is a free data retrieval call binding the contract method 0xb6c3dcf8.
+//
+// Solidity: function totalFootprint() view returns(uint256)
+func (_CliquePocr *CliquePocrCallerSession) TotalFootprint() (*big.Int, error) {
+	return _CliquePocr.Contract.TotalFootprint(&_CliquePocr.CallOpts)
+}
+
+// SetFootprint is a paid mutator transaction binding the contract method 0x46c556cc.
+//
+// Solidity: function setFootprint(address node, uint256 value) returns()
+func (_CliquePocr *CliquePocrTransactor) SetFootprint(opts *bind.TransactOpts, node common.Address, value *big.Int) (*types.Transaction, error) {
+	return _CliquePocr.contract.Transact(opts, "setFootprint", node, value)
+}
+
+// SetFootprint is a paid mutator transaction binding the contract method 0x46c556cc.
+//
+// Solidity: function setFootprint(address node, uint256 value) returns()
+func (_CliquePocr *CliquePocrSession) SetFootprint(node common.Address, value *big.Int) (*types.Transaction, error) {
+	return _CliquePocr.Contract.SetFootprint(&_CliquePocr.TransactOpts, node, value)
+}
+
+// SetFootprint is a paid mutator transaction binding the contract method 0x46c556cc.
+//
+// Solidity: function setFootprint(address node, uint256 value) returns()
+func (_CliquePocr *CliquePocrTransactorSession) SetFootprint(node common.Address, value *big.Int) (*types.Transaction, error) {
+	return _CliquePocr.Contract.SetFootprint(&_CliquePocr.TransactOpts, node, value)
+}
+
+// SetOwner is a paid mutator transaction binding the contract method 0x13af4035.
+//
+// Solidity: function setOwner(address newOwner) returns()
+func (_CliquePocr *CliquePocrTransactor) SetOwner(opts *bind.TransactOpts, newOwner common.Address) (*types.Transaction, error) {
+	return _CliquePocr.contract.Transact(opts, "setOwner", newOwner)
+}
+
+// SetOwner is a paid mutator transaction binding the contract method 0x13af4035.
+//
+// Solidity: function setOwner(address newOwner) returns()
+func (_CliquePocr *CliquePocrSession) SetOwner(newOwner common.Address) (*types.Transaction, error) {
+	return _CliquePocr.Contract.SetOwner(&_CliquePocr.TransactOpts, newOwner)
+}
+
+// SetOwner is a paid mutator transaction binding the contract method 0x13af4035.
+//
+// Solidity: function setOwner(address newOwner) returns()
+func (_CliquePocr *CliquePocrTransactorSession) SetOwner(newOwner common.Address) (*types.Transaction, error) {
+	return _CliquePocr.Contract.SetOwner(&_CliquePocr.TransactOpts, newOwner)
+}
@@ -0,0 +1,96 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package contracts
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// panicSelector is the selector of the Panic(uint256) errors of the compiler checks.
+var panicSelector = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+
+// panicReasons are the descriptions of the Panic(uint256) codes.
+var panicReasons = map[uint64]string{
+	0x01: "assertion failed",
+	0x11: "arithmetic underflow or overflow",
+	0x12: "division or modulo by zero",
+	0x21: "invalid enum value",
+	0x22: "invalid storage byte array",
+	0x31: "pop on an empty array",
+	0x32: "array index out of bounds",
+	0x41: "out of memory",
+	0x51: "call to an invalid internal function",
+}
+
+// RevertError is the revert of a call or of a transaction to the PoCR contracts,
+// with its decoded reason.
+type RevertError struct {
+	Reason string // Human readable reason of the revert
+	Data   []byte // Raw revert data returned by the contract
+}
+
+func (e *RevertError) Error() string {
+	return "execution reverted: " + e.Reason
+}
+
+// UnpackRevertError decodes the revert data carried by an error of a call or of
+// a gas estimation (as returned by the RPC API and the simulated backend) into a
+// RevertError. The errors without revert data are returned unchanged.
+func UnpackRevertError(err error) error {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return err
+	}
+	encoded, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return err
+	}
+	data, decodeErr := hexutil.Decode(encoded)
+	if decodeErr != nil {
+		return err
+	}
+	return &RevertError{Reason: revertReason(data), Data: data}
+}
+
+// revertReason returns the human readable reason of the revert data: the message
+// of a require or of a revert, or the check of the compiler that failed.
+func revertReason(data []byte) string {
+	if len(data) == 0 {
+		return "no reason given"
+	}
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return reason
+	}
+	if len(data) == 4+32 && bytes.Equal(data[:4], panicSelector) {
+		code := new(big.Int).SetBytes(data[4:])
+		if code.IsUint64() {
+			if reason, ok := panicReasons[code.Uint64()]; ok {
+				return reason
+			}
+		}
+		return fmt.Sprintf("panic code %#x", code)
+	}
+	return fmt.Sprintf("unknown revert data %x", data)
+}
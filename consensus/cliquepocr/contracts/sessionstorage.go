@@ -0,0 +1,240 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contracts
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// CliquePocrSessionStorageMetaData contains all meta data concerning the CliquePocrSessionStorage contract.
+var CliquePocrSessionStorageMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"internalType\":\"string\",\"name\":\"variableName\",\"type\":\"string\"}],\"name\":\"retrieveSessionVariable\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+	Sigs: map[string]string{
+		"403e6fc4": "retrieveSessionVariable(string)",
+	},
+	Bin: "0x608060405234801561001057600080fd5b50610468806100206000396000f3fe608060405234801561001057600080fd5b506004361061002b5760003560e01c8063403e6fc414610030575b600080fd5b61004a60048036038101906100459190610214565b610060565b6040516100579190610351565b60405180910390f35b60008060003073ffffffffffffffffffffffffffffffffffffffff16858560405160200161008f92919061032d565b60405160208183030381529060405280519060200120604051602401604051602081830303815290604052907bffffffffffffffffffffffffffffffffffffffffffffffffffffffff19166020820180517bffffffffffffffffffffffffffffffffffffffffffffffffffffffff83818316178352505050506040516101159190610316565b600060405180830381855afa9150503d8060008114610150576040519150601f19603f3d011682016040523d82523d6000602084013e610155565b606091505b5091509150811561017d57808060200190518101906101749190610261565b925050506101a3565b7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff925050505b92915050565b60008083601f8401126101bf576101be6103de565b5b8235905067ffffffffffffffff8111156101dc576101db6103d9565b5b6020830191508360018202830111156101f8576101f76103e3565b5b9250929050565b60008151905061020e8161041b565b92915050565b6000806020838503121561022b5761022a6103ed565b5b600083013567ffffffffffffffff811115610249576102486103e8565b5b610255858286016101a9565b92509250509250929050565b600060208284031215610277576102766103ed565b5b6000610285848285016101ff565b91505092915050565b60006102998261036c565b6102a38185610377565b93506102b38185602086016103a6565b80840191505092915050565b60006102cb8385610382565b93506102d8838584610397565b82840190509392505050565b60006102f1600283610382565b91506102fc826103f2565b600282019050919050565b6103108161038d565b82525050565b6000610322828461028e565b915081905092915050565b600061033a8284866102bf565b9150610345826102e4565b91508190509392505050565b60006020820190506103666000830184610307565b92915050565b600081519050919050565b600081905092915050565b600081905092915050565b6000819050919050565b82818337600083830152505050565b60005b838110156103c45780820151818401526020810190506103a9565b838111156103d3576000848401525b50505050565b600080fd5b600080fd5b600080fd5b600080fd5b600080fd5b7f2829000000000000000000000000000000000000000000000000000000000000600082015250565b6104248161038d565b811461042f57600080fd5b5056fea2646970667358221220de0f85e001b98ed11d512f12146a3a1ef386f126bb4d0e60e87a4d9bd320c86764736f6c63430008070033",
+}
+
+// CliquePocrSessionStorageABI is the input ABI used to generate the binding from.
+// Deprecated: Use CliquePocrSessionStorageMetaData.ABI instead.
+var CliquePocrSessionStorageABI = CliquePocrSessionStorageMetaData.ABI
+
+// Deprecated: Use CliquePocrSessionStorageMetaData.Sigs instead.
+// CliquePocrSessionStorageFuncSigs maps the 4-byte function signature to its string representation.
+var CliquePocrSessionStorageFuncSigs = CliquePocrSessionStorageMetaData.Sigs
+
+// CliquePocrSessionStorageBin is the compiled bytecode used for deploying new contracts.
+// Deprecated: Use CliquePocrSessionStorageMetaData.Bin instead.
+var CliquePocrSessionStorageBin = CliquePocrSessionStorageMetaData.Bin
+
+// DeployCliquePocrSessionStorage deploys a new Ethereum contract, binding an instance of CliquePocrSessionStorage to it.
+func DeployCliquePocrSessionStorage(auth *bind.TransactOpts, backend bind.ContractBackend) (common.Address, *types.Transaction, *CliquePocrSessionStorage, error) {
+	parsed, err := CliquePocrSessionStorageMetaData.GetAbi()
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if parsed == nil {
+		return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+	}
+
+	address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex(CliquePocrSessionStorageBin), backend)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &CliquePocrSessionStorage{CliquePocrSessionStorageCaller: CliquePocrSessionStorageCaller{contract: contract}, CliquePocrSessionStorageTransactor: CliquePocrSessionStorageTransactor{contract: contract}, CliquePocrSessionStorageFilterer: CliquePocrSessionStorageFilterer{contract: contract}}, nil
+}
+
+// CliquePocrSessionStorage is an auto generated Go binding around an Ethereum contract.
+type CliquePocrSessionStorage struct {
+	CliquePocrSessionStorageCaller     // Read-only binding to the contract
+	CliquePocrSessionStorageTransactor // Write-only binding to the contract
+	CliquePocrSessionStorageFilterer   // Log filterer for contract events
+}
+
+// CliquePocrSessionStorageCaller is an auto generated read-only Go binding around an Ethereum contract.
+type CliquePocrSessionStorageCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// CliquePocrSessionStorageTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type CliquePocrSessionStorageTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// CliquePocrSessionStorageFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type CliquePocrSessionStorageFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// CliquePocrSessionStorageSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type CliquePocrSessionStorageSession struct {
+	Contract     *CliquePocrSessionStorage // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts             // Call options to use throughout this session
+	TransactOpts bind.TransactOpts         // Transaction auth options to use throughout this session
+}
+
+// CliquePocrSessionStorageCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type CliquePocrSessionStorageCallerSession struct {
+	Contract *CliquePocrSessionStorageCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts                   // Call options to use throughout this session
+}
+
+// CliquePocrSessionStorageTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type CliquePocrSessionStorageTransactorSession struct {
+	Contract     *CliquePocrSessionStorageTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts                   // Transaction auth options to use throughout this session
+}
+
+// CliquePocrSessionStorageRaw is an auto generated low-level Go binding around an Ethereum contract.
+type CliquePocrSessionStorageRaw struct {
+	Contract *CliquePocrSessionStorage // Generic contract binding to access the raw methods on
+}
+
+// CliquePocrSessionStorageCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type CliquePocrSessionStorageCallerRaw struct {
+	Contract *CliquePocrSessionStorageCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// CliquePocrSessionStorageTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type CliquePocrSessionStorageTransactorRaw struct {
+	Contract *CliquePocrSessionStorageTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewCliquePocrSessionStorage creates a new instance of CliquePocrSessionStorage, bound to a specific deployed contract.
+func NewCliquePocrSessionStorage(address common.Address, backend bind.ContractBackend) (*CliquePocrSessionStorage, error) {
+	contract, err := bindCliquePocrSessionStorage(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &CliquePocrSessionStorage{CliquePocrSessionStorageCaller: CliquePocrSessionStorageCaller{contract: contract}, CliquePocrSessionStorageTransactor: CliquePocrSessionStorageTransactor{contract: contract}, CliquePocrSessionStorageFilterer: CliquePocrSessionStorageFilterer{contract: contract}}, nil
+}
+
+// NewCliquePocrSessionStorageCaller creates a new read-only instance of CliquePocrSessionStorage, bound to a specific deployed contract.
+func NewCliquePocrSessionStorageCaller(address common.Address, caller bind.ContractCaller) (*CliquePocrSessionStorageCaller, error) {
+	contract, err := bindCliquePocrSessionStorage(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &CliquePocrSessionStorageCaller{contract: contract}, nil
+}
+
+// NewCliquePocrSessionStorageTransactor creates a new write-only instance of CliquePocrSessionStorage, bound to a specific deployed contract.
+func NewCliquePocrSessionStorageTransactor(address common.Address, transactor bind.ContractTransactor) (*CliquePocrSessionStorageTransactor, error) {
+	contract, err := bindCliquePocrSessionStorage(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &CliquePocrSessionStorageTransactor{contract: contract}, nil
+}
+
+// NewCliquePocrSessionStorageFilterer creates a new log filterer instance of CliquePocrSessionStorage, bound to a specific deployed contract.
+func NewCliquePocrSessionStorageFilterer(address common.Address, filterer bind.ContractFilterer) (*CliquePocrSessionStorageFilterer, error) {
+	contract, err := bindCliquePocrSessionStorage(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &CliquePocrSessionStorageFilterer{contract: contract}, nil
+}
+
+// bindCliquePocrSessionStorage binds a generic wrapper to an already deployed contract.
+func bindCliquePocrSessionStorage(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(CliquePocrSessionStorageABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_CliquePocrSessionStorage *CliquePocrSessionStorageRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _CliquePocrSessionStorage.Contract.CliquePocrSessionStorageCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_CliquePocrSessionStorage *CliquePocrSessionStorageRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _CliquePocrSessionStorage.Contract.CliquePocrSessionStorageTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_CliquePocrSessionStorage *CliquePocrSessionStorageRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _CliquePocrSessionStorage.Contract.CliquePocrSessionStorageTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_CliquePocrSessionStorage *CliquePocrSessionStorageCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _CliquePocrSessionStorage.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_CliquePocrSessionStorage *CliquePocrSessionStorageTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _CliquePocrSessionStorage.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_CliquePocrSessionStorage *CliquePocrSessionStorageTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _CliquePocrSessionStorage.Contract.contract.Transact(opts, method, params...)
+}
+
+// RetrieveSessionVariable is a free data retrieval call binding the contract method 0x403e6fc4.
+//
+// Solidity: function retrieveSessionVariable(string variableName) view returns(uint256)
+func (_CliquePocrSessionStorage *CliquePocrSessionStorageCaller) RetrieveSessionVariable(opts *bind.CallOpts, variableName string) (*big.Int, error) {
+	var out []interface{}
+	err := _CliquePocrSessionStorage.contract.Call(opts, &out, "retrieveSessionVariable", variableName)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// RetrieveSessionVariable is a free data retrieval call binding the contract method 0x403e6fc4.
+//
+// Solidity: function retrieveSessionVariable(string variableName) view returns(uint256)
+func (_CliquePocrSessionStorage *CliquePocrSessionStorageSession) RetrieveSessionVariable(variableName string) (*big.Int, error) {
+	return _CliquePocrSessionStorage.Contract.RetrieveSessionVariable(&_CliquePocrSessionStorage.CallOpts, variableName)
+}
+
+// RetrieveSessionVariable is a free data retrieval call binding the contract method 0x403e6fc4.
+//
+// Solidity: function retrieveSessionVariable(string variableName) view returns(uint256)
+func (_CliquePocrSessionStorage *CliquePocrSessionStorageCallerSession) RetrieveSessionVariable(variableName string) (*big.Int, error) {
+	return _CliquePocrSessionStorage.Contract.RetrieveSessionVariable(&_CliquePocrSessionStorage.CallOpts, variableName)
+}
@@ -0,0 +1,188 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package cliquepocr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/cliquepocr/contracts"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// GenesisSealer is an initial sealer of a PoCR chain.
+type GenesisSealer struct {
+	Address    common.Address
+	Footprint  *big.Int // Audited footprint, nil or zero if the sealer is not audited
+	AuditBlock *big.Int // Block the footprint is audited at for the audit age penalty, nil for the genesis
+}
+
+// GenesisContracts is the initial content of the PoCR system contracts: the
+// sealers and their footprint, the owner of the PoCR contract and the
+// governance session variables.
+type GenesisContracts struct {
+	Sealers    []GenesisSealer
+	Owner      *common.Address     // Owner of the PoCR contract, nil to leave it to the first caller of setOwner
+	Governance map[string]*big.Int // Governance session variables, by name
+}
+
+// Alloc returns the genesis accounts of the PoCR contract and of the session
+// variables contract, with their code and initial storage. The PoCR contract
+// holds the footprints with the counters of the registry, the audit records of
+// the engine and the sealers, stored in the ascending order of their address,
+// the one of the signers in the genesis extra-data.
+func (g *GenesisContracts) Alloc() (core.GenesisAlloc, error) {
+	if len(g.Sealers) == 0 {
+		return nil, errors.New("no sealer")
+	}
+	sealers := make([]GenesisSealer, len(g.Sealers))
+	copy(sealers, g.Sealers)
+	sort.Slice(sealers, func(i, j int) bool {
+		return bytes.Compare(sealers[i].Address[:], sealers[j].Address[:]) < 0
+	})
+	var (
+		pocr         = make(map[common.Hash]common.Hash)
+		nbFootprints int64
+		total        = new(big.Int)
+	)
+	for i, sealer := range sealers {
+		if i > 0 && sealer.Address == sealers[i-1].Address {
+			return nil, fmt.Errorf("duplicate sealer %x", sealer.Address)
+		}
+		key := common.BytesToHash(sealer.Address.Bytes())
+		pocr[mappingLocation(slotSealers, common.BigToHash(big.NewInt(int64(i))))] = key
+		pocr[mappingLocation(slotIsSealer, key)] = common.BigToHash(common.Big1)
+
+		if sealer.Footprint != nil {
+			if sealer.Footprint.Sign() < 0 {
+				return nil, fmt.Errorf("negative footprint for sealer %x", sealer.Address)
+			}
+			if sealer.Footprint.Sign() > 0 {
+				pocr[mappingLocation(slotFootprint, key)] = common.BigToHash(sealer.Footprint)
+				pocr[mappingLocation(slotAuditedFootprint, key)] = common.BigToHash(sealer.Footprint)
+				nbFootprints++
+				total.Add(total, sealer.Footprint)
+			}
+		}
+		if sealer.AuditBlock != nil {
+			if sealer.AuditBlock.Sign() < 0 {
+				return nil, fmt.Errorf("negative audit block for sealer %x", sealer.Address)
+			}
+			if sealer.Footprint == nil || sealer.Footprint.Sign() == 0 {
+				return nil, fmt.Errorf("audit block for sealer %x without footprint", sealer.Address)
+			}
+			if sealer.AuditBlock.Sign() > 0 {
+				pocr[mappingLocation(slotAuditBlock, key)] = common.BigToHash(sealer.AuditBlock)
+			}
+		}
+	}
+	pocr[slotHash(slotNbNodes)] = common.BigToHash(big.NewInt(int64(len(sealers))))
+	if nbFootprints > 0 {
+		pocr[slotHash(slotNbFootprints)] = common.BigToHash(big.NewInt(nbFootprints))
+		pocr[slotHash(slotTotalFootprint)] = common.BigToHash(total)
+	}
+	if g.Owner != nil && *g.Owner != (common.Address{}) {
+		pocr[slotHash(slotOwner)] = common.BytesToHash(g.Owner.Bytes())
+	}
+
+	session, err := governanceStorage(g.Governance)
+	if err != nil {
+		return nil, err
+	}
+	pocrCode, err := deployedCode(contracts.CliquePocrMetaData)
+	if err != nil {
+		return nil, err
+	}
+	sessionCode, err := deployedCode(contracts.CliquePocrSessionStorageMetaData)
+	if err != nil {
+		return nil, err
+	}
+	return core.GenesisAlloc{
+		common.HexToAddress(proofOfCarbonReductionContractAddress): {
+			Balance: new(big.Int),
+			Code:    pocrCode,
+			Storage: pocr,
+		},
+		common.HexToAddress(sessionVariablesContractAddress): {
+			Balance: new(big.Int),
+			Code:    sessionCode,
+			Storage: session,
+		},
+	}, nil
+}
+
+// ExtraData returns the clique extra-data of the genesis, listing the sealers
+// as the initial signers in ascending order.
+func (g *GenesisContracts) ExtraData() []byte {
+	signers := make([]common.Address, len(g.Sealers))
+	for i, sealer := range g.Sealers {
+		signers[i] = sealer.Address
+	}
+	sort.Slice(signers, func(i, j int) bool {
+		return bytes.Compare(signers[i][:], signers[j][:]) < 0
+	})
+	extra := make([]byte, extraVanity+len(signers)*common.AddressLength+crypto.SignatureLength)
+	for i, signer := range signers {
+		copy(extra[extraVanity+i*common.AddressLength:], signer[:])
+	}
+	return extra
+}
+
+// governanceStorage returns the storage of the session variables contract for
+// the given governance parameters, which must be known and within their range.
+func governanceStorage(values map[string]*big.Int) (map[common.Hash]common.Hash, error) {
+	storage := make(map[common.Hash]common.Hash)
+	for name, value := range values {
+		var known bool
+		for _, variable := range governanceVariables {
+			if variable.name != name {
+				continue
+			}
+			if value == nil || value.Cmp(variable.min) < 0 || value.Cmp(variable.max) > 0 {
+				return nil, fmt.Errorf("governance parameter %s out of range [%v, %v]: %v", name, variable.min, variable.max, value)
+			}
+			known = true
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown governance parameter %s", name)
+		}
+		storage[common.BytesToHash(crypto.Keccak256([]byte(name)))] = common.BigToHash(value)
+	}
+	return storage, nil
+}
+
+// slotHash returns the storage location of a state variable of the contract.
+func slotHash(slot uint) common.Hash {
+	return common.BigToHash(new(big.Int).SetUint64(uint64(slot)))
+}
+
+// deployedCode returns the runtime code of a contract of the bindings, the code
+// of the genesis alloc.
+func deployedCode(meta *bind.MetaData) ([]byte, error) {
+	code, _, _, err := runtime.Create(common.FromHex(meta.Bin), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deploy the contract code: %v", err)
+	}
+	return code, nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package cliquepocr
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/cliquepocr/contracts"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the genesis contracts read back through the contract calls and the
+// engine as they were described, and that the registry keeps working from them.
+func TestGenesisContracts(t *testing.T) {
+	sealers := []common.Address{common.HexToAddress("0x03"), common.HexToAddress("0x01"), common.HexToAddress("0x02")}
+	owner := common.HexToAddress("0x0f")
+	genesis := &GenesisContracts{
+		Sealers: []GenesisSealer{
+			{Address: sealers[0], Footprint: big.NewInt(3000), AuditBlock: big.NewInt(0)},
+			{Address: sealers[1], Footprint: big.NewInt(1000), AuditBlock: big.NewInt(5)},
+			{Address: sealers[2]},
+		},
+		Owner:      &owner,
+		Governance: map[string]*big.Int{"RankDecay": big.NewInt(80)},
+	}
+	alloc, err := genesis.Alloc()
+	if err != nil {
+		t.Fatalf("failed to build the genesis alloc: %v", err)
+	}
+	db := rawdb.NewMemoryDatabase()
+	block := (&core.Genesis{Config: params.AllCliqueProtocolChanges, ExtraData: genesis.ExtraData(), Alloc: alloc}).MustCommit(db)
+	statedb, err := state.New(block.Root(), state.NewDatabase(db), nil)
+	if err != nil {
+		t.Fatalf("failed to open the genesis state: %v", err)
+	}
+	// Read the contract as the first block does
+	contract := NewCarbonFootPrintContract(common.Address{}, params.AllCliqueProtocolChanges, statedb, &types.Header{Number: big.NewInt(1)})
+	call := func(method string, args ...interface{}) interface{} {
+		t.Helper()
+		out, err := contract.call(method, args...)
+		if err != nil {
+			t.Fatalf("%s failed: %v", method, err)
+		}
+		return out[0]
+	}
+	if nbNodes := contract.getNbNodes(); nbNodes != 3 {
+		t.Fatalf("nbNodes mismatch: have %v, want 3", nbNodes)
+	}
+	footprints := map[common.Address]int64{sealers[0]: 3000, sealers[1]: 1000, sealers[2]: 0}
+	auditBlocks := map[common.Address]int64{sealers[0]: 0, sealers[1]: 5, sealers[2]: 0}
+	for i, want := range []common.Address{sealers[1], sealers[2], sealers[0]} {
+		if have := contract.getSealerAt(int64(i)); have != want {
+			t.Errorf("sealer %d mismatch: have %x, want %x", i, have, want)
+		}
+		if !contract.getIsSealerOf(want) {
+			t.Errorf("sealer %x not registered", want)
+		}
+		if have := call("footprint", want).(*big.Int); have.Int64() != footprints[want] {
+			t.Errorf("footprint of %x mismatch: have %v, want %d", want, have, footprints[want])
+		}
+		if have := contract.footprint(want); have.Int64() != footprints[want] {
+			t.Errorf("stored footprint of %x mismatch: have %v, want %d", want, have, footprints[want])
+		}
+		if have := contract.auditBlock(want, contract.footprint(want), big.NewInt(1)); have.Int64() != auditBlocks[want] {
+			t.Errorf("audit block of %x mismatch: have %v, want %d", want, have, auditBlocks[want])
+		}
+	}
+	if have := call("nbNodes").(*big.Int); have.Int64() != 2 {
+		t.Errorf("registry nbNodes mismatch: have %v, want 2", have)
+	}
+	if have := call("totalFootprint").(*big.Int); have.Int64() != 4000 {
+		t.Errorf("total footprint mismatch: have %v, want 4000", have)
+	}
+	if have := call("owner").(common.Address); have != owner {
+		t.Errorf("owner mismatch: have %x, want %x", have, owner)
+	}
+	// The counters of the registry allow removing a genesis footprint
+	pocrABI, _ := contracts.CliquePocrMetaData.GetAbi()
+	input, _ := pocrABI.Pack("setFootprint", sealers[0], new(big.Int))
+	cfg := *contract.RuntimeConfig
+	if _, _, err := runtime.Call(contract.ContractAddress, input, &cfg); err != nil {
+		t.Fatalf("failed to remove a genesis footprint: %v", err)
+	}
+	contract = NewCarbonFootPrintContract(common.Address{}, params.AllCliqueProtocolChanges, statedb, &types.Header{Number: big.NewInt(2)})
+	if have := call("totalFootprint").(*big.Int); have.Int64() != 1000 {
+		t.Errorf("total footprint after removal mismatch: have %v, want 1000", have)
+	}
+	values := readGovernanceValues(statedb)
+	if fork := values.apply(&params.DefaultPoCRFork); fork.RankDecay != 80 {
+		t.Errorf("rank decay mismatch: have %d, want 80", fork.RankDecay)
+	}
+}
+
+// Tests that invalid genesis descriptions are rejected.
+func TestGenesisContractsErrors(t *testing.T) {
+	sealer := GenesisSealer{Address: common.HexToAddress("0x01")}
+	tests := []*GenesisContracts{
+		{},
+		{Sealers: []GenesisSealer{sealer, sealer}},
+		{Sealers: []GenesisSealer{{Address: sealer.Address, Footprint: big.NewInt(-1)}}},
+		{Sealers: []GenesisSealer{{Address: sealer.Address, Footprint: big.NewInt(1), AuditBlock: big.NewInt(-1)}}},
+		{Sealers: []GenesisSealer{{Address: sealer.Address, AuditBlock: big.NewInt(1)}}},
+		{Sealers: []GenesisSealer{sealer}, Governance: map[string]*big.Int{"Unknown": big.NewInt(1)}},
+		{Sealers: []GenesisSealer{sealer}, Governance: map[string]*big.Int{"AuditPenalty": big.NewInt(101)}},
+	}
+	for i, contracts := range tests {
+		if _, err := contracts.Alloc(); err == nil {
+			t.Errorf("test %d: invalid description accepted", i)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package cliquepocr
+
+import (
+	"math/big"
+)
+
+// inflationPrecision is the number of fractional bits of the fixed-point
+// inflation control factor.
+const inflationPrecision = 128
+
+// ln2Fixed is ln(2) with inflationPrecision fractional bits, rounded down.
+var ln2Fixed, _ = new(big.Int).SetString("b17217f7d1cf79abc9e3b39803f2f6af", 16)
+
+/*
+	inverseExp returns e^-x for a positive x in fixed point, as a rational whose
+	denominator is at most 2^inflationPrecision.
+
+	Only integer operations are used, so the result is the same on every platform.
+	x is split into k.ln2 + r (0 <= r < ln2) and e^-x = 2^-(k+1) . e^(ln2-r), where
+	the exponential is summed as its series until the terms vanish (about 35 terms).
+	Every truncation is below 2^-128 and there are less than 2^8 of them, hence
+	|inverseExp(x) - e^-x| < 2^-120. Values below that bound are returned as zero.
+*/
+func inverseExp(x *big.Rat) *big.Rat {
+	one := new(big.Int).Lsh(big.NewInt(1), inflationPrecision)
+
+	// X = x in fixed point, rounded down
+	X := new(big.Int).Lsh(x.Num(), inflationPrecision)
+	X.Quo(X, x.Denom())
+
+	// X = k.ln2 + r
+	k, r := new(big.Int).QuoRem(X, ln2Fixed, new(big.Int))
+	if !k.IsUint64() || k.Uint64() >= inflationPrecision {
+		return new(big.Rat)
+	}
+	// e^s with s = ln2 - r in ]0, ln2]
+	s := r.Sub(ln2Fixed, r)
+	sum, term := new(big.Int).Set(one), new(big.Int).Set(one)
+	for n := int64(1); term.Sign() > 0; n++ {
+		term.Mul(term, s)
+		term.Rsh(term, inflationPrecision)
+		term.Quo(term, big.NewInt(n))
+		sum.Add(sum, term)
+	}
+	sum.Rsh(sum, uint(k.Uint64()+1))
+
+	return new(big.Rat).SetFrac(sum, one)
+}
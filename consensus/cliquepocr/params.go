@@ -0,0 +1,125 @@
+package cliquepocr
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Number of seconds per year the yearly figures are spread over
+const secondsPerYear = 365 * 24 * 3600
+
+// RewardParams are the PoCR economic parameters in force at a block, converted
+// from the chain configuration to blocks and crypto units.
+type RewardParams struct {
+	BlocksBetweenAudit   *big.Int // Blocks after which an audited footprint gets penalized
+	AuditPenalty         int64    // Footprint penalty in percent per elapsed audit validity
+	InflationDenominator *big.Int // Total generated coins per step of the inflation control
+	MinCreationPerBlock  *big.Rat // Minimum amount minted per block, in crypto units
+	RankDecay            *big.Rat // Rank multiplier per better ranked sealer
+	AlphaFactor          *big.Rat // Inflation control exponent factor
+	ExactInflation       bool     // Fixed-point inflation control instead of the Taylor series
+}
+
+// NewRewardParams converts a PoCR fork of the chain configuration given the
+// block period its durations are expressed with.
+func NewRewardParams(fork *params.PoCRFork, period uint64) *RewardParams {
+	blocksBetweenAudit := fork.AuditValidity / period
+	if blocksBetweenAudit == 0 {
+		blocksBetweenAudit = 1
+	}
+	// minimum creation per year x period / seconds per year, in crypto units
+	minCreation := new(big.Rat).SetFrac(new(big.Int).Mul(fork.MinCreationPerYear, new(big.Int).SetUint64(period)), big.NewInt(secondsPerYear))
+	minCreation.Mul(minCreation, new(big.Rat).SetInt(CTCUnit))
+
+	return &RewardParams{
+		BlocksBetweenAudit:   new(big.Int).SetUint64(blocksBetweenAudit),
+		AuditPenalty:         int64(fork.AuditPenalty),
+		InflationDenominator: new(big.Int).Set(fork.InflationDenominator),
+		MinCreationPerBlock:  minCreation,
+		RankDecay:            big.NewRat(int64(fork.RankDecay), 100),
+		AlphaFactor:          big.NewRat(int64(fork.AlphaFactor), 100),
+		ExactInflation:       fork.ExactInflation,
+	}
+}
+
+// DefaultRewardParams returns the parameters of the chains having no PoCR
+// configuration.
+func DefaultRewardParams() *RewardParams {
+	return NewRewardParams(&params.DefaultPoCRFork, params.DefaultPoCRPeriod)
+}
+
+// governanceVariable is a PoCR economic parameter the governance sets through a
+// session variable, overriding the value of the chain configuration. A zero
+// value leaves the parameter unset.
+type governanceVariable struct {
+	name     string   // Name of the session variable
+	min, max *big.Int // Range of the accepted values
+	set      func(fork *params.PoCRFork, value *big.Int)
+}
+
+// governanceVariables are the PoCR parameters set by the governance, in the
+// units of the chain configuration.
+var governanceVariables = [...]governanceVariable{
+	{"AuditValidity", big.NewInt(24 * 3600), big.NewInt(10 * secondsPerYear), func(fork *params.PoCRFork, value *big.Int) { fork.AuditValidity = value.Uint64() }},
+	{"AuditPenalty", big.NewInt(1), big.NewInt(100), func(fork *params.PoCRFork, value *big.Int) { fork.AuditPenalty = value.Uint64() }},
+	{"InflationDenominator", big.NewInt(1000), big.NewInt(1e15), func(fork *params.PoCRFork, value *big.Int) { fork.InflationDenominator = value }},
+	{"MinCreationPerYear", big.NewInt(1), big.NewInt(1e12), func(fork *params.PoCRFork, value *big.Int) { fork.MinCreationPerYear = value }},
+	{"RankDecay", big.NewInt(1), big.NewInt(100), func(fork *params.PoCRFork, value *big.Int) { fork.RankDecay = value.Uint64() }},
+	{"AlphaFactor", big.NewInt(1), big.NewInt(1000), func(fork *params.PoCRFork, value *big.Int) { fork.AlphaFactor = value.Uint64() }},
+}
+
+// GovernanceParameter is a PoCR parameter the governance sets through a session
+// variable, along with the range of its accepted values.
+type GovernanceParameter struct {
+	Name     string
+	Min, Max *big.Int
+}
+
+// GovernanceParameters returns the PoCR parameters set by the governance.
+func GovernanceParameters() []GovernanceParameter {
+	parameters := make([]GovernanceParameter, len(governanceVariables))
+	for i, variable := range governanceVariables {
+		parameters[i] = GovernanceParameter{
+			Name: variable.name,
+			Min:  new(big.Int).Set(variable.min),
+			Max:  new(big.Int).Set(variable.max),
+		}
+	}
+	return parameters
+}
+
+// governanceValues are the raw values of the governance session variables in a
+// state, zero when unset. They identify the parameters the governance sets.
+type governanceValues [len(governanceVariables)]common.Hash
+
+// readGovernanceValues reads the governance session variables of a state.
+func readGovernanceValues(state *state.StateDB) governanceValues {
+	var values governanceValues
+	for i, variable := range governanceVariables {
+		values[i] = common.BigToHash(ReadSessionVariable(variable.name, state))
+	}
+	return values
+}
+
+// apply returns the PoCR parameters of the chain configuration overridden by the
+// governance values. The values out of their range are ignored, falling back to
+// the configuration: a wrong vote must not halt the chain.
+func (values *governanceValues) apply(fork *params.PoCRFork) *params.PoCRFork {
+	governed := *fork
+	for i, variable := range governanceVariables {
+		value := values[i].Big()
+		if value.Sign() == 0 {
+			continue
+		}
+		if value.Cmp(variable.min) < 0 || value.Cmp(variable.max) > 0 {
+			log.Debug("Ignoring out of range PoCR governance parameter", "name", variable.name, "value", value, "min", variable.min, "max", variable.max)
+			continue
+		}
+		variable.set(&governed, value)
+	}
+	return &governed
+}
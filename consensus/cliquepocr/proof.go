@@ -0,0 +1,226 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package cliquepocr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// errProofBeforeFork is returned when the rewards of a block before the PoCR
+// fork are requested from proofs: the footprints of these blocks are read by
+// running the code of the contract, which the proofs do not give.
+var errProofBeforeFork = errors.New("rewards before the footprint storage fork cannot be proven")
+
+// StorageProof is the Merkle proof of a storage slot, as returned by the
+// eth_getProof RPC method.
+type StorageProof struct {
+	Key   common.Hash     `json:"key"`
+	Value *hexutil.Big    `json:"value"`
+	Proof []hexutil.Bytes `json:"proof"`
+}
+
+// AccountProof is the Merkle proof of an account and of some of its storage
+// slots, as returned by the eth_getProof RPC method.
+type AccountProof struct {
+	Address      common.Address  `json:"address"`
+	AccountProof []hexutil.Bytes `json:"accountProof"`
+	StorageHash  common.Hash     `json:"storageHash"`
+	StorageProof []StorageProof  `json:"storageProof"`
+}
+
+// ProofFetcher retrieves the proof of an account and of the given storage slots
+// in the state of a block.
+type ProofFetcher func(ctx context.Context, account common.Address, keys []common.Hash, block common.Hash) (*AccountProof, error)
+
+// RPCProofFetcher returns a proof fetcher retrieving the proofs from a node with
+// eth_getProof.
+func RPCProofFetcher(client *rpc.Client) ProofFetcher {
+	return func(ctx context.Context, account common.Address, keys []common.Hash, block common.Hash) (*AccountProof, error) {
+		proof := new(AccountProof)
+		if err := client.CallContext(ctx, proof, "eth_getProof", account, keys, rpc.BlockNumberOrHashWithHash(block, false)); err != nil {
+			return nil, err
+		}
+		return proof, nil
+	}
+}
+
+// SetProofSource sets the source of the proofs the rewards are verified with
+// when the chain gives no access to the state, as on the light clients.
+func (c *CliquePoCR) SetProofSource(fetch ProofFetcher) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.proofs = fetch
+}
+
+// proofSource returns the source of the proofs, nil if none is set.
+func (c *CliquePoCR) proofSource() ProofFetcher {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.proofs
+}
+
+// rewardProofKeys returns the storage slots the rewards of a block are computed
+// from: the entries of the signers in the signerSlots mappings of the PoCR
+// contract, read in the state of the block (the rewards do not alter them), and the total crypto amount and the
+// governance variables of the session variables contract, read in the state of
+// the parent.
+func rewardProofKeys(signers []common.Address) (pocr []common.Hash, session []common.Hash) {
+	for _, signer := range signers {
+		key := common.BytesToHash(signer.Bytes())
+		for _, slot := range signerSlots {
+			pocr = append(pocr, mappingLocation(slot, key))
+		}
+	}
+	session = append(session, common.BytesToHash(crypto.Keccak256([]byte(sessionVariableTotalPocRCoins))))
+	for _, variable := range governanceVariables {
+		session = append(session, common.BytesToHash(crypto.Keccak256([]byte(variable.name))))
+	}
+	return pocr, session
+}
+
+// verifyProof checks the proof of an account and of the given storage slots
+// against a state root. The proof must hold a valid proof, of inclusion or of
+// exclusion, of every slot: the state built from it reads a slot missing from
+// the proof as empty instead of failing.
+func verifyProof(root common.Hash, address common.Address, keys []common.Hash, proof *AccountProof) error {
+	if proof.Address != address {
+		return fmt.Errorf("proof of account %x instead of %x", proof.Address, address)
+	}
+	db := memorydb.New()
+	for _, node := range proof.AccountProof {
+		db.Put(crypto.Keccak256(node), node)
+	}
+	enc, err := trie.VerifyProof(root, crypto.Keccak256(address.Bytes()), db)
+	if err != nil {
+		return fmt.Errorf("invalid account proof: %v", err)
+	}
+	storageRoot := types.EmptyRootHash
+	if enc != nil {
+		var account types.StateAccount
+		if err := rlp.DecodeBytes(enc, &account); err != nil {
+			return fmt.Errorf("invalid account: %v", err)
+		}
+		storageRoot = account.Root
+	}
+	if proof.StorageHash != storageRoot {
+		return fmt.Errorf("storage root mismatch: have %x, want %x", proof.StorageHash, storageRoot)
+	}
+	// The slots of an empty storage are all empty, with nothing to prove
+	if storageRoot == types.EmptyRootHash {
+		return nil
+	}
+	slots := make(map[common.Hash]StorageProof, len(proof.StorageProof))
+	for _, slot := range proof.StorageProof {
+		slots[slot.Key] = slot
+	}
+	for _, key := range keys {
+		slot, ok := slots[key]
+		if !ok {
+			return fmt.Errorf("missing proof of slot %x", key)
+		}
+		db := memorydb.New()
+		for _, node := range slot.Proof {
+			db.Put(crypto.Keccak256(node), node)
+		}
+		if _, err := trie.VerifyProof(storageRoot, crypto.Keccak256(key.Bytes()), db); err != nil {
+			return fmt.Errorf("invalid proof of slot %x: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// proofState verifies the proof of an account and of the given storage slots
+// against a state root, and returns a read only state made of its trie nodes.
+func proofState(root common.Hash, address common.Address, keys []common.Hash, proof *AccountProof) (*state.StateDB, error) {
+	if err := verifyProof(root, address, keys, proof); err != nil {
+		return nil, err
+	}
+	db := rawdb.NewMemoryDatabase()
+	for _, node := range proof.AccountProof {
+		rawdb.WriteTrieNode(db, crypto.Keccak256Hash(node), node)
+	}
+	for _, slot := range proof.StorageProof {
+		for _, node := range slot.Proof {
+			rawdb.WriteTrieNode(db, crypto.Keccak256Hash(node), node)
+		}
+	}
+	return state.New(root, state.NewDatabase(db), nil)
+}
+
+// ProvenRewards computes the rewards of a block from the Merkle proofs of the
+// storage slots they depend on instead of the local state, the proofs being
+// checked against the state roots of the block and of its parent. It only needs
+// the headers of the chain, so that a light client can check the rewards a full
+// node reports. The fee adjustment and the burnt fees are only computed if the
+// receipts of the block are given. Only the blocks from the footprint storage
+// fork on, whose footprints are read from the storage, can be proven.
+func (c *CliquePoCR) ProvenRewards(ctx context.Context, chain consensus.ChainHeaderReader, header *types.Header, receipts types.Receipts, fetch ProofFetcher) (*BlockRewards, error) {
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil, errGenesisReward
+	}
+	if !c.config.IsFootprintStorage(header.Number) {
+		return nil, errProofBeforeFork
+	}
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	signers, err := c.getSigners(chain, header, nil)
+	if err != nil {
+		return nil, err
+	}
+	pocrKeys, sessionKeys := rewardProofKeys(signers)
+
+	var (
+		pocrAddress    = common.HexToAddress(proofOfCarbonReductionContractAddress)
+		sessionAddress = common.HexToAddress(sessionVariablesContractAddress)
+	)
+	pocrProof, err := fetch(ctx, pocrAddress, pocrKeys, header.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve the PoCR contract proof: %v", err)
+	}
+	sessionProof, err := fetch(ctx, sessionAddress, sessionKeys, parent.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve the session variables proof: %v", err)
+	}
+	statedb, err := proofState(header.Root, pocrAddress, pocrKeys, pocrProof)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid PoCR contract proof: %v", ErrContractCall, err)
+	}
+	parentState, err := proofState(parent.Root, sessionAddress, sessionKeys, sessionProof)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid session variables proof: %v", ErrContractCall, err)
+	}
+	return c.BlockRewards(chain, header, statedb, parentState, receipts)
+}
@@ -0,0 +1,289 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package cliquepocr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/cliquepocr/contracts"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testProofService serves eth_getProof from the state of a chain, with the
+// response format of the ethapi package.
+type testProofService struct {
+	chain *core.BlockChain
+}
+
+type testAccountResult struct {
+	Address      common.Address      `json:"address"`
+	AccountProof []string            `json:"accountProof"`
+	Balance      *hexutil.Big        `json:"balance"`
+	CodeHash     common.Hash         `json:"codeHash"`
+	Nonce        hexutil.Uint64      `json:"nonce"`
+	StorageHash  common.Hash         `json:"storageHash"`
+	StorageProof []testStorageResult `json:"storageProof"`
+}
+
+type testStorageResult struct {
+	Key   string       `json:"key"`
+	Value *hexutil.Big `json:"value"`
+	Proof []string     `json:"proof"`
+}
+
+func toHexSlice(b [][]byte) []string {
+	r := make([]string, len(b))
+	for i := range b {
+		r[i] = hexutil.Encode(b[i])
+	}
+	return r
+}
+
+func (s *testProofService) GetProof(address common.Address, storageKeys []string, blockNrOrHash rpc.BlockNumberOrHash) (*testAccountResult, error) {
+	hash, ok := blockNrOrHash.Hash()
+	if !ok {
+		return nil, errors.New("block hash expected")
+	}
+	header := s.chain.GetHeaderByHash(hash)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	statedb, err := s.chain.StateAt(header.Root)
+	if err != nil {
+		return nil, err
+	}
+	result := &testAccountResult{
+		Address:     address,
+		Balance:     (*hexutil.Big)(statedb.GetBalance(address)),
+		CodeHash:    statedb.GetCodeHash(address),
+		Nonce:       hexutil.Uint64(statedb.GetNonce(address)),
+		StorageHash: statedb.StorageTrie(address).Hash(),
+	}
+	for _, key := range storageKeys {
+		proof, err := statedb.GetStorageProof(address, common.HexToHash(key))
+		if err != nil {
+			return nil, err
+		}
+		result.StorageProof = append(result.StorageProof, testStorageResult{key, (*hexutil.Big)(statedb.GetState(address, common.HexToHash(key)).Big()), toHexSlice(proof)})
+	}
+	proof, err := statedb.GetProof(address)
+	if err != nil {
+		return nil, err
+	}
+	result.AccountProof = toHexSlice(proof)
+	return result, nil
+}
+
+// newTestProofFetcher returns a proof fetcher calling eth_getProof on an in-process
+// RPC server backed by the chain.
+func newTestProofFetcher(t *testing.T, chain *core.BlockChain) ProofFetcher {
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", &testProofService{chain: chain}); err != nil {
+		t.Fatalf("failed to register the proof service: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	t.Cleanup(func() {
+		client.Close()
+		server.Stop()
+	})
+	return RPCProofFetcher(client)
+}
+
+// headerChain hides the state access of a chain, as the light chain does.
+type headerChain struct {
+	consensus.ChainHeaderReader
+}
+
+// Tests that the rewards computed from the proofs of the storage they depend on
+// are the ones computed from the state.
+func TestProvenRewards(t *testing.T) {
+	var (
+		key, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		sender = crypto.PubkeyToAddress(key.PublicKey)
+		signer = types.LatestSigner(params.AllCliqueProtocolChanges)
+	)
+	tc := newForkedTestChain(t, 1000, core.GenesisAlloc{sender: {Balance: big.NewInt(params.Ether)}})
+	defer tc.chain.Stop()
+
+	blocks := tc.extend(t, 3, func(i int, block *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(sender), common.Address{0x01}, big.NewInt(1), params.TxGas, block.BaseFee(), nil), signer, key)
+		block.AddTx(tx)
+	})
+	fetch := newTestProofFetcher(t, tc.chain)
+
+	for _, block := range blocks {
+		want, err := tc.api().GetRewardsAtHash(block.Hash())
+		if err != nil {
+			t.Fatalf("block %d: failed to retrieve rewards: %v", block.NumberU64(), err)
+		}
+		have, err := tc.engine.ProvenRewards(context.Background(), tc.chain, block.Header(), tc.chain.GetReceiptsByHash(block.Hash()), fetch)
+		if err != nil {
+			t.Fatalf("block %d: failed to prove rewards: %v", block.NumberU64(), err)
+		}
+		haveJSON, _ := json.Marshal(have)
+		wantJSON, _ := json.Marshal(want)
+		if string(haveJSON) != string(wantJSON) {
+			t.Errorf("block %d: rewards mismatch:\nhave %s\nwant %s", block.NumberU64(), haveJSON, wantJSON)
+		}
+	}
+}
+
+// Tests that the rewards proven on the registry of the published network genesis
+// are the ones computed from the state, across a footprint update recorded as an
+// audit by the engine.
+func TestProvenRewardsNetworkContract(t *testing.T) {
+	var (
+		key, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		sender = crypto.PubkeyToAddress(key.PublicKey)
+		sealer = crypto.PubkeyToAddress(testSealerKey.PublicKey)
+		signer = types.LatestSigner(params.AllCliqueProtocolChanges)
+		alloc  = networkRegistryAlloc(t, sealer, 1000)
+	)
+	pocrABI, _ := contracts.CliquePocrMetaData.GetAbi()
+	input, _ := pocrABI.Pack("setFootprint", sealer, big.NewInt(2000))
+	alloc[sender] = core.GenesisAccount{Balance: big.NewInt(params.Ether)}
+	tc := newForkedTestChain(t, 0, alloc)
+	defer tc.chain.Stop()
+
+	blocks := tc.extend(t, 3, func(i int, block *core.BlockGen) {
+		if i == 1 {
+			tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(sender), contracts.CliquePocrAddress, new(big.Int), 100000, block.BaseFee(), input), signer, key)
+			block.AddTx(tx)
+		}
+	})
+	fetch := newTestProofFetcher(t, tc.chain)
+
+	for i, block := range blocks {
+		want, err := tc.api().GetRewardsAtHash(block.Hash())
+		if err != nil {
+			t.Fatalf("block %d: failed to retrieve rewards: %v", block.NumberU64(), err)
+		}
+		// the footprint is audited when first read, then at its update
+		footprint, audit := []int64{1000, 2000, 2000}[i], []int64{1, 2, 2}[i]
+		if f := want.Signers[0]; f.Footprint.ToInt().Int64() != footprint || f.FootprintBlock.ToInt().Int64() != audit {
+			t.Fatalf("block %d: footprint mismatch: have %v at %v, want %d at %d", block.NumberU64(), f.Footprint, f.FootprintBlock, footprint, audit)
+		}
+		have, err := tc.engine.ProvenRewards(context.Background(), tc.chain, block.Header(), tc.chain.GetReceiptsByHash(block.Hash()), fetch)
+		if err != nil {
+			t.Fatalf("block %d: failed to prove rewards: %v", block.NumberU64(), err)
+		}
+		haveJSON, _ := json.Marshal(have)
+		wantJSON, _ := json.Marshal(want)
+		if string(haveJSON) != string(wantJSON) {
+			t.Errorf("block %d: rewards mismatch:\nhave %s\nwant %s", block.NumberU64(), haveJSON, wantJSON)
+		}
+	}
+}
+
+// Tests that the rewards are not proven before the footprint storage fork, as
+// the footprints are then read with the getters of the contract.
+func TestProvenRewardsBeforeFork(t *testing.T) {
+	tc := newTestChain(t, 1000, nil)
+	defer tc.chain.Stop()
+
+	blocks := tc.extend(t, 1, nil)
+	fetch := newTestProofFetcher(t, tc.chain)
+	if _, err := tc.engine.ProvenRewards(context.Background(), tc.chain, blocks[0].Header(), nil, fetch); err != errProofBeforeFork {
+		t.Fatalf("error mismatch: have %v, want %v", err, errProofBeforeFork)
+	}
+}
+
+// Tests that the proofs not matching the state roots of the block and of its
+// parent are rejected.
+func TestProvenRewardsInvalidProofs(t *testing.T) {
+	tc := newForkedTestChain(t, 1000, nil)
+	defer tc.chain.Stop()
+
+	blocks := tc.extend(t, 2, nil)
+	fetch := newTestProofFetcher(t, tc.chain)
+
+	tests := map[string]ProofFetcher{
+		// the proofs of another block
+		"stale": func(ctx context.Context, account common.Address, keys []common.Hash, block common.Hash) (*AccountProof, error) {
+			return fetch(ctx, account, keys, tc.chain.Genesis().Hash())
+		},
+		// the account proof without the storage proofs
+		"partial": func(ctx context.Context, account common.Address, keys []common.Hash, block common.Hash) (*AccountProof, error) {
+			proof, err := fetch(ctx, account, keys, block)
+			if err == nil {
+				proof.StorageProof = nil
+			}
+			return proof, err
+		},
+		// the proofs of other slots
+		"unrelated": func(ctx context.Context, account common.Address, keys []common.Hash, block common.Hash) (*AccountProof, error) {
+			return fetch(ctx, account, []common.Hash{{0x01}}, block)
+		},
+	}
+	for name, fetch := range tests {
+		if _, err := tc.engine.ProvenRewards(context.Background(), tc.chain, blocks[1].Header(), nil, fetch); !errors.Is(err, ErrContractCall) {
+			t.Errorf("%s: error mismatch: have %v, want %v", name, err, ErrContractCall)
+		}
+	}
+}
+
+// Tests that the API computes the rewards from the proofs on the chains giving
+// no access to the state, once the engine has a source of proofs.
+func TestAPIProvenRewards(t *testing.T) {
+	tc := newForkedTestChain(t, 1000, nil)
+	defer tc.chain.Stop()
+
+	blocks := tc.extend(t, 2, nil)
+	want, err := tc.api().GetRewardsAtHash(blocks[1].Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve rewards: %v", err)
+	}
+	// The light clients process no block, so they have no reward record
+	rawdb.DeletePoCRReward(tc.db, blocks[1].Hash(), blocks[1].NumberU64())
+
+	api := &API{chain: headerChain{tc.chain}, pocr: tc.engine}
+	if _, err := api.GetRewardsAtHash(blocks[1].Hash()); err != errNoStateAccess {
+		t.Fatalf("error mismatch: have %v, want %v", err, errNoStateAccess)
+	}
+	tc.engine.SetProofSource(newTestProofFetcher(t, tc.chain))
+	defer tc.engine.SetProofSource(nil)
+
+	have, err := api.GetRewardsAtHash(blocks[1].Hash())
+	if err != nil {
+		t.Fatalf("failed to prove rewards: %v", err)
+	}
+	if have.BlockReward.ToInt().Cmp(want.BlockReward.ToInt()) != 0 {
+		t.Errorf("block reward mismatch: have %v, want %v", have.BlockReward, want.BlockReward)
+	}
+	if have.TotalCrypto.ToInt().Cmp(want.TotalCrypto.ToInt()) != 0 {
+		t.Errorf("total crypto mismatch: have %v, want %v", have.TotalCrypto, want.TotalCrypto)
+	}
+	if have.Rank != want.Rank {
+		t.Errorf("rank mismatch: have %v, want %v", have.Rank, want.Rank)
+	}
+	// The light clients have no receipts to adjust the fees with
+	if have.FeeAdjustment != nil || have.Burnt != nil {
+		t.Errorf("fees reported without receipts: adjustment %v, burnt %v", have.FeeAdjustment, have.Burnt)
+	}
+}
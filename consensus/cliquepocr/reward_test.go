@@ -20,10 +20,14 @@ import (
 	"math"
 	"math/big"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 func TestCalculateGlobalInflationControlFactor(t *testing.T) {
-	rewardComputation := NewRaceRankComputation()
+	rewardComputation := NewRaceRankComputation(DefaultRewardParams())
 	n1 := new(big.Int)
 	n1, ok := n1.SetString("20000000000000000000000000", 10)
 	if !ok {
@@ -41,7 +45,7 @@ func TestCalculateGlobalInflationControlFactor(t *testing.T) {
 
 
 func TestCalculateRanking1(t *testing.T) {
-	rewardComputation := NewRaceRankComputation()
+	rewardComputation := NewRaceRankComputation(DefaultRewardParams())
 	cf := make([]*big.Int, 3)
 	cf[0] = big.NewInt(100000)
 	cf[1] = big.NewInt(200000)
@@ -58,6 +62,215 @@ func TestCalculateRanking1(t *testing.T) {
 	}
 }
 
+func TestCalculateRankingProportional(t *testing.T) {
+	rewardComputation := NewProportionalComputation(DefaultRewardParams())
+	cf := []*big.Int{big.NewInt(0), big.NewInt(100000), big.NewInt(200000), big.NewInt(400000)}
+
+	for _, tt := range []struct {
+		footprint int64
+		rank      *big.Rat
+	}{
+		{100000, big.NewRat(1, 1)},
+		{200000, big.NewRat(1, 2)},
+		{400000, big.NewRat(1, 4)},
+		{50000, big.NewRat(1, 1)},
+	} {
+		rank, nodes, err := rewardComputation.CalculateRanking(big.NewInt(tt.footprint), cf)
+		if err != nil {
+			t.Fatalf("footprint %d: ranking failed: %v", tt.footprint, err)
+		}
+		if rank.Cmp(tt.rank) != 0 {
+			t.Errorf("footprint %d: expected rank %v got %v", tt.footprint, tt.rank, rank)
+		}
+		if nodes != len(cf) {
+			t.Errorf("footprint %d: expected %v nodes got %v", tt.footprint, len(cf), nodes)
+		}
+	}
+	if _, _, err := rewardComputation.CalculateRanking(big.NewInt(0), cf); err == nil {
+		t.Errorf("zero footprint ranked")
+	}
+}
+
+func TestRewardAlgorithmFork(t *testing.T) {
+	engine := New(&params.CliqueConfig{
+		Epoch:                30000,
+		RewardAlgorithmForks: []params.RewardAlgorithmFork{{Block: big.NewInt(10), Algorithm: ProportionalAlgorithmId}},
+	}, rawdb.NewMemoryDatabase())
+
+	for _, tt := range []struct {
+		number int64
+		id     int
+	}{{1, RaceRankAlgorithmId}, {9, RaceRankAlgorithmId}, {10, ProportionalAlgorithmId}, {1000, ProportionalAlgorithmId}} {
+		if id := engine.computationAt(params.AllCliqueProtocolChanges, big.NewInt(tt.number), governanceValues{}).GetAlgorithmId(); id != tt.id {
+			t.Errorf("block %d: expected algorithm %d got %d", tt.number, tt.id, id)
+		}
+	}
+}
+
+// referenceInverseExp computes e^-x with a precision way beyond the fixed-point one.
+func referenceInverseExp(x *big.Rat) *big.Float {
+	const prec, halvings = 1024, 16
+
+	// e^-x = (e^-(x/2^16))^(2^16), the series converging quickly on the small value
+	y := new(big.Float).SetPrec(prec).SetRat(x)
+	y.Neg(y).SetMantExp(y, -halvings)
+	sum, term := big.NewFloat(1).SetPrec(prec), big.NewFloat(1).SetPrec(prec)
+	epsilon := new(big.Float).SetMantExp(big.NewFloat(1), -prec)
+	for n := int64(1); new(big.Float).Abs(term).Cmp(epsilon) > 0; n++ {
+		term.Mul(term, y)
+		term.Quo(term, new(big.Float).SetInt64(n))
+		sum.Add(sum, term)
+	}
+	for i := 0; i < halvings; i++ {
+		sum.Mul(sum, sum)
+	}
+	return sum
+}
+
+func TestExactInflationControlFactor(t *testing.T) {
+	p := DefaultRewardParams()
+	p.ExactInflation = true
+	rewardComputation := NewRaceRankComputation(p)
+
+	// Same vector as the Taylor series, e^-1.44 instead of its approximation
+	n1, _ := new(big.Int).SetString("20000000000000000000000000", 10)
+	factor, _ := rewardComputation.CalculateGlobalInflationControlFactor(n1)
+	if have, want := factor.FloatString(6), "0.236928"; have != want {
+		t.Errorf("factor mismatch: have %s, want %s", have, want)
+	}
+	// Check the documented error bound from a single wei up to a hundred times
+	// the inflation denominator, where the factor vanishes
+	var (
+		bound = new(big.Float).SetMantExp(big.NewFloat(1), -120)
+		step  = new(big.Int).Mul(CTCUnit, p.InflationDenominator)
+	)
+	supplies := []*big.Int{big.NewInt(1), big.NewInt(1e9), new(big.Int).Set(CTCUnit)}
+	for i := int64(1); i <= 400; i++ {
+		// quarters of the denominator, slightly offset to avoid round values
+		supply := new(big.Int).Mul(step, big.NewInt(i))
+		supply.Div(supply, big.NewInt(4))
+		supplies = append(supplies, supply, new(big.Int).Add(supply, big.NewInt(i*7919)))
+	}
+	for _, supply := range supplies {
+		factor, err := rewardComputation.CalculateGlobalInflationControlFactor(supply)
+		if err != nil {
+			t.Fatalf("supply %v: %v", supply, err)
+		}
+		if factor.Denom().BitLen() > inflationPrecision+1 {
+			t.Errorf("supply %v: unbounded factor denominator of %d bits", supply, factor.Denom().BitLen())
+		}
+		x := new(big.Rat).SetFrac(supply, step)
+		x.Mul(x, p.AlphaFactor)
+
+		diff := new(big.Float).SetPrec(1024).SetRat(factor)
+		diff.Sub(diff, referenceInverseExp(x))
+		if diff.Abs(diff).Cmp(bound) >= 0 {
+			t.Errorf("supply %v: error %v above the bound", supply, diff)
+		}
+	}
+}
+
+// Tests that the simulator follows the rewards of an actual chain.
+func TestSimulator(t *testing.T) {
+	tc := newTestChain(t, 1000, nil)
+	defer tc.chain.Stop()
+
+	sim, err := NewSimulator(params.AllCliqueProtocolChanges, new(big.Int))
+	if err != nil {
+		t.Fatalf("failed to create simulator: %v", err)
+	}
+	sealers := []SimSealer{{Address: tc.addr, Footprint: big.NewInt(1000), FootprintBlock: new(big.Int)}}
+	for _, block := range tc.extend(t, 3, nil) {
+		record := rawdb.ReadPoCRReward(tc.db, block.Hash(), block.NumberU64())
+		simulated, err := sim.Block(block.NumberU64(), tc.addr, sealers, new(big.Int), record.Burnt)
+		if err != nil {
+			t.Fatalf("block %d: simulation failed: %v", block.NumberU64(), err)
+		}
+		if simulated.BlockReward.Cmp(record.BlockReward) != 0 {
+			t.Errorf("block %d: reward mismatch: have %v, want %v", block.NumberU64(), simulated.BlockReward, record.BlockReward)
+		}
+		if simulated.TotalCrypto.Cmp(record.TotalCryptoAfter) != 0 {
+			t.Errorf("block %d: total crypto mismatch: have %v, want %v", block.NumberU64(), simulated.TotalCrypto, record.TotalCryptoAfter)
+		}
+	}
+}
+
+func TestRewardParams(t *testing.T) {
+	// The default parameters are the ones the chain was launched with
+	p := DefaultRewardParams()
+	if p.BlocksBetweenAudit.Cmp(big.NewInt((3600/4)*24*365)) != 0 {
+		t.Errorf("blocks between audit mismatch: have %v, want %v", p.BlocksBetweenAudit, (3600/4)*24*365)
+	}
+	if p.AuditPenalty != 5 {
+		t.Errorf("audit penalty mismatch: have %v, want 5", p.AuditPenalty)
+	}
+	if p.InflationDenominator.Cmp(big.NewInt(1e7)) != 0 {
+		t.Errorf("inflation denominator mismatch: have %v, want 1e7", p.InflationDenominator)
+	}
+	minCreation := new(big.Rat).Mul(big.NewRat(100000, (3600/4)*24*365), new(big.Rat).SetInt(CTCUnit))
+	if p.MinCreationPerBlock.Cmp(minCreation) != 0 {
+		t.Errorf("minimum creation mismatch: have %v, want %v", p.MinCreationPerBlock, minCreation)
+	}
+	if p.RankDecay.Cmp(big.NewRat(9, 10)) != 0 || p.AlphaFactor.Cmp(big.NewRat(72, 100)) != 0 {
+		t.Errorf("rank decay / alpha mismatch: have %v/%v, want 9/10 and 72/100", p.RankDecay, p.AlphaFactor)
+	}
+	// Durations follow the block period
+	fork := params.DefaultPoCRFork
+	fork.AuditValidity = 30
+	for _, tt := range []struct {
+		period uint64
+		blocks int64
+	}{{2, 15}, {15, 2}, {60, 1}} {
+		p := NewRewardParams(&fork, tt.period)
+		if p.BlocksBetweenAudit.Int64() != tt.blocks {
+			t.Errorf("period %d: blocks between audit mismatch: have %v, want %v", tt.period, p.BlocksBetweenAudit, tt.blocks)
+		}
+		minCreation := new(big.Rat).Mul(big.NewRat(100000*int64(tt.period), secondsPerYear), new(big.Rat).SetInt(CTCUnit))
+		if p.MinCreationPerBlock.Cmp(minCreation) != 0 {
+			t.Errorf("period %d: minimum creation mismatch: have %v, want %v", tt.period, p.MinCreationPerBlock, minCreation)
+		}
+	}
+}
+
+// Tests that the governance values override the configuration within their
+// range only, and that unset values keep it.
+func TestGovernanceParams(t *testing.T) {
+	var values governanceValues
+	fork := values.apply(&params.DefaultPoCRFork)
+	if fork == &params.DefaultPoCRFork || fork.AuditPenalty != params.DefaultPoCRFork.AuditPenalty || fork.RankDecay != params.DefaultPoCRFork.RankDecay {
+		t.Errorf("unset values changed the configuration: %+v", fork)
+	}
+	set := func(name string, value int64) {
+		for i, variable := range governanceVariables {
+			if variable.name == name {
+				values[i] = common.BigToHash(big.NewInt(value))
+				return
+			}
+		}
+		t.Fatalf("unknown governance variable %s", name)
+	}
+	set("AuditPenalty", 20)
+	set("MinCreationPerYear", 500000)
+	set("RankDecay", 101)
+	set("AuditValidity", 60)
+
+	fork = values.apply(&params.DefaultPoCRFork)
+	if fork.AuditPenalty != 20 || fork.MinCreationPerYear.Int64() != 500000 {
+		t.Errorf("valid values not applied: penalty %d, min creation %v", fork.AuditPenalty, fork.MinCreationPerYear)
+	}
+	if fork.RankDecay != params.DefaultPoCRFork.RankDecay || fork.AuditValidity != params.DefaultPoCRFork.AuditValidity {
+		t.Errorf("out of range values applied: rank decay %d, audit validity %d", fork.RankDecay, fork.AuditValidity)
+	}
+	if params.DefaultPoCRFork.AuditPenalty != 5 || params.DefaultPoCRFork.MinCreationPerYear.Int64() != 100000 {
+		t.Errorf("configuration modified by the governance values")
+	}
+	// The governance values are part of the reward algorithm identity
+	engine := New(params.AllCliqueProtocolChanges.Clique, rawdb.NewMemoryDatabase())
+	if engine.computationAt(params.AllCliqueProtocolChanges, common.Big1, values) == engine.computationAt(params.AllCliqueProtocolChanges, common.Big1, governanceValues{}) {
+		t.Errorf("governance values ignored by the reward algorithm cache")
+	}
+}
+
 // func TestCalculateCarbonFootprintReward1(t *testing.T) {
 // 	var rewardComputation RaceRankComputation
 // 	cf := make([]*big.Int, 3)
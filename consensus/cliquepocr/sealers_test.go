@@ -0,0 +1,382 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package cliquepocr
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// sealerChain is a PoCR chain whose signers vote each other in and out.
+type sealerChain struct {
+	db     ethdb.Database
+	keys   map[common.Address]*ecdsa.PrivateKey
+	config *params.ChainConfig
+	engine *CliquePoCR
+	chain  *core.BlockChain
+	head   *types.Block
+}
+
+// newSealerKeys creates n signer keys, returning their addresses in ascending
+// order.
+func newSealerKeys(n int) ([]common.Address, map[common.Address]*ecdsa.PrivateKey) {
+	addrs := make([]common.Address, 0, n)
+	keys := make(map[common.Address]*ecdsa.PrivateKey, n)
+	for i := 0; i < n; i++ {
+		key, _ := crypto.ToECDSA(crypto.Keccak256([]byte{byte(i)}))
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		addrs, keys[addr] = append(addrs, addr), key
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+	return addrs, keys
+}
+
+// newSealerChain creates a PoCR chain with the given genesis signers, all the
+// keys having a footprint.
+func newSealerChain(t testing.TB, config *params.ChainConfig, keys map[common.Address]*ecdsa.PrivateKey, signers []common.Address) *sealerChain {
+	footprints := make(map[common.Address]int64, len(keys))
+	for addr := range keys {
+		footprints[addr] = 1000
+	}
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		engine  = New(config.Clique, db)
+		genesis = &core.Genesis{
+			Config:    config,
+			ExtraData: make([]byte, extraVanity+len(signers)*common.AddressLength+extraSeal),
+			Alloc: core.GenesisAlloc{
+				common.HexToAddress(proofOfCarbonReductionContractAddress): {
+					Balance: big.NewInt(0),
+					Code:    testFootprintCode,
+					Storage: testFootprintStorage(footprints, 0),
+				},
+				common.HexToAddress(sessionVariablesContractAddress): {
+					Balance: big.NewInt(0),
+					Code:    common.Hex2Bytes("608060"),
+				},
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+	)
+	for i, signer := range signers {
+		copy(genesis.ExtraData[extraVanity+i*common.AddressLength:], signer[:])
+	}
+	head := genesis.MustCommit(db)
+	chain, err := core.NewBlockChain(db, nil, config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	return &sealerChain{db: db, keys: keys, config: config, engine: engine, chain: chain, head: head}
+}
+
+// recentlySigned tells whether the signer is not allowed to seal the block
+// because of the clique spam protection.
+func recentlySigned(snap *clique.Snapshot, number uint64, signer common.Address) bool {
+	for seen, recent := range snap.Recents {
+		if recent == signer {
+			if limit := uint64(len(snap.Signers)/2 + 1); number < limit || seen > number-limit {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// extend seals n blocks on top of the head, by the in-turn signer if allowed,
+// each voting on the candidate if any, and imports them.
+func (sc *sealerChain) extend(t testing.TB, n int, candidate common.Address, authorize bool) []*types.Block {
+	var blocks []*types.Block
+	for i := 0; i < n; i++ {
+		snap, err := sc.engine.EngineInstance.Snapshot(sc.chain, sc.head.NumberU64(), sc.head.Hash(), nil)
+		if err != nil {
+			t.Fatalf("failed to retrieve snapshot: %v", err)
+		}
+		var (
+			number  = sc.head.NumberU64() + 1
+			signers = snap.GetSigners()
+			signer  common.Address
+			diff    = diffNoTurn
+		)
+		for j := range signers {
+			if s := signers[(number+uint64(j))%uint64(len(signers))]; !recentlySigned(snap, number, s) {
+				if signer = s; j == 0 {
+					diff = diffInTurn
+				}
+				break
+			}
+		}
+		sc.engine.Authorize(signer, nil)
+		generated, _ := core.GenerateChain(sc.config, sc.head, sc.engine, sc.db, 1, func(_ int, block *core.BlockGen) {
+			block.SetDifficulty(diff)
+			block.SetExtra(make([]byte, extraVanity+extraSeal))
+			// the chain maker keeps the coinbase of the parent, which would be a vote
+			block.SetCoinbase(candidate)
+			var nonce types.BlockNonce
+			if authorize {
+				copy(nonce[:], nonceAuthVote)
+			} else {
+				copy(nonce[:], nonceDropVote)
+			}
+			block.SetNonce(nonce)
+		})
+		header := generated[0].Header()
+		header.Extra = make([]byte, extraVanity+extraSeal)
+		sig, _ := crypto.Sign(sc.engine.SealHash(header).Bytes(), sc.keys[signer])
+		copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+		block := generated[0].WithSeal(header)
+
+		if _, err := sc.chain.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatalf("failed to insert block %d: %v", block.NumberU64(), err)
+		}
+		sc.head = block
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// sealers checks that the sealers of the PoCR contract in the state of the head
+// are the signers the head is sealed with, and returns their index. The slots
+// above the number of sealers must be clear in the sealer set layout.
+func (sc *sealerChain) sealers(t testing.TB, set bool) map[common.Address]int64 {
+	t.Helper()
+
+	head := sc.chain.CurrentHeader()
+	snap, err := sc.engine.EngineInstance.Snapshot(sc.chain, head.Number.Uint64()-1, head.ParentHash, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve snapshot: %v", err)
+	}
+	statedb, _ := sc.chain.State()
+	contract := NewCarbonFootPrintContract(common.Address{}, sc.config, statedb, head)
+
+	signers := snap.GetSigners()
+	if nbNodes := contract.getNbNodes(); nbNodes != uint64(len(signers)) {
+		t.Fatalf("block %d: number of sealers mismatch: have %d, want %d", head.Number, nbNodes, len(signers))
+	}
+	indexes := make(map[common.Address]int64)
+	for i := range signers {
+		s := contract.getSealerAt(int64(i))
+		if _, ok := snap.Signers[s]; !ok {
+			t.Errorf("block %d: sealer %d %x is not a signer", head.Number, i, s)
+		}
+		if _, ok := indexes[s]; ok {
+			t.Errorf("block %d: sealer %x duplicated", head.Number, s)
+		}
+		if !contract.getIsSealerOf(s) {
+			t.Errorf("block %d: sealer %x not enabled", head.Number, s)
+		}
+		indexes[s] = int64(i)
+	}
+	if s := contract.getSealerAt(int64(len(signers))); set && s != (common.Address{}) {
+		t.Errorf("block %d: stale sealer %x after the last one", head.Number, s)
+	}
+	return indexes
+}
+
+// storageRoot returns the storage root of the PoCR contract in the head state.
+func (sc *sealerChain) storageRoot() common.Hash {
+	statedb, _ := sc.chain.State()
+	return statedb.StorageTrie(common.HexToAddress(proofOfCarbonReductionContractAddress)).Hash()
+}
+
+// Tests that the sealers of the PoCR contract follow the signers added and
+// removed by votes, without writes when the signers do not change.
+func TestSealerReconciliation(t *testing.T) {
+	t.Run("list", func(t *testing.T) { testSealerReconciliation(t, false) })
+	t.Run("set", func(t *testing.T) { testSealerReconciliation(t, true) })
+}
+
+func testSealerReconciliation(t *testing.T, set bool) {
+	addrs, keys := newSealerKeys(4)
+	// The candidate sorts first, it shifts the sorted list of the signers
+	candidate, signers := addrs[0], addrs[1:]
+
+	config := *params.AllCliqueProtocolChanges
+	cliqueConfig := *config.Clique
+	if set {
+		cliqueConfig.SealerSetBlock = big.NewInt(0)
+	}
+	config.Clique = &cliqueConfig
+
+	sc := newSealerChain(t, &config, keys, signers)
+	defer sc.chain.Stop()
+
+	sc.extend(t, 2, common.Address{}, false)
+	initial := sc.sealers(t, set)
+	for i, signer := range signers {
+		if initial[signer] != int64(i) {
+			t.Errorf("initial sealer %x index mismatch: have %d, want %d", signer, initial[signer], i)
+		}
+	}
+	// Blocks without signer change leave the contract storage untouched
+	root := sc.storageRoot()
+	sc.extend(t, 3, common.Address{}, false)
+	if have := sc.storageRoot(); have != root {
+		t.Errorf("contract storage changed without signer change: have %x, want %x", have, root)
+	}
+	// Vote the candidate in: 2 votes out of 3 signers, then the reconciliation
+	sc.extend(t, 3, candidate, true)
+	sc.extend(t, 1, common.Address{}, false)
+	added := sc.sealers(t, set)
+	if len(added) != 4 {
+		t.Fatalf("candidate not added: %v", added)
+	}
+	for i, addr := range addrs {
+		want := int64(i)
+		if set {
+			// the kept sealers stay in place, the new one is appended
+			if want = initial[addr]; addr == candidate {
+				want = 3
+			}
+		}
+		if added[addr] != want {
+			t.Errorf("sealer %x index mismatch after addition: have %d, want %d", addr, added[addr], want)
+		}
+	}
+	// Vote the first sealer out: 3 votes out of 4 signers
+	removed := signers[0]
+	sc.extend(t, 4, removed, false)
+	sc.extend(t, 1, common.Address{}, false)
+	remaining := sc.sealers(t, set)
+	if _, ok := remaining[removed]; ok || len(remaining) != 3 {
+		t.Fatalf("sealer not removed: %v", remaining)
+	}
+	statedb, _ := sc.chain.State()
+	contract := NewCarbonFootPrintContract(common.Address{}, sc.config, statedb, sc.chain.CurrentHeader())
+	if contract.getIsSealerOf(removed) {
+		t.Errorf("removed sealer still enabled")
+	}
+	if set {
+		// the last sealer fills the hole, the others stay in place
+		for addr, index := range remaining {
+			want := added[addr]
+			if addr == candidate {
+				want = added[removed]
+			}
+			if index != want {
+				t.Errorf("sealer %x index mismatch after removal: have %d, want %d", addr, index, want)
+			}
+		}
+	}
+}
+
+// Tests that the sealers of the PoCR contract follow the signers of the branch
+// the chain reorganizes to, back and forth.
+func TestSealerReconciliationReorg(t *testing.T) {
+	addrs, keys := newSealerKeys(4)
+	candidate, signers := addrs[0], addrs[1:]
+
+	config := *params.AllCliqueProtocolChanges
+	cliqueConfig := *config.Clique
+	cliqueConfig.SealerSetBlock = big.NewInt(0)
+	config.Clique = &cliqueConfig
+
+	a := newSealerChain(t, &config, keys, signers)
+	defer a.chain.Stop()
+	b := newSealerChain(t, &config, keys, signers)
+	defer b.chain.Stop()
+
+	// The sealing is deterministic, both chains share their first blocks
+	if a.extend(t, 2, common.Address{}, false)[1].Hash() != b.extend(t, 2, common.Address{}, false)[1].Hash() {
+		t.Fatalf("shared blocks mismatch")
+	}
+
+	// The first branch adds the candidate, the second one removes a signer
+	a.extend(t, 4, candidate, true)
+	aHead := a.head
+	if sealers := a.sealers(t, true); len(sealers) != 4 {
+		t.Fatalf("candidate not added: %v", sealers)
+	}
+	removed := signers[1]
+	branch := b.extend(t, 10, removed, false)
+	if sealers := b.sealers(t, true); len(sealers) != 2 {
+		t.Fatalf("signer not removed: %v", sealers)
+	}
+	// Reorganize to the heavier second branch
+	if _, err := a.chain.InsertChain(branch); err != nil {
+		t.Fatalf("failed to insert second branch: %v", err)
+	}
+	if head := a.chain.CurrentHeader().Hash(); head != b.head.Hash() {
+		t.Fatalf("chain not reorganized: have %x, want %x", head, b.head.Hash())
+	}
+	sealers := a.sealers(t, true)
+	if _, ok := sealers[candidate]; ok {
+		t.Errorf("candidate of the dropped branch is a sealer")
+	}
+	if _, ok := sealers[removed]; ok {
+		t.Errorf("removed signer is still a sealer")
+	}
+	// Reorganize back to the first branch, made heavier
+	a.head = aHead
+	a.extend(t, 18, common.Address{}, false)
+	if head := a.chain.CurrentHeader().Hash(); head != a.head.Hash() {
+		t.Fatalf("chain not reorganized back: have %x, want %x", head, a.head.Hash())
+	}
+	sealers = a.sealers(t, true)
+	for _, addr := range addrs {
+		if _, ok := sealers[addr]; !ok {
+			t.Errorf("sealer %x missing after reorganizing back", addr)
+		}
+	}
+}
+
+// Tests that the sealers are reconciled on every block before the sealer set
+// fork, and only on signer changes from the fork.
+func TestSealerReconciliationFork(t *testing.T) {
+	t.Run("list", func(t *testing.T) { testSealerReconciliationFork(t, false) })
+	t.Run("set", func(t *testing.T) { testSealerReconciliationFork(t, true) })
+}
+
+func testSealerReconciliationFork(t *testing.T, set bool) {
+	addrs, keys := newSealerKeys(3)
+
+	config := *params.AllCliqueProtocolChanges
+	cliqueConfig := *config.Clique
+	if set {
+		cliqueConfig.SealerSetBlock = big.NewInt(0)
+	}
+	config.Clique = &cliqueConfig
+
+	sc := newSealerChain(t, &config, keys, addrs)
+	defer sc.chain.Stop()
+	sc.extend(t, 3, common.Address{}, false)
+
+	// Disable a sealer in the contract without any signer change
+	statedb, _ := sc.chain.State()
+	header := &types.Header{Number: new(big.Int).Add(sc.head.Number(), common.Big1), ParentHash: sc.head.Hash()}
+	contract := NewCarbonFootPrintContractForUpdate(common.Address{}, sc.config, statedb, header)
+	contract.setIsSealerOf(addrs[0], false)
+
+	if err := synchronizeSealers(sc.engine, sc.chain, common.Address{}, statedb, header); err != nil {
+		t.Fatalf("failed to synchronize the sealers: %v", err)
+	}
+	if enabled := contract.getIsSealerOf(addrs[0]); enabled == set {
+		t.Errorf("sealer enabled mismatch: have %v, want %v", enabled, !set)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package cliquepocr
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// SimSealer is a sealer of a simulated block along with its registered footprint.
+type SimSealer struct {
+	Address        common.Address
+	Footprint      *big.Int // Audited footprint, zero if never audited
+	FootprintBlock *big.Int // Block of the last audit
+}
+
+// SimBlock is the outcome of the reward processing of a simulated block.
+type SimBlock struct {
+	Number          uint64
+	Author          common.Address
+	Rank            *big.Rat // Rank of the author
+	NbNodes         int      // Number of ranked sealers
+	InflationFactor *big.Rat // Global inflation control factor before the block
+	BlockReward     *big.Int // Amount minted for the author
+	FeeAdjustment   *big.Int // Fees removed from (negative) the author because of its rank
+	Burnt           *big.Int // Fees burnt by the EIP-1559
+	TotalCrypto     *big.Int // GeneratedPocRTotal once the block is applied
+}
+
+// Simulator replays the PoCR reward rules of a chain configuration over
+// synthetic blocks, without any chain or state: the sealers, their footprints
+// and the fees are given for every block. It is meant for supply projections.
+// The governance session variables are unset, the parameters are the ones of the
+// chain configuration.
+type Simulator struct {
+	config      *params.ChainConfig
+	engine      *CliquePoCR
+	totalCrypto *big.Int // Running value of GeneratedPocRTotal
+}
+
+// NewSimulator creates a simulator of the given clique chain configuration,
+// starting from the given amount of generated crypto.
+func NewSimulator(config *params.ChainConfig, totalCrypto *big.Int) (*Simulator, error) {
+	if config.Clique == nil {
+		return nil, errors.New("chain configuration is not a clique one")
+	}
+	if err := config.CheckConfigForkOrder(); err != nil {
+		return nil, err
+	}
+	return &Simulator{
+		config:      config,
+		engine:      New(config.Clique, rawdb.NewMemoryDatabase()),
+		totalCrypto: new(big.Int).Set(totalCrypto),
+	}, nil
+}
+
+// TotalCrypto returns the current value of GeneratedPocRTotal.
+func (s *Simulator) TotalCrypto() *big.Int {
+	return new(big.Int).Set(s.totalCrypto)
+}
+
+// Block processes the rewards of a block sealed by author, given the sealers
+// of the block and the fees it pays to its sealer and burns. The accounting is
+// the one of the block processing, using the same reward algorithms.
+func (s *Simulator) Block(number uint64, author common.Address, sealers []SimSealer, received, burnt *big.Int) (*SimBlock, error) {
+	return s.Blocks(number, 1, author, sealers, received, burnt)
+}
+
+// Blocks processes the rewards of count identical blocks sealed by author from
+// the given number on, as if they all saw the state of the first one. It trades
+// accuracy for speed on long projections, count 1 being the exact processing.
+// The fees are given per block, the result is the total over the blocks.
+func (s *Simulator) Blocks(number uint64, count uint64, author common.Address, sealers []SimSealer, received, burnt *big.Int) (*SimBlock, error) {
+	var (
+		num          = new(big.Int).SetUint64(number)
+		blocks       = new(big.Int).SetUint64(count)
+		computation  = s.engine.computationAt(s.config, num, governanceValues{})
+		rewardParams = rewardParamsAt(s.config, num, governanceValues{})
+	)
+	inflation, err := computation.CalculateGlobalInflationControlFactor(s.totalCrypto)
+	if err != nil {
+		return nil, err
+	}
+	// Penalize the footprints the way collectFootprints does
+	var footprint *big.Int
+	allNodesFootprint := make([]*big.Int, 0, len(sealers))
+	for _, sealer := range sealers {
+		penalized := calcCarbonFootprintAuditIncentive(sealer.Footprint, sealer.FootprintBlock, num, rewardParams)
+		allNodesFootprint = append(allNodesFootprint, penalized)
+		if sealer.Address == author {
+			footprint = penalized
+		}
+	}
+	result := &SimBlock{
+		Number:          number,
+		Author:          author,
+		Rank:            new(big.Rat),
+		InflationFactor: inflation,
+		BlockReward:     new(big.Int),
+		Burnt:           new(big.Int).Mul(burnt, blocks),
+	}
+	// A sealer without footprint is not rewarded, and its zero rank takes all the fees away
+	if footprint != nil && footprint.Sign() > 0 {
+		rank, nbNodes, err := computation.CalculateRanking(footprint, allNodesFootprint)
+		if err != nil {
+			return nil, err
+		}
+		reward, err := computation.CalculateCarbonFootprintReward(rank, nbNodes, s.totalCrypto)
+		if err != nil {
+			return nil, err
+		}
+		result.Rank, result.NbNodes, result.BlockReward = rank, nbNodes, reward.Mul(reward, blocks)
+	}
+	result.FeeAdjustment = calcCarbonFootprintFeeAdjustment(result.Rank, received)
+	result.FeeAdjustment.Mul(result.FeeAdjustment, blocks)
+
+	s.totalCrypto.Add(s.totalCrypto, result.BlockReward)
+	s.totalCrypto.Add(s.totalCrypto, result.FeeAdjustment)
+	s.totalCrypto.Sub(s.totalCrypto, result.Burnt)
+	result.TotalCrypto = new(big.Int).Set(s.totalCrypto)
+
+	return result, nil
+}
@@ -0,0 +1,277 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package cliquepocr
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// maxSupplyRange is the maximum number of blocks the supply changes are summed
+// over by pocr_getSupplyDelta.
+const maxSupplyRange = 100000
+
+// errSupplyNotRecorded is returned when the supply change of a block is requested
+// but the node did not process the block (e.g. below the pivot of a snap sync).
+var errSupplyNotRecorded = errors.New("supply change not recorded, the block was not processed by the node")
+
+// supplyDelta is the change of the PoCR supply over a block or a range of blocks.
+// The supply grows by the minted amounts and shrinks by the burnt and confiscated
+// fees, and GeneratedPocRTotal follows its net change.
+type supplyDelta struct {
+	Minted      *big.Int // Block rewards, and fees added to the sealers by their rank
+	Burnt       *big.Int // Fees burnt by the EIP-1559
+	Confiscated *big.Int // Fees removed from the sealers by their rank
+}
+
+// newSupplyDelta returns an empty supply change.
+func newSupplyDelta() *supplyDelta {
+	return &supplyDelta{Minted: new(big.Int), Burnt: new(big.Int), Confiscated: new(big.Int)}
+}
+
+// rewardSupplyDelta returns the supply change of a block from the record of its
+// rewards.
+func rewardSupplyDelta(reward *types.PoCRReward) *supplyDelta {
+	delta := newSupplyDelta()
+	delta.Minted.Set(reward.BlockReward)
+	if reward.FeeAdjustment.Sign() > 0 {
+		delta.Minted.Add(delta.Minted, reward.FeeAdjustment)
+	} else {
+		delta.Confiscated.Neg(reward.FeeAdjustment)
+	}
+	delta.Burnt.Set(reward.Burnt)
+	return delta
+}
+
+// add accumulates the given supply change.
+func (d *supplyDelta) add(other *supplyDelta) {
+	d.Minted.Add(d.Minted, other.Minted)
+	d.Burnt.Add(d.Burnt, other.Burnt)
+	d.Confiscated.Add(d.Confiscated, other.Confiscated)
+}
+
+// net returns the net change of the supply.
+func (d *supplyDelta) net() *big.Int {
+	net := new(big.Int).Sub(d.Minted, d.Burnt)
+	return net.Sub(net, d.Confiscated)
+}
+
+// Supply is the PoCR supply once a block is applied, along with its change over
+// the block.
+type Supply struct {
+	Number      hexutil.Uint64 `json:"number"`
+	Hash        common.Hash    `json:"hash"`
+	Generated   *hexutil.Big   `json:"generated"`   // GeneratedPocRTotal once the block is applied
+	Minted      *hexutil.Big   `json:"minted"`      // Minted by the block
+	Burnt       *hexutil.Big   `json:"burnt"`       // Burnt by the EIP-1559 in the block
+	Confiscated *hexutil.Big   `json:"confiscated"` // Fees removed from the sealer by its rank
+}
+
+// SupplyRange is the change of the PoCR supply over a range of blocks.
+type SupplyRange struct {
+	From            hexutil.Uint64 `json:"from"`
+	To              hexutil.Uint64 `json:"to"`
+	GeneratedBefore *hexutil.Big   `json:"generatedBefore"` // GeneratedPocRTotal before the first block
+	GeneratedAfter  *hexutil.Big   `json:"generatedAfter"`  // GeneratedPocRTotal once the last block is applied
+	Minted          *hexutil.Big   `json:"minted"`
+	Burnt           *hexutil.Big   `json:"burnt"`
+	Confiscated     *hexutil.Big   `json:"confiscated"`
+	Net             *hexutil.Big   `json:"net"` // Net change of the supply
+}
+
+// SupplyCheck is the comparison of the balances of the accounts with the PoCR
+// supply generated since the genesis.
+type SupplyCheck struct {
+	Number          hexutil.Uint64 `json:"number"`
+	Hash            common.Hash    `json:"hash"`
+	Balances        *hexutil.Big   `json:"balances"`        // Sum of the balances of the accounts
+	GenesisBalances *hexutil.Big   `json:"genesisBalances"` // Sum of the balances allocated by the genesis
+	Generated       *hexutil.Big   `json:"generated"`       // GeneratedPocRTotal, net of the burnt and confiscated fees
+	Consistent      bool           `json:"consistent"`      // Whether the balances grew by the generated supply
+}
+
+// GetSupply retrieves the PoCR supply once the given block is applied, and its
+// change over the block.
+func (api *API) GetSupply(number *rpc.BlockNumber) (*Supply, error) {
+	header, err := api.header(number)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		num       = header.Number.Uint64()
+		generated *big.Int
+		delta     *supplyDelta
+	)
+	if num == 0 {
+		// The genesis has no supply change, but may allocate an initial total
+		chain, ok := api.chain.(stateChainReader)
+		if !ok {
+			return nil, errNoStateAccess
+		}
+		statedb, err := chain.StateAt(header.Root)
+		if err != nil {
+			return nil, err
+		}
+		generated, delta = getTotalCryptoBalance(statedb), newSupplyDelta()
+	} else {
+		reward := rawdb.ReadPoCRReward(api.pocr.db, header.Hash(), num)
+		if reward == nil {
+			return nil, errSupplyNotRecorded
+		}
+		generated, delta = reward.TotalCryptoAfter, rewardSupplyDelta(reward)
+	}
+	return &Supply{
+		Number:      hexutil.Uint64(num),
+		Hash:        header.Hash(),
+		Generated:   (*hexutil.Big)(generated),
+		Minted:      (*hexutil.Big)(delta.Minted),
+		Burnt:       (*hexutil.Big)(delta.Burnt),
+		Confiscated: (*hexutil.Big)(delta.Confiscated),
+	}, nil
+}
+
+// GetSupplyDelta sums the changes of the PoCR supply over the canonical blocks
+// from and to, both included.
+func (api *API) GetSupplyDelta(from, to rpc.BlockNumber) (*SupplyRange, error) {
+	first, err := api.header(&from)
+	if err != nil {
+		return nil, err
+	}
+	last, err := api.header(&to)
+	if err != nil {
+		return nil, err
+	}
+	start, end := first.Number.Uint64(), last.Number.Uint64()
+	if start == 0 {
+		start = 1 // the genesis has no supply change
+	}
+	if end < start {
+		return nil, fmt.Errorf("invalid block range %d-%d", start, end)
+	}
+	if end-start >= maxSupplyRange {
+		return nil, fmt.Errorf("block range %d-%d exceeds %d blocks", start, end, maxSupplyRange)
+	}
+	var (
+		total         = newSupplyDelta()
+		before, after *big.Int
+	)
+	for number := start; number <= end; number++ {
+		header := api.chain.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, errUnknownBlock
+		}
+		reward := rawdb.ReadPoCRReward(api.pocr.db, header.Hash(), number)
+		if reward == nil {
+			return nil, fmt.Errorf("block %d: %w", number, errSupplyNotRecorded)
+		}
+		if before == nil {
+			before = reward.TotalCryptoBefore
+		}
+		after = reward.TotalCryptoAfter
+		total.add(rewardSupplyDelta(reward))
+	}
+	return &SupplyRange{
+		From:            hexutil.Uint64(start),
+		To:              hexutil.Uint64(end),
+		GeneratedBefore: (*hexutil.Big)(before),
+		GeneratedAfter:  (*hexutil.Big)(after),
+		Minted:          (*hexutil.Big)(total.Minted),
+		Burnt:           (*hexutil.Big)(total.Burnt),
+		Confiscated:     (*hexutil.Big)(total.Confiscated),
+		Net:             (*hexutil.Big)(total.net()),
+	}, nil
+}
+
+// DebugAPI is the collection of the proof-of-carbon-reduction debugging RPC
+// methods, served under the debug namespace as they are too expensive for the
+// public API.
+type DebugAPI struct {
+	api *API
+}
+
+// CheckPoCRSupply checks that the balances of the accounts grew since the
+// genesis by the supply generated, that is that the balances plus the burnt and
+// the confiscated fees equal the genesis allocation plus the minted amounts. It
+// walks the whole state of the block and of the genesis, so it is meant for the
+// development and test chains.
+func (debug *DebugAPI) CheckPoCRSupply(number *rpc.BlockNumber) (*SupplyCheck, error) {
+	api := debug.api
+	chain, ok := api.chain.(stateChainReader)
+	if !ok {
+		return nil, errNoStateAccess
+	}
+	header, err := api.header(number)
+	if err != nil {
+		return nil, err
+	}
+	genesis := chain.GetHeaderByNumber(0)
+	statedb, err := chain.StateAt(header.Root)
+	if err != nil {
+		return nil, err
+	}
+	genesisState, err := chain.StateAt(genesis.Root)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := sumBalances(statedb.Database(), header.Root)
+	if err != nil {
+		return nil, err
+	}
+	genesisBalances, err := sumBalances(genesisState.Database(), genesis.Root)
+	if err != nil {
+		return nil, err
+	}
+	generated := new(big.Int).Sub(getTotalCryptoBalance(statedb), getTotalCryptoBalance(genesisState))
+	growth := new(big.Int).Sub(balances, genesisBalances)
+
+	return &SupplyCheck{
+		Number:          hexutil.Uint64(header.Number.Uint64()),
+		Hash:            header.Hash(),
+		Balances:        (*hexutil.Big)(balances),
+		GenesisBalances: (*hexutil.Big)(genesisBalances),
+		Generated:       (*hexutil.Big)(generated),
+		Consistent:      growth.Cmp(generated) == 0,
+	}, nil
+}
+
+// sumBalances sums the balances of all the accounts of a state.
+func sumBalances(db state.Database, root common.Hash) (*big.Int, error) {
+	tr, err := db.OpenTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	total := new(big.Int)
+	it := trie.NewIterator(tr.NodeIterator(nil))
+	for it.Next() {
+		var account types.StateAccount
+		if err := rlp.DecodeBytes(it.Value, &account); err != nil {
+			return nil, err
+		}
+		total.Add(total, account.Balance)
+	}
+	return total, it.Err
+}
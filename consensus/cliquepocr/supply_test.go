@@ -0,0 +1,179 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package cliquepocr
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// newSupplyTestChain creates a chain whose sealer has the given footprint, with
+// blocks holding transactions paying a tip to the sealer.
+func newSupplyTestChain(t *testing.T, footprint int64, n int) (*testChain, []*types.Block) {
+	var (
+		key, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		sender = crypto.PubkeyToAddress(key.PublicKey)
+		signer = types.LatestSigner(params.AllCliqueProtocolChanges)
+	)
+	// The generated total is seeded, as the burnt and confiscated fees would
+	// otherwise take it below zero on a chain without rewards
+	tc := newTestChain(t, footprint, core.GenesisAlloc{
+		sender: {Balance: big.NewInt(params.Ether)},
+		common.HexToAddress(sessionVariablesContractAddress): {
+			Balance: big.NewInt(0),
+			Code:    common.Hex2Bytes("608060"),
+			Storage: map[common.Hash]common.Hash{
+				crypto.Keccak256Hash([]byte(sessionVariableTotalPocRCoins)): common.BigToHash(big.NewInt(params.Ether)),
+			},
+		},
+	})
+	blocks := tc.extend(t, n, func(i int, block *core.BlockGen) {
+		// The tips are paid to the coinbase of the generated block, while the
+		// imported block pays them to its signer
+		block.SetCoinbase(tc.addr)
+
+		gasPrice := new(big.Int).Mul(block.BaseFee(), big.NewInt(2))
+		tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(sender), common.Address{0x01}, big.NewInt(1), params.TxGas, gasPrice, nil), signer, key)
+		block.AddTx(tx)
+	})
+	return tc, blocks
+}
+
+// Tests that the supply changes of the blocks follow the generated total, and
+// that their sums over a range match its progression.
+func TestAPISupply(t *testing.T) {
+	tests := []struct {
+		name        string
+		footprint   int64
+		minted      bool // whether the sealer is rewarded
+		confiscated bool // whether the fees of the sealer are confiscated
+	}{
+		{"rewarded", 1000, true, false},
+		{"unaudited", 0, false, true},
+	}
+	for _, tt := range tests {
+		tc, blocks := newSupplyTestChain(t, tt.footprint, 3)
+		defer tc.chain.Stop()
+		api := tc.api()
+
+		genesis := rpc.BlockNumber(0)
+		previous, err := api.GetSupply(&genesis)
+		if err != nil {
+			t.Fatalf("%s: failed to retrieve the genesis supply: %v", tt.name, err)
+		}
+		if previous.Minted.ToInt().Sign() != 0 || previous.Burnt.ToInt().Sign() != 0 || previous.Confiscated.ToInt().Sign() != 0 {
+			t.Errorf("%s: genesis supply change: %+v", tt.name, previous)
+		}
+		for _, block := range blocks {
+			number := rpc.BlockNumber(block.NumberU64())
+			supply, err := api.GetSupply(&number)
+			if err != nil {
+				t.Fatalf("%s: block %d: failed to retrieve supply: %v", tt.name, number, err)
+			}
+			if supply.Hash != block.Hash() {
+				t.Errorf("%s: block %d: hash mismatch: have %x, want %x", tt.name, number, supply.Hash, block.Hash())
+			}
+			if (supply.Minted.ToInt().Sign() > 0) != tt.minted {
+				t.Errorf("%s: block %d: minted %v", tt.name, number, supply.Minted)
+			}
+			if (supply.Confiscated.ToInt().Sign() > 0) != tt.confiscated {
+				t.Errorf("%s: block %d: confiscated %v", tt.name, number, supply.Confiscated)
+			}
+			if supply.Burnt.ToInt().Sign() <= 0 {
+				t.Errorf("%s: block %d: nothing burnt", tt.name, number)
+			}
+			want := new(big.Int).Add(previous.Generated.ToInt(), supply.Minted.ToInt())
+			want.Sub(want, supply.Burnt.ToInt())
+			want.Sub(want, supply.Confiscated.ToInt())
+			if supply.Generated.ToInt().Cmp(want) != 0 {
+				t.Errorf("%s: block %d: generated mismatch: have %v, want %v", tt.name, number, supply.Generated, want)
+			}
+			previous = supply
+		}
+		delta, err := api.GetSupplyDelta(0, rpc.LatestBlockNumber)
+		if err != nil {
+			t.Fatalf("%s: failed to retrieve supply delta: %v", tt.name, err)
+		}
+		if delta.From != 1 || delta.To != 3 {
+			t.Errorf("%s: range mismatch: have %d-%d, want 1-3", tt.name, delta.From, delta.To)
+		}
+		if net := new(big.Int).Sub(delta.GeneratedAfter.ToInt(), delta.GeneratedBefore.ToInt()); net.Cmp(delta.Net.ToInt()) != 0 {
+			t.Errorf("%s: net change mismatch: have %v, want %v", tt.name, delta.Net, net)
+		}
+		if delta.GeneratedAfter.ToInt().Cmp(previous.Generated.ToInt()) != 0 {
+			t.Errorf("%s: generated mismatch: have %v, want %v", tt.name, delta.GeneratedAfter, previous.Generated)
+		}
+		// The balances grew by the generated supply, which only the debug
+		// namespace checks as it walks the whole state
+		server := rpc.NewServer()
+		for _, api := range tc.engine.APIs(tc.chain) {
+			if err := server.RegisterName(api.Namespace, api.Service); err != nil {
+				t.Fatalf("%s: failed to register the %s API: %v", tt.name, api.Namespace, err)
+			}
+		}
+		client := rpc.DialInProc(server)
+		var check struct {
+			Number     hexutil.Uint64
+			Consistent bool
+		}
+		if err := client.Call(&check, "pocr_checkSupply", "latest"); err == nil {
+			t.Errorf("%s: supply check served by the public API", tt.name)
+		}
+		if err := client.Call(&check, "debug_checkPoCRSupply", "latest"); err != nil {
+			t.Fatalf("%s: failed to check supply: %v", tt.name, err)
+		}
+		if !check.Consistent || uint64(check.Number) != 3 {
+			t.Errorf("%s: inconsistent supply: %+v", tt.name, check)
+		}
+		client.Close()
+		server.Stop()
+	}
+}
+
+// Tests that the supply of the blocks the node did not process is not reported,
+// and that the invalid ranges are rejected.
+func TestAPISupplyErrors(t *testing.T) {
+	tc, blocks := newSupplyTestChain(t, 1000, 3)
+	defer tc.chain.Stop()
+	api := tc.api()
+
+	if _, err := api.GetSupplyDelta(3, 2); err == nil {
+		t.Errorf("reversed range accepted")
+	}
+	if _, err := api.GetSupplyDelta(1, 10); err != errUnknownBlock {
+		t.Errorf("error mismatch: have %v, want %v", err, errUnknownBlock)
+	}
+	rawdb.DeletePoCRReward(tc.db, blocks[1].Hash(), 2)
+
+	number := rpc.BlockNumber(2)
+	if _, err := api.GetSupply(&number); err != errSupplyNotRecorded {
+		t.Errorf("error mismatch: have %v, want %v", err, errSupplyNotRecorded)
+	}
+	if _, err := api.GetSupplyDelta(1, 3); !errors.Is(err, errSupplyNotRecorded) {
+		t.Errorf("error mismatch: have %v, want %v", err, errSupplyNotRecorded)
+	}
+}
@@ -92,9 +92,11 @@ type Engine interface {
 	// but does not assemble the block.
 	//
 	// Note: The block header and state database might be updated to reflect any
-	// consensus rules that happen at finalization (e.g. block rewards).
+	// consensus rules that happen at finalization (e.g. block rewards). An error
+	// means the post-transaction modifications could not be applied and the block
+	// must be rejected. The receipts are the ones of the executed transactions.
 	Finalize(chain ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction,
-		uncles []*types.Header)
+		uncles []*types.Header, receipts []*types.Receipt) error
 
 	// FinalizeAndAssemble runs any post-transaction state modifications (e.g. block
 	// rewards) and assembles the final block.
@@ -132,3 +134,13 @@ type PoW interface {
 	// Hashrate returns the current mining hashrate of a PoW consensus engine.
 	Hashrate() float64
 }
+
+// RewardRecorder is a consensus engine keeping a record of the rewards it grants
+// to the blocks it finalizes, for the chain to store once the block is committed.
+type RewardRecorder interface {
+	Engine
+
+	// RewardRecord returns the reward record of a finalized block, or nil if the
+	// engine did not finalize the block or does not remember it anymore.
+	RewardRecord(hash common.Hash) *types.PoCRReward
+}
@@ -657,11 +657,12 @@ func (bc *BlockChain) setHeadBeyondRoot(head uint64, root common.Hash, repair bo
 			// Remove the hash <-> number mapping from the active store.
 			rawdb.DeleteHeaderNumber(db, hash)
 		} else {
-			// Remove relative body and receipts from the active store.
-			// The header, total difficulty and canonical hash will be
+			// Remove relative body, receipts and PoCR reward from the active
+			// store. The header, total difficulty and canonical hash will be
 			// removed in the hc.SetHead function.
 			rawdb.DeleteBody(db, hash, num)
 			rawdb.DeleteReceipts(db, hash, num)
+			rawdb.DeletePoCRReward(db, hash, num)
 		}
 		// Todo(rjl493456442) txlookup, bloombits, etc
 	}
@@ -1265,6 +1266,9 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	rawdb.WriteTd(blockBatch, block.Hash(), block.NumberU64(), externTd)
 	rawdb.WriteBlock(blockBatch, block)
 	rawdb.WriteReceipts(blockBatch, block.Hash(), block.NumberU64(), receipts)
+	if reward := bc.rewardRecord(block.Hash()); reward != nil {
+		rawdb.WritePoCRReward(blockBatch, block.Hash(), block.NumberU64(), reward)
+	}
 	rawdb.WritePreimages(blockBatch, state.Preimages())
 	if err := blockBatch.Write(); err != nil {
 		log.Crit("Failed to write block into disk", "err", err)
@@ -1369,6 +1373,7 @@ func (bc *BlockChain) writeBlockAndSetHead(block *types.Block, receipts []*types
 	bc.futureBlocks.Remove(block.Hash())
 
 	if status == CanonStatTy {
+		logs = append(logs, rawdb.ReadPoCRLogs(bc.db, block.Hash(), block.NumberU64(), uint(len(block.Transactions())), uint(len(logs)))...)
 		bc.chainFeed.Send(ChainEvent{Block: block, Hash: block.Hash(), Logs: logs})
 		if len(logs) > 0 {
 			bc.logsFeed.Send(logs)
@@ -1956,6 +1961,41 @@ func (bc *BlockChain) recoverAncestors(block *types.Block) (common.Hash, error)
 	return block.Hash(), nil
 }
 
+// rewardRecord returns the reward record the consensus engine keeps for a
+// processed block, if any.
+func (bc *BlockChain) rewardRecord(hash common.Hash) *types.PoCRReward {
+	if recorder, ok := bc.engine.(consensus.RewardRecorder); ok {
+		return recorder.RewardRecord(hash)
+	}
+	return nil
+}
+
+// regenerateRewardRecord stores the reward record of a block joining the
+// canonical chain if it is missing, replaying the block on the state of its
+// parent. The record cannot be regenerated if that state is not available.
+func (bc *BlockChain) regenerateRewardRecord(db ethdb.KeyValueWriter, block *types.Block) {
+	recorder, ok := bc.engine.(consensus.RewardRecorder)
+	if !ok || block.NumberU64() == 0 || rawdb.HasPoCRReward(bc.db, block.Hash(), block.NumberU64()) {
+		return
+	}
+	parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return
+	}
+	statedb, err := bc.StateAt(parent.Root)
+	if err != nil {
+		log.Debug("Reward record not regenerated, missing state", "number", block.Number(), "hash", block.Hash())
+		return
+	}
+	if _, _, _, err := bc.processor.Process(block, statedb, vm.Config{}, bc.engine); err != nil {
+		log.Warn("Failed to regenerate reward record", "number", block.Number(), "hash", block.Hash(), "err", err)
+		return
+	}
+	if reward := recorder.RewardRecord(block.Hash()); reward != nil {
+		rawdb.WritePoCRReward(db, block.Hash(), block.NumberU64(), reward)
+	}
+}
+
 // collectLogs collects the logs that were generated or removed during
 // the processing of the block that corresponds with the given hash.
 // These logs are later announced as deleted or reborn.
@@ -1976,6 +2016,11 @@ func (bc *BlockChain) collectLogs(hash common.Hash, removed bool) []*types.Log {
 			logs = append(logs, &l)
 		}
 	}
+	// The PoCR system logs are derived, they need no copy
+	for _, log := range rawdb.ReadPoCRLogs(bc.db, hash, *number, uint(len(receipts)), uint(len(logs))) {
+		log.Removed = removed
+		logs = append(logs, log)
+	}
 	return logs
 }
 
@@ -2111,6 +2156,12 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 	for _, tx := range types.HashDifference(deletedTxs, addedTxs) {
 		rawdb.DeleteTxLookupEntry(indexesBatch, tx)
 	}
+	// The PoCR reward records of the blocks leaving the canonical chain are kept,
+	// as their receipts, in case they are re-adopted. Regenerate the ones missing
+	// from the blocks joining it.
+	for i := len(newChain) - 1; i >= 0; i-- {
+		bc.regenerateRewardRecord(indexesBatch, newChain[i])
+	}
 
 	// Delete all hash markers that are not part of the new canonical chain.
 	// Because the reorg function does not handle new chain head, all hash
@@ -233,6 +233,12 @@ func gatherForks(config *params.ChainConfig) []uint64 {
 			forks = append(forks, rule.Uint64())
 		}
 	}
+	// System contract upgrades change the state transition, they are forks too
+	for _, upgrade := range config.SystemContractUpgrades {
+		if upgrade.Block != nil {
+			forks = append(forks, upgrade.Block.Uint64())
+		}
+	}
 	// Sort the fork block numbers to permit chronological XOR
 	for i := 0; i < len(forks); i++ {
 		for j := i + 1; j < len(forks); j++ {
@@ -274,6 +274,25 @@ func TestValidation(t *testing.T) {
 	}
 }
 
+// Tests that the system contract upgrades are part of the fork ID.
+func TestSystemContractUpgradeForks(t *testing.T) {
+	config := *params.RinkebyChainConfig
+	config.SystemContractUpgrades = []params.SystemContractUpgrade{
+		{Name: "upgrade", Block: big.NewInt(20_000_000)},
+	}
+	forks := gatherForks(&config)
+	if forks[len(forks)-1] != 20_000_000 {
+		t.Fatalf("upgrade block not gathered: have %v", forks)
+	}
+	before := NewID(params.RinkebyChainConfig, params.RinkebyGenesisHash, 19_999_999)
+	if have := NewID(&config, params.RinkebyGenesisHash, 19_999_999); have.Hash != before.Hash || have.Next != 20_000_000 {
+		t.Errorf("fork ID before the upgrade mismatch: have %x/%d, want %x/20000000", have.Hash, have.Next, before.Hash)
+	}
+	if have := NewID(&config, params.RinkebyGenesisHash, 20_000_000); have.Hash == before.Hash || have.Next != 0 {
+		t.Errorf("fork ID after the upgrade not changed: have %x/%d", have.Hash, have.Next)
+	}
+}
+
 // Tests that IDs are properly RLP encoded (specifically important because we
 // use uint32 to store the hash, but we need to encode it as [4]byte).
 func TestEncoding(t *testing.T) {
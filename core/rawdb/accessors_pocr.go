@@ -0,0 +1,106 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ReadPoCRRewardRLP retrieves the PoCR reward record of a block in RLP encoding.
+func ReadPoCRRewardRLP(db ethdb.Reader, hash common.Hash, number uint64) rlp.RawValue {
+	var data []byte
+	db.ReadAncients(func(reader ethdb.AncientReaderOp) error {
+		// Check if the data is in ancients
+		if isCanon(reader, number, hash) {
+			data, _ = reader.Ancient(chainFreezerPoCRTable, number)
+			return nil
+		}
+		// If not, try reading from leveldb
+		data, _ = db.Get(pocrRewardKey(number, hash))
+		return nil
+	})
+	return data
+}
+
+// HasPoCRReward verifies the existence of the PoCR reward record of a block.
+func HasPoCRReward(db ethdb.Reader, hash common.Hash, number uint64) bool {
+	return len(ReadPoCRRewardRLP(db, hash, number)) > 0
+}
+
+// ReadPoCRReward retrieves the PoCR reward record of a block, or nil if the
+// block was never processed by the PoCR engine (e.g. it was snap synced).
+func ReadPoCRReward(db ethdb.Reader, hash common.Hash, number uint64) *types.PoCRReward {
+	data := ReadPoCRRewardRLP(db, hash, number)
+	if len(data) == 0 {
+		return nil
+	}
+	reward := new(types.PoCRReward)
+	if err := rlp.DecodeBytes(data, reward); err != nil {
+		log.Error("Invalid PoCR reward RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return reward
+}
+
+// ReadPoCRLogs retrieves the system logs of the PoCR reward of a block, placed
+// after its txCount transactions and logCount logs, or nil if the block has no
+// reward record.
+func ReadPoCRLogs(db ethdb.Reader, hash common.Hash, number uint64, txCount, logCount uint) []*types.Log {
+	reward := ReadPoCRReward(db, hash, number)
+	if reward == nil {
+		return nil
+	}
+	return reward.Logs(hash, number, txCount, logCount)
+}
+
+// AppendPoCRLogs appends the system logs of the PoCR reward of a block to its
+// transaction logs, as the logs of an extra transaction.
+func AppendPoCRLogs(db ethdb.Reader, hash common.Hash, number uint64, logs [][]*types.Log) [][]*types.Log {
+	if logs == nil {
+		return nil
+	}
+	var count uint
+	for _, txLogs := range logs {
+		count += uint(len(txLogs))
+	}
+	if pocrLogs := ReadPoCRLogs(db, hash, number, uint(len(logs)), count); len(pocrLogs) > 0 {
+		logs = append(logs, pocrLogs)
+	}
+	return logs
+}
+
+// WritePoCRReward stores the PoCR reward record of a block.
+func WritePoCRReward(db ethdb.KeyValueWriter, hash common.Hash, number uint64, reward *types.PoCRReward) {
+	data, err := rlp.EncodeToBytes(reward)
+	if err != nil {
+		log.Crit("Failed to RLP encode PoCR reward", "err", err)
+	}
+	if err := db.Put(pocrRewardKey(number, hash), data); err != nil {
+		log.Crit("Failed to store PoCR reward", "err", err)
+	}
+}
+
+// DeletePoCRReward removes the PoCR reward record of a block.
+func DeletePoCRReward(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
+	if err := db.Delete(pocrRewardKey(number, hash)); err != nil {
+		log.Crit("Failed to delete PoCR reward", "err", err)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Tests PoCR reward record storage and retrieval operations.
+func TestPoCRRewardStorage(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	hash, reward := common.Hash{0x01}, &types.PoCRReward{
+		Author:            common.Address{0x02},
+		Footprint:         big.NewInt(1000),
+		Rank:              big.NewRat(2, 3),
+		NbNodes:           3,
+		TotalCryptoBefore: big.NewInt(-5),
+		TotalCryptoAfter:  big.NewInt(7),
+		BlockReward:       big.NewInt(20),
+		FeeAdjustment:     big.NewInt(-8),
+		Burnt:             big.NewInt(0),
+	}
+	if entry := ReadPoCRReward(db, hash, 1); entry != nil {
+		t.Fatalf("Non existent reward returned: %v", entry)
+	}
+	// Write and verify the reward in the database
+	WritePoCRReward(db, hash, 1, reward)
+	if entry := ReadPoCRReward(db, hash, 1); entry == nil {
+		t.Fatalf("Stored reward not found")
+	} else if err := checkPoCRRewardRLP(entry, reward); err != nil {
+		t.Fatal(err)
+	}
+	if entry := ReadPoCRReward(db, hash, 2); entry != nil {
+		t.Fatalf("Reward returned for another block number: %v", entry)
+	}
+	// Delete the reward and verify the execution
+	DeletePoCRReward(db, hash, 1)
+	if entry := ReadPoCRReward(db, hash, 1); entry != nil {
+		t.Fatalf("Deleted reward returned: %v", entry)
+	}
+	// Rewards are part of the block data
+	WritePoCRReward(db, hash, 1, reward)
+	DeleteBlock(db, hash, 1)
+	if HasPoCRReward(db, hash, 1) {
+		t.Fatalf("Reward not deleted with its block")
+	}
+}
+
+// Tests that the reward records are moved to the freezer along with the blocks.
+func TestAncientPoCRRewardStorage(t *testing.T) {
+	db, err := NewDatabaseWithFreezer(NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("failed to create database with ancient backend")
+	}
+	defer db.Close()
+
+	block := types.NewBlockWithHeader(&types.Header{
+		Number:      big.NewInt(0),
+		Extra:       []byte("test block"),
+		UncleHash:   types.EmptyUncleHash,
+		TxHash:      types.EmptyRootHash,
+		ReceiptHash: types.EmptyRootHash,
+	})
+	hash, number := block.Hash(), block.NumberU64()
+
+	// Blocks written straight to the freezer have no record
+	WriteAncientBlocks(db, []*types.Block{block}, []types.Receipts{nil}, big.NewInt(100))
+	if entry := ReadPoCRReward(db, hash, number); entry != nil {
+		t.Fatalf("Non existent reward returned: %v", entry)
+	}
+	// Freeze a processed block and check the record is moved over
+	child := types.NewBlockWithHeader(&types.Header{
+		ParentHash:  hash,
+		Number:      big.NewInt(1),
+		Extra:       []byte("test block"),
+		UncleHash:   types.EmptyUncleHash,
+		TxHash:      types.EmptyRootHash,
+		ReceiptHash: types.EmptyRootHash,
+	})
+	reward := &types.PoCRReward{
+		Author:            common.Address{0x02},
+		Footprint:         big.NewInt(1000),
+		Rank:              big.NewRat(1, 1),
+		NbNodes:           1,
+		TotalCryptoBefore: big.NewInt(0),
+		TotalCryptoAfter:  big.NewInt(1),
+		BlockReward:       big.NewInt(1),
+		FeeAdjustment:     big.NewInt(0),
+		Burnt:             big.NewInt(0),
+	}
+	WriteBlock(db, child)
+	WriteReceipts(db, child.Hash(), 1, nil)
+	WriteTd(db, child.Hash(), 1, big.NewInt(101))
+	WriteCanonicalHash(db, child.Hash(), 1)
+	WritePoCRReward(db, child.Hash(), 1, reward)
+
+	frdb := db.(*freezerdb)
+	if _, err := frdb.AncientStore.(*chainFreezer).freezeRange(&nofreezedb{KeyValueStore: frdb.KeyValueStore}, 1, 1); err != nil {
+		t.Fatalf("failed to freeze block: %v", err)
+	}
+	DeleteBlockWithoutNumber(db, child.Hash(), 1)
+
+	if entry := ReadPoCRReward(db, child.Hash(), 1); entry == nil {
+		t.Fatalf("Frozen reward not found")
+	} else if err := checkPoCRRewardRLP(entry, reward); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func checkPoCRRewardRLP(have, want *types.PoCRReward) error {
+	haveBytes, err := rlp.EncodeToBytes(have)
+	if err != nil {
+		return err
+	}
+	wantBytes, err := rlp.EncodeToBytes(want)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(haveBytes, wantBytes) {
+		return fmt.Errorf("reward mismatch: have %+v, want %+v", have, want)
+	}
+	if have.FeeAdjustment.Cmp(want.FeeAdjustment) != 0 || have.TotalCryptoBefore.Cmp(want.TotalCryptoBefore) != 0 {
+		return fmt.Errorf("signed values mismatch: have %v/%v, want %v/%v", have.FeeAdjustment, have.TotalCryptoBefore, want.FeeAdjustment, want.TotalCryptoBefore)
+	}
+	return nil
+}
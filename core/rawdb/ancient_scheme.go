@@ -34,16 +34,27 @@ const (
 
 	// chainFreezerDifficultyTable indicates the name of the freezer total difficulty table.
 	chainFreezerDifficultyTable = "diffs"
+
+	// chainFreezerPoCRTable indicates the name of the freezer PoCR reward table.
+	chainFreezerPoCRTable = "pocr"
 )
 
 // chainFreezerNoSnappy configures whether compression is disabled for the ancient-tables.
-// Hashes and difficulties don't compress well.
+// Hashes, difficulties and PoCR rewards don't compress well.
 var chainFreezerNoSnappy = map[string]bool{
 	chainFreezerHeaderTable:     false,
 	chainFreezerHashTable:       true,
 	chainFreezerBodiesTable:     false,
 	chainFreezerReceiptTable:    false,
 	chainFreezerDifficultyTable: true,
+	chainFreezerPoCRTable:       true,
+}
+
+// freezerBackfillTables lists the ancient-tables introduced after freezers may
+// have been populated. When such a table is found empty while the others are
+// not, it is filled with empty items instead of truncating the whole freezer.
+var freezerBackfillTables = map[string]bool{
+	chainFreezerPoCRTable: true,
 }
 
 // The list of identifiers of ancient stores.
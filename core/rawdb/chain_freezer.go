@@ -276,6 +276,9 @@ func (f *chainFreezer) freezeRange(nfdb *nofreezedb, number, limit uint64) (hash
 			if len(td) == 0 {
 				return fmt.Errorf("total difficulty missing, can't freeze block %d", number)
 			}
+			// The reward record is missing for the blocks that were not processed
+			// locally, freeze an empty item in that case.
+			reward := ReadPoCRRewardRLP(nfdb, hash, number)
 
 			// Write to the batch.
 			if err := op.AppendRaw(chainFreezerHashTable, number, hash[:]); err != nil {
@@ -293,6 +296,9 @@ func (f *chainFreezer) freezeRange(nfdb *nofreezedb, number, limit uint64) (hash
 			if err := op.AppendRaw(chainFreezerDifficultyTable, number, td); err != nil {
 				return fmt.Errorf("can't write td to Freezer: %v", err)
 			}
+			if err := op.AppendRaw(chainFreezerPoCRTable, number, reward); err != nil {
+				return fmt.Errorf("can't write PoCR reward to Freezer: %v", err)
+			}
 
 			hashes = append(hashes, hash)
 		}
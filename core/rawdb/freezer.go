@@ -129,8 +129,11 @@ func NewFreezer(datadir string, namespace string, readonly bool, maxTableSize ui
 		// validate also sets `freezer.frozen`.
 		err = freezer.validate()
 	} else {
-		// Truncate all tables to common length.
-		err = freezer.repair()
+		// Fill up the newly introduced tables and truncate all tables to
+		// common length.
+		if err = freezer.backfill(); err == nil {
+			err = freezer.repair()
+		}
 	}
 	if err != nil {
 		for _, table := range freezer.tables {
@@ -345,6 +348,43 @@ func (f *Freezer) validate() error {
 	return nil
 }
 
+// backfill appends empty items to the tables listed in freezerBackfillTables
+// which are found empty while the other tables are not, so that adding a table
+// does not cause repair to truncate the whole freezer.
+func (f *Freezer) backfill() error {
+	head := uint64(math.MaxUint64)
+	for kind, table := range f.tables {
+		if freezerBackfillTables[kind] {
+			continue
+		}
+		if items := atomic.LoadUint64(&table.items); items < head {
+			head = items
+		}
+	}
+	if head == math.MaxUint64 || head == 0 {
+		return nil
+	}
+	for kind, table := range f.tables {
+		if !freezerBackfillTables[kind] || atomic.LoadUint64(&table.items) != 0 {
+			continue
+		}
+		batch := table.newBatch()
+		for item := uint64(0); item < head; item++ {
+			if err := batch.AppendRaw(item, nil); err != nil {
+				return err
+			}
+		}
+		if err := batch.commit(); err != nil {
+			return err
+		}
+		if err := table.Sync(); err != nil {
+			return err
+		}
+		log.Info("Backfilled ancient table", "table", kind, "items", head)
+	}
+	return nil
+}
+
 // repair truncates all data tables to the same length.
 func (f *Freezer) repair() error {
 	var (
@@ -283,6 +283,45 @@ func TestFreezerReadonlyValidate(t *testing.T) {
 	}
 }
 
+// TestFreezerBackfill checks that a table introduced after the freezer has been
+// populated is filled up with empty items, instead of truncating the others.
+func TestFreezerBackfill(t *testing.T) {
+	dir := t.TempDir()
+	f, err := NewFreezer(dir, "", false, 2049, map[string]bool{"a": true})
+	if err != nil {
+		t.Fatal("can't open freezer", err)
+	}
+	var item = make([]byte, 1024)
+	batch := f.tables["a"].newBatch()
+	require.NoError(t, batch.AppendRaw(0, item))
+	require.NoError(t, batch.AppendRaw(1, item))
+	require.NoError(t, batch.AppendRaw(2, item))
+	require.NoError(t, batch.commit())
+	require.NoError(t, f.Close())
+
+	// Reopen with a backfilled table added
+	f, err = NewFreezer(dir, "", false, 2049, map[string]bool{"a": true, chainFreezerPoCRTable: true})
+	if err != nil {
+		t.Fatal("can't reopen freezer", err)
+	}
+	defer f.Close()
+
+	checkAncientCount(t, f, "a", 3)
+	checkAncientCount(t, f, chainFreezerPoCRTable, 3)
+	for i := uint64(0); i < 3; i++ {
+		blob, err := f.Ancient(chainFreezerPoCRTable, i)
+		if err != nil {
+			t.Fatalf("can't read backfilled item %d: %v", i, err)
+		}
+		if len(blob) != 0 {
+			t.Fatalf("backfilled item %d not empty: %x", i, blob)
+		}
+	}
+	if blob, _ := f.Ancient("a", 2); !bytes.Equal(blob, item) {
+		t.Fatalf("existing item lost")
+	}
+}
+
 func newFreezerForTesting(t *testing.T, tables map[string]bool) (*Freezer, string) {
 	t.Helper()
 
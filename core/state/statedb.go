@@ -353,6 +353,18 @@ func (s *StateDB) Database() Database {
 	return s.db
 }
 
+// GetStorageRoot retrieves the storage root of an account as of the last root
+// computation. The returned flag reports whether the storage is unchanged since
+// then, i.e. whether the root still identifies the storage content.
+func (s *StateDB) GetStorageRoot(addr common.Address) (common.Hash, bool) {
+	stateObject := s.getStateObject(addr)
+	if stateObject == nil {
+		return common.Hash{}, false
+	}
+	clean := len(stateObject.pendingStorage) == 0 && len(stateObject.dirtyStorage) == 0 && stateObject.fakeStorage == nil
+	return stateObject.data.Root, clean
+}
+
 // StorageTrie returns the storage trie of an account.
 // The return value is a copy and is nil for non-existent accounts.
 func (s *StateDB) StorageTrie(addr common.Address) Trie {
@@ -954,3 +954,36 @@ func TestFlushOrderDataLoss(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the storage root of an account is only reported as up to date when
+// its storage is unchanged since the last root computation.
+func TestGetStorageRoot(t *testing.T) {
+	state, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	addr := common.Address{0x01}
+
+	if _, clean := state.GetStorageRoot(addr); clean {
+		t.Fatalf("missing account reported as clean")
+	}
+	state.SetState(addr, common.Hash{0x01}, common.Hash{0x02})
+	if _, clean := state.GetStorageRoot(addr); clean {
+		t.Fatalf("dirty storage reported as clean")
+	}
+	state.Finalise(false)
+	if _, clean := state.GetStorageRoot(addr); clean {
+		t.Fatalf("pending storage reported as clean")
+	}
+	state.IntermediateRoot(false)
+	root, clean := state.GetStorageRoot(addr)
+	if !clean || root == emptyRoot {
+		t.Fatalf("hashed storage mismatch: have %x/%v, want non-empty root and clean", root, clean)
+	}
+	stateRoot, _ := state.Commit(false)
+	state, _ = New(stateRoot, state.db, nil)
+	if have, clean := state.GetStorageRoot(addr); !clean || have != root {
+		t.Fatalf("committed storage mismatch: have %x/%v, want %x/true", have, clean, root)
+	}
+	state.SetState(addr, common.Hash{0x01}, common.Hash{0x03})
+	if _, clean := state.GetStorageRoot(addr); clean {
+		t.Fatalf("modified storage reported as clean")
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/misc"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/systemcontracts"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -70,6 +71,9 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 	if p.config.DAOForkSupport && p.config.DAOForkBlock != nil && p.config.DAOForkBlock.Cmp(block.Number()) == 0 {
 		misc.ApplyDAOHardFork(statedb)
 	}
+	if err := systemcontracts.UpgradeBuildInSystemContract(p.config, blockNumber, statedb); err != nil {
+		return nil, nil, 0, err
+	}
 	blockContext := NewEVMBlockContext(header, p.bc, nil)
 	vmenv := vm.NewEVM(blockContext, vm.TxContext{}, statedb, p.config, cfg)
 	// Iterate over and process the individual transactions
@@ -87,7 +91,9 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 		allLogs = append(allLogs, receipt.Logs...)
 	}
 	// Finalize the block, applying any consensus engine specific extras (e.g. block rewards)
-	p.engine.Finalize(p.bc, header, statedb, block.Transactions(), block.Uncles())
+	if err := p.engine.Finalize(p.bc, header, statedb, block.Transactions(), block.Uncles(), receipts); err != nil {
+		return nil, nil, 0, err
+	}
 
 	return receipts, allLogs, *usedGas, nil
 }
@@ -101,11 +107,6 @@ func applyTransactionWithEngine(msg types.Message, config *params.ChainConfig, a
 	if err != nil {
 		return nil, err
 	}
-	// copy the calculated fee fields to the transaction structure
-	tx.FeeSpent = result.FeeSpent
-	tx.FeeTransferred = result.FeeTransferred
-	tx.FeeBurnt = result.FeeBurnt
-
 	// Update the state with pending changes.
 	var root []byte
 	if config.IsByzantium(blockNumber) {
@@ -125,6 +126,7 @@ func applyTransactionWithEngine(msg types.Message, config *params.ChainConfig, a
 	}
 	receipt.TxHash = tx.Hash()
 	receipt.GasUsed = result.UsedGas
+	receipt.FeeSpent, receipt.FeeTransferred, receipt.FeeBurnt = result.FeeSpent, result.FeeTransferred, result.FeeBurnt
 
 	// If the transaction created a contract, store the creation address in the receipt.
 	if msg.To() == nil {
@@ -0,0 +1,30 @@
+package systemcontracts
+
+import "github.com/ethereum/go-ethereum/common"
+
+const (
+	// pocrNetworkChainID is the chain id of the PoCR network launched from
+	// consensus/cliquepocr/networkInit/genesis.yml.
+	pocrNetworkChainID = 1804
+
+	// SessionStorageRuntimeUpgrade replaces the code of the session variables
+	// contract of the PoCR network. The first genesis of the network allocated
+	// the creation code of the contract instead of its runtime code, so every
+	// call ran the constructor again. The upgrade installs the runtime code and
+	// keeps the storage, where the engine reads the variables from.
+	SessionStorageRuntimeUpgrade = "sessionStorageRuntime"
+)
+
+// sessionStorageRuntimeCode is the runtime code of CliquePocrSessionStorage, as
+// allocated by the current network genesis.
+const sessionStorageRuntimeCode = "0x608060405234801561001057600080fd5b506004361061002b5760003560e01c8063403e6fc414610030575b600080fd5b61004a60048036038101906100459190610214565b610060565b6040516100579190610351565b60405180910390f35b60008060003073ffffffffffffffffffffffffffffffffffffffff16858560405160200161008f92919061032d565b60405160208183030381529060405280519060200120604051602401604051602081830303815290604052907bffffffffffffffffffffffffffffffffffffffffffffffffffffffff19166020820180517bffffffffffffffffffffffffffffffffffffffffffffffffffffffff83818316178352505050506040516101159190610316565b600060405180830381855afa9150503d8060008114610150576040519150601f19603f3d011682016040523d82523d6000602084013e610155565b606091505b5091509150811561017d57808060200190518101906101749190610261565b925050506101a3565b7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff925050505b92915050565b60008083601f8401126101bf576101be6103de565b5b8235905067ffffffffffffffff8111156101dc576101db6103d9565b5b6020830191508360018202830111156101f8576101f76103e3565b5b9250929050565b60008151905061020e8161041b565b92915050565b6000806020838503121561022b5761022a6103ed565b5b600083013567ffffffffffffffff811115610249576102486103e8565b5b610255858286016101a9565b92509250509250929050565b600060208284031215610277576102766103ed565b5b6000610285848285016101ff565b91505092915050565b60006102998261036c565b6102a38185610377565b93506102b38185602086016103a6565b80840191505092915050565b60006102cb8385610382565b93506102d8838584610397565b82840190509392505050565b60006102f1600283610382565b91506102fc826103f2565b600282019050919050565b6103108161038d565b82525050565b6000610322828461028e565b915081905092915050565b600061033a8284866102bf565b9150610345826102e4565b91508190509392505050565b60006020820190506103666000830184610307565b92915050565b600081519050919050565b600081905092915050565b600081905092915050565b6000819050919050565b82818337600083830152505050565b60005b838110156103c45780820151818401526020810190506103a9565b838111156103d3576000848401525b50505050565b600080fd5b600080fd5b600080fd5b600080fd5b600080fd5b7f2829000000000000000000000000000000000000000000000000000000000000600082015250565b6104248161038d565b811461042f57600080fd5b5056fea2646970667358221220de0f85e001b98ed11d512f12146a3a1ef386f126bb4d0e60e87a4d9bd320c86764736f6c63430008070033"
+
+func init() {
+	RegisterUpgrade(pocrNetworkChainID, &Upgrade{
+		UpgradeName: SessionStorageRuntimeUpgrade,
+		Configs: []*UpgradeConfig{{
+			ContractAddr: common.HexToAddress(sessionVariablesContractAddress),
+			Code:         sessionStorageRuntimeCode,
+		}},
+	})
+}
@@ -0,0 +1,62 @@
+package systemcontracts
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the upgrade of the PoCR network installs the session variables code
+// of the current network genesis, keeping the session variables.
+func TestSessionStorageRuntimeUpgrade(t *testing.T) {
+	blob, err := os.ReadFile("../../consensus/cliquepocr/networkInit/genesis.yml")
+	if err != nil {
+		t.Fatalf("failed to read the network genesis: %v", err)
+	}
+	var genesis struct {
+		Config struct {
+			ChainID uint64 `json:"chainId"`
+		} `json:"config"`
+		Alloc map[string]struct {
+			Code string `json:"code"`
+		} `json:"alloc"`
+	}
+	if err := json.Unmarshal(blob, &genesis); err != nil {
+		t.Fatalf("failed to parse the network genesis: %v", err)
+	}
+	if genesis.Config.ChainID != pocrNetworkChainID {
+		t.Fatalf("network chain id mismatch: have %d, want %d", pocrNetworkChainID, genesis.Config.ChainID)
+	}
+	var (
+		address = common.HexToAddress(sessionVariablesContractAddress)
+		slot    = common.Hash{0x01}
+		value   = common.Hash{0x2a}
+	)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetCode(address, []byte{0x60, 0x80})
+	statedb.SetState(address, slot, value)
+
+	config := &params.ChainConfig{
+		ChainID:                new(big.Int).SetUint64(pocrNetworkChainID),
+		SystemContractUpgrades: []params.SystemContractUpgrade{{Name: SessionStorageRuntimeUpgrade, Block: big.NewInt(10)}},
+	}
+	if err := CheckUpgrades(config); err != nil {
+		t.Fatalf("network upgrade rejected: %v", err)
+	}
+	if err := UpgradeBuildInSystemContract(config, big.NewInt(10), statedb); err != nil {
+		t.Fatalf("failed to apply the network upgrade: %v", err)
+	}
+	if code, want := statedb.GetCode(address), common.FromHex(genesis.Alloc[sessionVariablesContractAddress[2:]].Code); len(want) == 0 || !bytes.Equal(code, want) {
+		t.Errorf("session variables code mismatch: have %x, want %x", code, want)
+	}
+	if have := statedb.GetState(address, slot); have != value {
+		t.Errorf("session variable lost: have %x, want %x", have, value)
+	}
+}
@@ -1,10 +1,13 @@
 package systemcontracts
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 type UpgradeConfig struct {
@@ -21,3 +24,84 @@ type Upgrade struct {
 }
 
 type upgradeHook func(blockNumber *big.Int, contractAddr common.Address, statedb *state.StateDB) error
+
+// upgrades is the table of the system contract upgrades of each network, by
+// chain id and upgrade name. The chain configuration schedules them with its
+// SystemContractUpgrades.
+var upgrades = make(map[uint64]map[string]*Upgrade)
+
+// RegisterUpgrade adds an upgrade to the table of the network with the given
+// chain id. It is meant to be called from the init functions of the networks,
+// and panics if the network already has an upgrade of the same name.
+func RegisterUpgrade(chainID uint64, upgrade *Upgrade) {
+	if upgrades[chainID] == nil {
+		upgrades[chainID] = make(map[string]*Upgrade)
+	}
+	if _, exists := upgrades[chainID][upgrade.UpgradeName]; exists {
+		panic(fmt.Sprintf("system contract upgrade %s already registered for chain %d", upgrade.UpgradeName, chainID))
+	}
+	upgrades[chainID][upgrade.UpgradeName] = upgrade
+}
+
+// lookupUpgrade retrieves a scheduled upgrade from the table of the network.
+func lookupUpgrade(config *params.ChainConfig, name string) (*Upgrade, error) {
+	if config.ChainID == nil {
+		return nil, fmt.Errorf("system contract upgrade %s scheduled on a chain without id", name)
+	}
+	upgrade := upgrades[config.ChainID.Uint64()][name]
+	if upgrade == nil {
+		return nil, fmt.Errorf("unknown system contract upgrade %s for chain %v", name, config.ChainID)
+	}
+	return upgrade, nil
+}
+
+// CheckUpgrades verifies that all the system contract upgrades scheduled by the
+// chain configuration are known, so that a node cannot start on a chain it
+// would fail to process.
+func CheckUpgrades(config *params.ChainConfig) error {
+	for _, scheduled := range config.SystemContractUpgrades {
+		if _, err := lookupUpgrade(config, scheduled.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpgradeBuildInSystemContract applies the system contract upgrades scheduled at
+// the given block. It must run on the parent state, before the transactions of
+// the block.
+func UpgradeBuildInSystemContract(config *params.ChainConfig, blockNumber *big.Int, statedb *state.StateDB) error {
+	if config == nil || blockNumber == nil || statedb == nil {
+		return nil
+	}
+	for _, name := range config.SystemContractUpgradesAt(blockNumber) {
+		upgrade, err := lookupUpgrade(config, name)
+		if err != nil {
+			return err
+		}
+		if err := applySystemContractUpgrade(upgrade, blockNumber, statedb); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applySystemContractUpgrade(upgrade *Upgrade, blockNumber *big.Int, statedb *state.StateDB) error {
+	log.Info("Apply system contract upgrade", "name", upgrade.UpgradeName, "number", blockNumber)
+	for _, cfg := range upgrade.Configs {
+		log.Info("Upgrade system contract", "address", cfg.ContractAddr, "commit", cfg.CommitUrl)
+		if cfg.BeforeUpgrade != nil {
+			if err := cfg.BeforeUpgrade(blockNumber, cfg.ContractAddr, statedb); err != nil {
+				return fmt.Errorf("upgrade %s of contract %x: before upgrade: %v", upgrade.UpgradeName, cfg.ContractAddr, err)
+			}
+		}
+		statedb.SetCode(cfg.ContractAddr, common.FromHex(cfg.Code))
+
+		if cfg.AfterUpgrade != nil {
+			if err := cfg.AfterUpgrade(blockNumber, cfg.ContractAddr, statedb); err != nil {
+				return fmt.Errorf("upgrade %s of contract %x: after upgrade: %v", upgrade.UpgradeName, cfg.ContractAddr, err)
+			}
+		}
+	}
+	return nil
+}
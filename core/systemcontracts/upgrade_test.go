@@ -0,0 +1,130 @@
+package systemcontracts
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that a scheduled upgrade replaces the code at its block only, and runs
+// the storage migrations around the code replacement.
+func TestUpgradeBuildInSystemContract(t *testing.T) {
+	var (
+		chainID  = uint64(0xfeed)
+		address  = common.HexToAddress(proofOfCarbonReductionContractAddress)
+		slot     = common.Hash{0x01}
+		migrated = common.Hash{0x02}
+		calls    []string
+	)
+	RegisterUpgrade(chainID, &Upgrade{
+		UpgradeName: "test",
+		Configs: []*UpgradeConfig{{
+			ContractAddr: address,
+			Code:         "0x6001",
+			BeforeUpgrade: func(blockNumber *big.Int, contractAddr common.Address, statedb *state.StateDB) error {
+				if code := statedb.GetCode(contractAddr); len(code) != 1 {
+					t.Errorf("code replaced before the migration: %x", code)
+				}
+				calls = append(calls, "before")
+				statedb.SetState(contractAddr, migrated, statedb.GetState(contractAddr, slot))
+				return nil
+			},
+			AfterUpgrade: func(blockNumber *big.Int, contractAddr common.Address, statedb *state.StateDB) error {
+				if code := statedb.GetCode(contractAddr); len(code) != 2 {
+					t.Errorf("code not replaced before the migration: %x", code)
+				}
+				calls = append(calls, "after")
+				statedb.SetState(contractAddr, slot, common.Hash{})
+				return nil
+			},
+		}},
+	})
+	defer delete(upgrades, chainID)
+
+	config := &params.ChainConfig{
+		ChainID:                new(big.Int).SetUint64(chainID),
+		SystemContractUpgrades: []params.SystemContractUpgrade{{Name: "test", Block: big.NewInt(10)}},
+	}
+	if err := CheckUpgrades(config); err != nil {
+		t.Fatalf("known upgrade rejected: %v", err)
+	}
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetCode(address, []byte{0x00})
+	statedb.SetState(address, slot, common.Hash{0xaa})
+
+	if err := UpgradeBuildInSystemContract(config, big.NewInt(9), statedb); err != nil || len(calls) != 0 {
+		t.Fatalf("upgrade applied before its block: calls %v, err %v", calls, err)
+	}
+	if err := UpgradeBuildInSystemContract(config, big.NewInt(10), statedb); err != nil {
+		t.Fatalf("upgrade failed: %v", err)
+	}
+	if len(calls) != 2 || calls[0] != "before" || calls[1] != "after" {
+		t.Errorf("migrations mismatch: have %v, want [before after]", calls)
+	}
+	if code := statedb.GetCode(address); len(code) != 2 || code[0] != 0x60 || code[1] != 0x01 {
+		t.Errorf("code mismatch: have %x, want 6001", code)
+	}
+	if have := statedb.GetState(address, migrated); have != (common.Hash{0xaa}) {
+		t.Errorf("migrated slot mismatch: have %x", have)
+	}
+	if have := statedb.GetState(address, slot); have != (common.Hash{}) {
+		t.Errorf("cleared slot mismatch: have %x", have)
+	}
+	if err := UpgradeBuildInSystemContract(config, big.NewInt(11), statedb); err != nil || len(calls) != 2 {
+		t.Errorf("upgrade applied after its block: calls %v, err %v", calls, err)
+	}
+}
+
+// Tests that failing migrations and unknown upgrades are reported.
+func TestUpgradeErrors(t *testing.T) {
+	chainID := uint64(0xbeef)
+	RegisterUpgrade(chainID, &Upgrade{
+		UpgradeName: "failing",
+		Configs: []*UpgradeConfig{{
+			ContractAddr: common.HexToAddress(sessionVariablesContractAddress),
+			BeforeUpgrade: func(*big.Int, common.Address, *state.StateDB) error {
+				return errors.New("migration failed")
+			},
+		}},
+	})
+	defer delete(upgrades, chainID)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	config := &params.ChainConfig{
+		ChainID:                new(big.Int).SetUint64(chainID),
+		SystemContractUpgrades: []params.SystemContractUpgrade{{Name: "failing", Block: big.NewInt(1)}},
+	}
+	if err := UpgradeBuildInSystemContract(config, big.NewInt(1), statedb); err == nil {
+		t.Errorf("failing migration accepted")
+	}
+	config.SystemContractUpgrades = append(config.SystemContractUpgrades, params.SystemContractUpgrade{Name: "unknown", Block: big.NewInt(2)})
+	if err := CheckUpgrades(config); err == nil {
+		t.Errorf("unknown upgrade accepted")
+	}
+	if err := UpgradeBuildInSystemContract(config, big.NewInt(2), statedb); err == nil {
+		t.Errorf("unknown upgrade applied")
+	}
+	config.ChainID = nil
+	if err := CheckUpgrades(config); err == nil {
+		t.Errorf("upgrade without chain id accepted")
+	}
+}
+
+// Tests that registering two upgrades of the same name on a network panics.
+func TestRegisterUpgradeDuplicate(t *testing.T) {
+	chainID := uint64(0xbeef)
+	RegisterUpgrade(chainID, &Upgrade{UpgradeName: "duplicate"})
+	defer delete(upgrades, chainID)
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("duplicate upgrade registered")
+		}
+	}()
+	RegisterUpgrade(chainID, &Upgrade{UpgradeName: "duplicate"})
+}
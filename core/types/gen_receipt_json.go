@@ -25,6 +25,9 @@ func (r Receipt) MarshalJSON() ([]byte, error) {
 		TxHash            common.Hash    `json:"transactionHash" gencodec:"required"`
 		ContractAddress   common.Address `json:"contractAddress"`
 		GasUsed           hexutil.Uint64 `json:"gasUsed" gencodec:"required"`
+		FeeSpent          *hexutil.Big   `json:"feeSpent,omitempty"`
+		FeeTransferred    *hexutil.Big   `json:"feeTransferred,omitempty"`
+		FeeBurnt          *hexutil.Big   `json:"feeBurnt,omitempty"`
 		BlockHash         common.Hash    `json:"blockHash,omitempty"`
 		BlockNumber       *hexutil.Big   `json:"blockNumber,omitempty"`
 		TransactionIndex  hexutil.Uint   `json:"transactionIndex"`
@@ -39,6 +42,9 @@ func (r Receipt) MarshalJSON() ([]byte, error) {
 	enc.TxHash = r.TxHash
 	enc.ContractAddress = r.ContractAddress
 	enc.GasUsed = hexutil.Uint64(r.GasUsed)
+	enc.FeeSpent = (*hexutil.Big)(r.FeeSpent)
+	enc.FeeTransferred = (*hexutil.Big)(r.FeeTransferred)
+	enc.FeeBurnt = (*hexutil.Big)(r.FeeBurnt)
 	enc.BlockHash = r.BlockHash
 	enc.BlockNumber = (*hexutil.Big)(r.BlockNumber)
 	enc.TransactionIndex = hexutil.Uint(r.TransactionIndex)
@@ -57,6 +63,9 @@ func (r *Receipt) UnmarshalJSON(input []byte) error {
 		TxHash            *common.Hash    `json:"transactionHash" gencodec:"required"`
 		ContractAddress   *common.Address `json:"contractAddress"`
 		GasUsed           *hexutil.Uint64 `json:"gasUsed" gencodec:"required"`
+		FeeSpent          *hexutil.Big    `json:"feeSpent,omitempty"`
+		FeeTransferred    *hexutil.Big    `json:"feeTransferred,omitempty"`
+		FeeBurnt          *hexutil.Big    `json:"feeBurnt,omitempty"`
 		BlockHash         *common.Hash    `json:"blockHash,omitempty"`
 		BlockNumber       *hexutil.Big    `json:"blockNumber,omitempty"`
 		TransactionIndex  *hexutil.Uint   `json:"transactionIndex"`
@@ -97,6 +106,15 @@ func (r *Receipt) UnmarshalJSON(input []byte) error {
 		return errors.New("missing required field 'gasUsed' for Receipt")
 	}
 	r.GasUsed = uint64(*dec.GasUsed)
+	if dec.FeeSpent != nil {
+		r.FeeSpent = (*big.Int)(dec.FeeSpent)
+	}
+	if dec.FeeTransferred != nil {
+		r.FeeTransferred = (*big.Int)(dec.FeeTransferred)
+	}
+	if dec.FeeBurnt != nil {
+		r.FeeBurnt = (*big.Int)(dec.FeeBurnt)
+	}
 	if dec.BlockHash != nil {
 		r.BlockHash = *dec.BlockHash
 	}
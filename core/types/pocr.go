@@ -0,0 +1,189 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// errZeroRankDenominator is returned when decoding a reward record whose rank is
+// not a valid rational.
+var errZeroRankDenominator = errors.New("zero rank denominator")
+
+var (
+	// PoCRLogAddress is the emitter of the PoCR system logs, the system address
+	// no contract lives at, so that the logs are not mistaken for the ones of
+	// the PoCR contract.
+	PoCRLogAddress = common.HexToAddress("0xfffffffffffffffffffffffffffffffffffffffe")
+
+	// RewardMintedTopic is the id of the RewardMinted(address indexed sealer,
+	// uint256 amount, uint256 rank) system log, the rank having 18 decimals.
+	RewardMintedTopic = crypto.Keccak256Hash([]byte("RewardMinted(address,uint256,uint256)"))
+
+	// FeeAdjustedTopic is the id of the FeeAdjusted(address indexed sealer,
+	// int256 amount) system log.
+	FeeAdjustedTopic = crypto.Keccak256Hash([]byte("FeeAdjusted(address,int256)"))
+
+	// pocrLogPrefix is the preimage prefix of the transaction hash of the PoCR
+	// system logs.
+	pocrLogPrefix = []byte("pocr-system-logs")
+
+	// rankUnit is the fixed point unit of the rank in the system logs.
+	rankUnit = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+)
+
+// PoCRLogTxHash returns the transaction hash of the PoCR system logs of a block.
+// No transaction has this hash, it only tells the system logs of the different
+// blocks apart.
+func PoCRLogTxHash(blockHash common.Hash) common.Hash {
+	return crypto.Keccak256Hash(pocrLogPrefix, blockHash.Bytes())
+}
+
+// PoCRReward is the breakdown of the proof-of-carbon-reduction reward granted
+// to the sealer of a block, as computed when the block was finalized.
+type PoCRReward struct {
+	Author            common.Address // Sealer of the block
+	Footprint         *big.Int       // Footprint of the sealer used for the ranking (audit penalty included)
+	Rank              *big.Rat       // Rank of the sealer, between 0 and 1
+	NbNodes           uint64         // Number of ranked sealers
+	TotalCryptoBefore *big.Int       // Total crypto generated before the block
+	TotalCryptoAfter  *big.Int       // Total crypto generated once the block is applied
+	BlockReward       *big.Int       // Amount minted for the sealer
+	FeeAdjustment     *big.Int       // Fees added to (positive) or removed from (negative) the sealer
+	Burnt             *big.Int       // Fees burnt by the EIP-1559
+}
+
+// signedBig is the RLP representation of a big integer that may be negative.
+type signedBig struct {
+	Negative bool
+	Abs      *big.Int
+}
+
+func newSignedBig(v *big.Int) signedBig {
+	if v == nil {
+		return signedBig{Abs: new(big.Int)}
+	}
+	return signedBig{Negative: v.Sign() < 0, Abs: new(big.Int).Abs(v)}
+}
+
+func (s signedBig) bigInt() *big.Int {
+	v := new(big.Int).Set(s.Abs)
+	if s.Negative {
+		v.Neg(v)
+	}
+	return v
+}
+
+// pocrRewardRLP is the consensus-independent storage encoding of a PoCRReward.
+type pocrRewardRLP struct {
+	Author            common.Address
+	Footprint         *big.Int
+	RankNum           *big.Int
+	RankDenom         *big.Int
+	NbNodes           uint64
+	TotalCryptoBefore signedBig
+	TotalCryptoAfter  signedBig
+	BlockReward       *big.Int
+	FeeAdjustment     signedBig
+	Burnt             *big.Int
+}
+
+// EncodeRLP implements rlp.Encoder.
+func (r *PoCRReward) EncodeRLP(w io.Writer) error {
+	enc := &pocrRewardRLP{
+		Author:            r.Author,
+		Footprint:         r.Footprint,
+		RankNum:           new(big.Int),
+		RankDenom:         big.NewInt(1),
+		NbNodes:           r.NbNodes,
+		TotalCryptoBefore: newSignedBig(r.TotalCryptoBefore),
+		TotalCryptoAfter:  newSignedBig(r.TotalCryptoAfter),
+		BlockReward:       r.BlockReward,
+		FeeAdjustment:     newSignedBig(r.FeeAdjustment),
+		Burnt:             r.Burnt,
+	}
+	if r.Rank != nil {
+		enc.RankNum, enc.RankDenom = r.Rank.Num(), r.Rank.Denom()
+	}
+	return rlp.Encode(w, enc)
+}
+
+// DecodeRLP implements rlp.Decoder.
+func (r *PoCRReward) DecodeRLP(s *rlp.Stream) error {
+	var dec pocrRewardRLP
+	if err := s.Decode(&dec); err != nil {
+		return err
+	}
+	if dec.RankDenom.Sign() == 0 {
+		return errZeroRankDenominator
+	}
+	r.Author = dec.Author
+	r.Footprint = dec.Footprint
+	r.Rank = new(big.Rat).SetFrac(dec.RankNum, dec.RankDenom)
+	r.NbNodes = dec.NbNodes
+	r.TotalCryptoBefore = dec.TotalCryptoBefore.bigInt()
+	r.TotalCryptoAfter = dec.TotalCryptoAfter.bigInt()
+	r.BlockReward = dec.BlockReward
+	r.FeeAdjustment = dec.FeeAdjustment.bigInt()
+	r.Burnt = dec.Burnt
+	return nil
+}
+
+// Logs returns the system logs of the reward: a RewardMinted log when a reward
+// was minted and a FeeAdjusted log when the fees of the sealer were adjusted.
+// The logs are not part of the receipts nor of the bloom of the block, they
+// follow the txCount transactions and the logCount logs of the block, as if
+// they were emitted by an extra transaction.
+func (r *PoCRReward) Logs(blockHash common.Hash, number uint64, txCount, logCount uint) []*Log {
+	var (
+		logs   []*Log
+		sealer = common.BytesToHash(r.Author.Bytes())
+		txHash = PoCRLogTxHash(blockHash)
+	)
+	emit := func(topic common.Hash, data []byte) {
+		logs = append(logs, &Log{
+			Address:     PoCRLogAddress,
+			Topics:      []common.Hash{topic, sealer},
+			Data:        data,
+			BlockNumber: number,
+			TxHash:      txHash,
+			TxIndex:     txCount,
+			BlockHash:   blockHash,
+			Index:       logCount + uint(len(logs)),
+		})
+	}
+	if r.BlockReward != nil && r.BlockReward.Sign() > 0 {
+		rank := new(big.Int)
+		if r.Rank != nil {
+			rank.Mul(r.Rank.Num(), rankUnit)
+			rank.Quo(rank, r.Rank.Denom())
+		}
+		emit(RewardMintedTopic, append(math.U256Bytes(new(big.Int).Set(r.BlockReward)), math.U256Bytes(rank)...))
+	}
+	if r.FeeAdjustment != nil && r.FeeAdjustment.Sign() != 0 {
+		// int256, in two's complement
+		emit(FeeAdjustedTopic, math.U256Bytes(new(big.Int).Set(r.FeeAdjustment)))
+	}
+	return logs
+}
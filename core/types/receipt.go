@@ -64,6 +64,13 @@ type Receipt struct {
 	ContractAddress common.Address `json:"contractAddress"`
 	GasUsed         uint64         `json:"gasUsed" gencodec:"required"`
 
+	// Fee fields: the fees paid by the transaction, split between the block sealer
+	// and the EIP-1559 burn. They are derived from the transaction and the block
+	// base fee, so they are not stored.
+	FeeSpent       *big.Int `json:"feeSpent,omitempty"`       // Fees paid by the sender
+	FeeTransferred *big.Int `json:"feeTransferred,omitempty"` // Fees received by the block sealer
+	FeeBurnt       *big.Int `json:"feeBurnt,omitempty"`       // Fees burnt, FeeSpent - FeeTransferred
+
 	// Inclusion information: These fields provide information about the inclusion of the
 	// transaction corresponding to this receipt.
 	BlockHash        common.Hash `json:"blockHash,omitempty"`
@@ -77,6 +84,9 @@ type receiptMarshaling struct {
 	Status            hexutil.Uint64
 	CumulativeGasUsed hexutil.Uint64
 	GasUsed           hexutil.Uint64
+	FeeSpent          *hexutil.Big
+	FeeTransferred    *hexutil.Big
+	FeeBurnt          *hexutil.Big
 	BlockNumber       *hexutil.Big
 	TransactionIndex  hexutil.Uint
 }
@@ -396,7 +406,7 @@ func (rs Receipts) EncodeIndex(i int, w *bytes.Buffer) {
 
 // DeriveFields fills the receipts with their computed fields based on consensus
 // data and contextual infos like containing block and transactions.
-func (rs Receipts) DeriveFields(config *params.ChainConfig, hash common.Hash, number uint64, txs Transactions) error {
+func (rs Receipts) DeriveFields(config *params.ChainConfig, hash common.Hash, number uint64, baseFee *big.Int, txs Transactions) error {
 	signer := MakeSigner(config, new(big.Int).SetUint64(number))
 
 	logIndex := uint(0)
@@ -425,6 +435,8 @@ func (rs Receipts) DeriveFields(config *params.ChainConfig, hash common.Hash, nu
 		} else {
 			rs[i].GasUsed = rs[i].CumulativeGasUsed - rs[i-1].CumulativeGasUsed
 		}
+		// The fees can be calculated based on the used gas and the base fee
+		rs[i].FeeSpent, rs[i].FeeTransferred, rs[i].FeeBurnt = txs[i].Fees(rs[i].GasUsed, baseFee)
 		// The derived log fields can simply be set from the block and transaction
 		for j := 0; j < len(rs[i].Logs); j++ {
 			rs[i].Logs[j].BlockNumber = number
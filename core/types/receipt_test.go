@@ -280,9 +280,10 @@ func TestDeriveFields(t *testing.T) {
 	// Clear all the computed fields and re-derive them
 	number := big.NewInt(1)
 	hash := common.BytesToHash([]byte{0x03, 0x14})
+	baseFee := big.NewInt(1)
 
 	clearComputedFieldsOnReceipts(t, receipts)
-	if err := receipts.DeriveFields(params.TestChainConfig, hash, number.Uint64(), txs); err != nil {
+	if err := receipts.DeriveFields(params.TestChainConfig, hash, number.Uint64(), baseFee, txs); err != nil {
 		t.Fatalf("DeriveFields(...) = %v, want <nil>", err)
 	}
 	// Iterate over all the computed fields and check that they're correct
@@ -308,6 +309,16 @@ func TestDeriveFields(t *testing.T) {
 		if receipts[i].GasUsed != txs[i].Gas() {
 			t.Errorf("receipts[%d].GasUsed = %d, want %d", i, receipts[i].GasUsed, txs[i].Gas())
 		}
+		gasUsed := new(big.Int).SetUint64(receipts[i].GasUsed)
+		if spent := new(big.Int).Mul(gasUsed, txs[i].GasPrice()); receipts[i].FeeSpent.Cmp(spent) != 0 {
+			t.Errorf("receipts[%d].FeeSpent = %s, want %s", i, receipts[i].FeeSpent, spent)
+		}
+		if burnt := new(big.Int).Mul(gasUsed, baseFee); receipts[i].FeeBurnt.Cmp(burnt) != 0 {
+			t.Errorf("receipts[%d].FeeBurnt = %s, want %s", i, receipts[i].FeeBurnt, burnt)
+		}
+		if transferred := new(big.Int).Sub(receipts[i].FeeSpent, receipts[i].FeeBurnt); receipts[i].FeeTransferred.Cmp(transferred) != 0 {
+			t.Errorf("receipts[%d].FeeTransferred = %s, want %s", i, receipts[i].FeeTransferred, transferred)
+		}
 		if txs[i].To() != nil && receipts[i].ContractAddress != (common.Address{}) {
 			t.Errorf("receipts[%d].ContractAddress = %s, want %s", i, receipts[i].ContractAddress.String(), (common.Address{}).String())
 		}
@@ -481,6 +492,9 @@ func clearComputedFieldsOnReceipt(t *testing.T, receipt *Receipt) {
 	receipt.TransactionIndex = math.MaxUint32
 	receipt.ContractAddress = common.Address{}
 	receipt.GasUsed = 0
+	receipt.FeeSpent = nil
+	receipt.FeeTransferred = nil
+	receipt.FeeBurnt = nil
 
 	clearComputedFieldsOnLogs(t, receipt.Logs)
 }
@@ -56,12 +56,6 @@ type Transaction struct {
 	hash atomic.Value
 	size atomic.Value
 	from atomic.Value
-
-	// additional calculated fields
-	FeeSpent	     *big.Int // Amount of crypto spent by the caller as fees
-	FeeTransferred *big.Int // Amount of crypto received by the block sealer
-	FeeBurnt       *big.Int // Amount of crypto spent by the caller and burnt = FeeSpent - FeeTransferred
-
 }
 
 // NewTx creates a new transaction.
@@ -354,6 +348,20 @@ func (tx *Transaction) EffectiveGasTipCmp(other *Transaction, baseFee *big.Int)
 	return tx.EffectiveGasTipValue(baseFee).Cmp(other.EffectiveGasTipValue(baseFee))
 }
 
+// Fees returns the fees paid by the transaction for the given amount of used gas
+// in a block of the given base fee: the amount spent by the sender, the part
+// transferred to the block sealer and the part burnt.
+func (tx *Transaction) Fees(gasUsed uint64, baseFee *big.Int) (spent, transferred, burnt *big.Int) {
+	gas := new(big.Int).SetUint64(gasUsed)
+	transferred = new(big.Int).Mul(gas, tx.EffectiveGasTipValue(baseFee))
+	burnt = new(big.Int)
+	if baseFee != nil {
+		burnt.Mul(gas, baseFee)
+	}
+	spent = new(big.Int).Add(transferred, burnt)
+	return spent, transferred, burnt
+}
+
 // EffectiveGasTipIntCmp compares the effective gasTipCap of a transaction to the given gasTipCap.
 func (tx *Transaction) EffectiveGasTipIntCmp(other *big.Int, baseFee *big.Int) int {
 	if baseFee == nil {
@@ -0,0 +1,262 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package downloader
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/cliquepocr"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// newPoCRGenesis creates the genesis of a PoCR chain sealed by the test account
+// alone, with the contracts of a real network.
+func newPoCRGenesis(t *testing.T) *core.Genesis {
+	config := *params.AllCliqueProtocolChanges
+	// The built contracts are read from their storage, from the footprint storage fork on
+	config.Clique = &params.CliqueConfig{Period: 0, Epoch: 30000, PoCR: true, FootprintStorageBlock: new(big.Int)}
+
+	contracts := &cliquepocr.GenesisContracts{
+		Sealers: []cliquepocr.GenesisSealer{{Address: testAddress, Footprint: big.NewInt(1000)}},
+	}
+	alloc, err := contracts.Alloc()
+	if err != nil {
+		t.Fatalf("failed to build the PoCR contracts: %v", err)
+	}
+	alloc[testAddress] = core.GenesisAccount{Balance: big.NewInt(1000000000000000)}
+
+	return &core.Genesis{
+		Config:    &config,
+		ExtraData: contracts.ExtraData(),
+		Alloc:     alloc,
+		BaseFee:   big.NewInt(params.InitialBaseFee),
+	}
+}
+
+// newPoCRChain creates a PoCR chain of the given length, sealed by the test
+// account and transferring funds in every block.
+func newPoCRChain(t *testing.T, genesis *core.Genesis, length int) (*core.BlockChain, ethdb.Database) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		engine = cliquepocr.New(genesis.Config.Clique, db)
+		signer = types.LatestSigner(genesis.Config)
+		parent = genesis.MustCommit(db)
+	)
+	engine.Authorize(testAddress, nil)
+
+	chain, err := core.NewBlockChain(db, nil, genesis.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create the PoCR chain: %v", err)
+	}
+	// Generate the blocks one by one, so the engine finds the snapshot of the
+	// parent of the block it is finalizing
+	for i := 0; i < length; i++ {
+		blocks, _ := core.GenerateChain(genesis.Config, parent, engine, db, 1, func(_ int, block *core.BlockGen) {
+			block.SetDifficulty(big.NewInt(2))
+			block.SetExtra(make([]byte, 32+crypto.SignatureLength))
+
+			tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(testAddress), common.Address{0x01}, big.NewInt(1), params.TxGas, block.BaseFee(), nil), signer, testKey)
+			block.AddTx(tx)
+		})
+		header := blocks[0].Header()
+		sig, _ := crypto.Sign(engine.SealHash(header).Bytes(), testKey)
+		copy(header.Extra[len(header.Extra)-crypto.SignatureLength:], sig)
+		parent = blocks[0].WithSeal(header)
+
+		if _, err := chain.InsertChain(types.Blocks{parent}); err != nil {
+			t.Fatalf("failed to insert block %d: %v", parent.NumberU64(), err)
+		}
+	}
+	return chain, db
+}
+
+// newPoCRTester creates a downloader test mocker syncing a PoCR chain, with the
+// database the engine records the rewards of the processed blocks in.
+func newPoCRTester(t *testing.T, genesis *core.Genesis) (*downloadTester, ethdb.Database) {
+	freezer := t.TempDir()
+	db, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), freezer, "", false)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+	genesis.MustCommit(db)
+
+	chain, err := core.NewBlockChain(db, nil, genesis.Config, cliquepocr.New(genesis.Config.Clique, db), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	tester := &downloadTester{
+		freezer: freezer,
+		chain:   chain,
+		peers:   make(map[string]*downloadTesterPeer),
+	}
+	tester.downloader = New(0, db, new(event.TypeMux), tester.chain, nil, tester.dropPeer, nil)
+	return tester, db
+}
+
+// newChainPeer registers a download source serving an existing chain.
+func (dl *downloadTester) newChainPeer(id string, version uint, chain *core.BlockChain) {
+	dl.lock.Lock()
+	defer dl.lock.Unlock()
+
+	peer := &downloadTesterPeer{
+		dl:              dl,
+		id:              id,
+		chain:           chain,
+		withholdHeaders: make(map[common.Hash]struct{}),
+	}
+	dl.peers[id] = peer
+
+	if err := dl.downloader.RegisterPeer(id, version, peer); err != nil {
+		panic(err)
+	}
+	if err := dl.downloader.SnapSyncer.Register(peer); err != nil {
+		panic(err)
+	}
+}
+
+// proofFetcher returns a fetcher of the storage proofs of the state of a chain,
+// as served by eth_getProof.
+func proofFetcher(chain *core.BlockChain) cliquepocr.ProofFetcher {
+	return func(ctx context.Context, account common.Address, keys []common.Hash, block common.Hash) (*cliquepocr.AccountProof, error) {
+		statedb, err := chain.StateAt(chain.GetHeaderByHash(block).Root)
+		if err != nil {
+			return nil, err
+		}
+		nodes, err := statedb.GetProof(account)
+		if err != nil {
+			return nil, err
+		}
+		proof := &cliquepocr.AccountProof{
+			Address:     account,
+			StorageHash: statedb.StorageTrie(account).Hash(),
+		}
+		for _, node := range nodes {
+			proof.AccountProof = append(proof.AccountProof, node)
+		}
+		for _, key := range keys {
+			nodes, err := statedb.GetStorageProof(account, key)
+			if err != nil {
+				return nil, err
+			}
+			slot := cliquepocr.StorageProof{Key: key, Value: (*hexutil.Big)(statedb.GetState(account, key).Big())}
+			for _, node := range nodes {
+				slot.Proof = append(slot.Proof, node)
+			}
+			proof.StorageProof = append(proof.StorageProof, slot)
+		}
+		return proof, nil
+	}
+}
+
+// Tests that a PoCR chain can be synchronised: the blocks processed in full
+// record their rewards, the ones below the snap sync pivot and the headers of
+// the light sync are only checked against the clique rules, and the rewards of
+// all of them can still be verified with the proofs of the state of a full node.
+func TestPoCRSync66Full(t *testing.T)  { testPoCRSync(t, eth.ETH66, FullSync) }
+func TestPoCRSync66Snap(t *testing.T)  { testPoCRSync(t, eth.ETH66, SnapSync) }
+func TestPoCRSync66Light(t *testing.T) { testPoCRSync(t, eth.ETH66, LightSync) }
+func TestPoCRSync67Full(t *testing.T)  { testPoCRSync(t, eth.ETH67, FullSync) }
+func TestPoCRSync67Snap(t *testing.T)  { testPoCRSync(t, eth.ETH67, SnapSync) }
+func TestPoCRSync67Light(t *testing.T) { testPoCRSync(t, eth.ETH67, LightSync) }
+
+func testPoCRSync(t *testing.T, protocol uint, mode SyncMode) {
+	genesis := newPoCRGenesis(t)
+
+	source, sourceDb := newPoCRChain(t, genesis, 2*fsMinFullBlocks)
+	defer source.Stop()
+
+	tester, db := newPoCRTester(t, genesis)
+	defer tester.terminate()
+
+	tester.newChainPeer("peer", protocol, source)
+	if err := tester.sync("peer", nil, mode); err != nil {
+		t.Fatalf("failed to synchronise blocks: %v", err)
+	}
+	assertOwnChain(t, tester, 2*fsMinFullBlocks+1)
+
+	// Only the blocks processed in full have their rewards computed
+	var processed uint64
+	switch mode {
+	case FullSync:
+		processed = 1
+	case SnapSync:
+		pivot := rawdb.ReadLastPivotNumber(db)
+		if pivot == nil {
+			t.Fatalf("snap sync pivot missing")
+		}
+		processed = *pivot + 1
+
+		// The state of the pivot is the one of the source
+		if have, want := tester.chain.CurrentBlock().Root(), source.CurrentBlock().Root(); have != want {
+			t.Fatalf("head state root mismatch: have %x, want %x", have, want)
+		}
+		statedb, err := tester.chain.State()
+		if err != nil {
+			t.Fatalf("failed to retrieve the synced state: %v", err)
+		}
+		want, _ := source.State()
+		if have, want := statedb.GetBalance(testAddress), want.GetBalance(testAddress); have.Cmp(want) != 0 {
+			t.Errorf("sealer balance mismatch: have %v, want %v", have, want)
+		}
+	case LightSync:
+		processed = source.CurrentBlock().NumberU64() + 1
+	}
+	engine := tester.chain.Engine().(*cliquepocr.CliquePoCR)
+	fetch := proofFetcher(source)
+
+	for number := uint64(1); number <= source.CurrentBlock().NumberU64(); number++ {
+		header := tester.chain.GetHeaderByNumber(number)
+		want := rawdb.ReadPoCRReward(sourceDb, header.Hash(), number)
+		if want == nil {
+			t.Fatalf("block %d: source reward missing", number)
+		}
+		have := rawdb.ReadPoCRReward(db, header.Hash(), number)
+		switch {
+		case number < processed && have != nil:
+			t.Errorf("block %d: reward recorded without processing the block", number)
+		case number >= processed && have == nil:
+			t.Errorf("block %d: reward of the processed block missing", number)
+		case have != nil && have.BlockReward.Cmp(want.BlockReward) != 0:
+			t.Errorf("block %d: recorded reward mismatch: have %v, want %v", number, have.BlockReward, want.BlockReward)
+		}
+		proven, err := engine.ProvenRewards(context.Background(), tester.chain, header, nil, fetch)
+		if err != nil {
+			t.Fatalf("block %d: failed to prove rewards: %v", number, err)
+		}
+		if proven.BlockReward.ToInt().Cmp(want.BlockReward) != 0 {
+			t.Errorf("block %d: proven reward mismatch: have %v, want %v", number, proven.BlockReward, want.BlockReward)
+		}
+		if proven.TotalCrypto.ToInt().Cmp(want.TotalCryptoBefore) != 0 {
+			t.Errorf("block %d: proven total crypto mismatch: have %v, want %v", number, proven.TotalCrypto, want.TotalCryptoBefore)
+		}
+	}
+}
@@ -154,6 +154,10 @@ type Config struct {
 	LightNoSyncServe   bool `toml:",omitempty"` // Whether to serve light clients before syncing
 	SyncFromCheckpoint bool `toml:",omitempty"` // Whether to sync the header chain from the configured checkpoint
 
+	// Endpoint of the node serving the proofs the light client verifies the
+	// PoCR rewards with
+	LightPoCRProofs string `toml:",omitempty"`
+
 	// Ultra Light client options
 	UltraLightServers      []string `toml:",omitempty"` // List of trusted ultra light servers
 	UltraLightFraction     int      `toml:",omitempty"` // Percentage of trusted servers to accept an announcement
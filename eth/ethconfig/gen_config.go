@@ -34,6 +34,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		LightNoPrune                          bool                   `toml:",omitempty"`
 		LightNoSyncServe                      bool                   `toml:",omitempty"`
 		SyncFromCheckpoint                    bool                   `toml:",omitempty"`
+		LightPoCRProofs                       string                 `toml:",omitempty"`
 		UltraLightServers                     []string               `toml:",omitempty"`
 		UltraLightFraction                    int                    `toml:",omitempty"`
 		UltraLightOnlyAnnounce                bool                   `toml:",omitempty"`
@@ -80,6 +81,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.LightNoPrune = c.LightNoPrune
 	enc.LightNoSyncServe = c.LightNoSyncServe
 	enc.SyncFromCheckpoint = c.SyncFromCheckpoint
+	enc.LightPoCRProofs = c.LightPoCRProofs
 	enc.UltraLightServers = c.UltraLightServers
 	enc.UltraLightFraction = c.UltraLightFraction
 	enc.UltraLightOnlyAnnounce = c.UltraLightOnlyAnnounce
@@ -130,6 +132,7 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		LightNoPrune                          *bool                  `toml:",omitempty"`
 		LightNoSyncServe                      *bool                  `toml:",omitempty"`
 		SyncFromCheckpoint                    *bool                  `toml:",omitempty"`
+		LightPoCRProofs                       *string                `toml:",omitempty"`
 		UltraLightServers                     []string               `toml:",omitempty"`
 		UltraLightFraction                    *int                   `toml:",omitempty"`
 		UltraLightOnlyAnnounce                *bool                  `toml:",omitempty"`
@@ -211,6 +214,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.SyncFromCheckpoint != nil {
 		c.SyncFromCheckpoint = *dec.SyncFromCheckpoint
 	}
+	if dec.LightPoCRProofs != nil {
+		c.LightPoCRProofs = *dec.LightPoCRProofs
+	}
 	if dec.UltraLightServers != nil {
 		c.UltraLightServers = dec.UltraLightServers
 	}
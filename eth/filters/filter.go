@@ -23,6 +23,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/bloombits"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -37,6 +38,8 @@ type Filter struct {
 	block      *common.Hash // Block hash if filtering a single block
 	begin, end int64        // Range interval if filtering multiple blocks
 
+	pocrLogs bool // Whether the criteria may match the PoCR system logs, missing from the blooms
+
 	matcher *bloombits.Matcher
 }
 
@@ -89,6 +92,7 @@ func newFilter(sys *FilterSystem, addresses []common.Address, topics [][]common.
 		sys:       sys,
 		addresses: addresses,
 		topics:    topics,
+		pocrLogs:  sys.pocrChain() && mayMatchPoCRLogs(addresses, topics),
 	}
 }
 
@@ -180,11 +184,23 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, err
 			// Abort if all matches have been fulfilled
 			if !ok {
 				err := session.Error()
+				if err == nil {
+					var skipped []*types.Log
+					skipped, err = f.pocrSystemLogs(ctx, uint64(f.begin), end+1)
+					logs = append(logs, skipped...)
+				}
 				if err == nil {
 					f.begin = int64(end) + 1
 				}
 				return logs, err
 			}
+			// The bloom bits skipped the blocks since the last match, they may
+			// still have matching PoCR system logs
+			skipped, err := f.pocrSystemLogs(ctx, uint64(f.begin), number)
+			logs = append(logs, skipped...)
+			if err != nil {
+				return logs, err
+			}
 			f.begin = int64(number) + 1
 
 			// Retrieve the suggested block and pull any truly matching logs
@@ -234,10 +250,51 @@ func (f *Filter) blockLogs(ctx context.Context, header *types.Header, skipBloom
 		return flatten(list), nil
 	} else if skipBloom || bloomFilter(header.Bloom, f.addresses, f.topics) {
 		return f.checkMatches(ctx, header)
+	} else if f.pocrLogs && f.pocrLogsMatch(header.Hash(), header.Number.Uint64()) {
+		return f.checkMatches(ctx, header)
 	}
 	return nil, nil
 }
 
+// pocrSystemLogs returns the logs matching the filter criteria of the canonical
+// blocks from begin to end (excluded) whose PoCR system logs match, the bloom
+// bits having skipped these blocks.
+func (f *Filter) pocrSystemLogs(ctx context.Context, begin, end uint64) ([]*types.Log, error) {
+	if !f.pocrLogs {
+		return nil, nil
+	}
+	var (
+		logs []*types.Log
+		db   = f.sys.backend.ChainDb()
+	)
+	for number := begin; number < end; number++ {
+		hash := rawdb.ReadCanonicalHash(db, number)
+		if hash == (common.Hash{}) || !f.pocrLogsMatch(hash, number) {
+			continue
+		}
+		header, err := f.sys.backend.HeaderByHash(ctx, hash)
+		if header == nil || err != nil {
+			return logs, err
+		}
+		found, err := f.checkMatches(ctx, header)
+		if err != nil {
+			return logs, err
+		}
+		logs = append(logs, found...)
+	}
+	return logs, nil
+}
+
+// pocrLogsMatch returns whether the PoCR system logs of a block, derived from its
+// stored reward record, match the filter criteria.
+func (f *Filter) pocrLogsMatch(hash common.Hash, number uint64) bool {
+	reward := rawdb.ReadPoCRReward(f.sys.backend.ChainDb(), hash, number)
+	if reward == nil {
+		return false
+	}
+	return len(filterLogs(reward.Logs(hash, number, 0, 0), nil, nil, f.addresses, f.topics)) > 0
+}
+
 // checkMatches checks if the receipts belonging to the given header contain any log events that
 // match the filter criteria. This function is called when the bloom filter signals a potential match.
 func (f *Filter) checkMatches(ctx context.Context, header *types.Header) ([]*types.Log, error) {
@@ -325,6 +382,28 @@ Logs:
 	return ret
 }
 
+// mayMatchPoCRLogs returns whether the criteria may match the PoCR system logs.
+// These logs are not part of the header blooms, the blocks having a PoCR reward
+// record must be checked regardless of their bloom.
+func mayMatchPoCRLogs(addresses []common.Address, topics [][]common.Hash) bool {
+	if len(addresses) > 0 && !includes(addresses, types.PoCRLogAddress) {
+		return false
+	}
+	// The system logs have two topics, the event id and the sealer
+	if len(topics) > 2 {
+		return false
+	}
+	if len(topics) > 0 && len(topics[0]) > 0 {
+		for _, topic := range topics[0] {
+			if topic == types.RewardMintedTopic || topic == types.FeeAdjustedTopic {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
 func bloomFilter(bloom types.Bloom, addresses []common.Address, topics [][]common.Hash) bool {
 	if len(addresses) > 0 {
 		var included bool
@@ -76,6 +76,9 @@ type FilterSystem struct {
 	backend   Backend
 	logsCache *lru.Cache
 	cfg       *Config
+
+	pocrOnce sync.Once // Reads the chain config once for the pocr flag
+	pocr     bool      // Whether the chain is sealed by the PoCR engine
 }
 
 // NewFilterSystem creates a filter system.
@@ -111,6 +114,17 @@ func (sys *FilterSystem) cachedGetLogs(ctx context.Context, blockHash common.Has
 	return logs, nil
 }
 
+// pocrChain returns whether the chain is sealed by the PoCR engine, and so may
+// have PoCR system logs.
+func (sys *FilterSystem) pocrChain() bool {
+	sys.pocrOnce.Do(func() {
+		db := sys.backend.ChainDb()
+		config := rawdb.ReadChainConfig(db, rawdb.ReadCanonicalHash(db, 0))
+		sys.pocr = config != nil && config.Clique != nil && config.Clique.PoCR
+	})
+	return sys.pocr
+}
+
 // Type determines the kind of filter and is used to put the filter in to
 // the correct bucket when added.
 type Type byte
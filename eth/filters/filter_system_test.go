@@ -28,6 +28,7 @@ import (
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/bitutil"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
@@ -89,7 +90,7 @@ func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.
 
 func (b *testBackend) GetLogs(ctx context.Context, hash common.Hash, number uint64) ([][]*types.Log, error) {
 	logs := rawdb.ReadLogs(b.db, hash, number, params.TestChainConfig)
-	return logs, nil
+	return rawdb.AppendPoCRLogs(b.db, hash, number, logs), nil
 }
 
 func (b *testBackend) PendingBlockAndReceipts() (*types.Block, types.Receipts) {
@@ -138,7 +139,9 @@ func (b *testBackend) ServiceFilter(ctx context.Context, session *bloombits.Matc
 				for i, section := range task.Sections {
 					if rand.Int()%4 != 0 { // Handle occasional missing deliveries
 						head := rawdb.ReadCanonicalHash(b.db, (section+1)*params.BloomBitsBlocks-1)
-						task.Bitsets[i], _ = rawdb.ReadBloomBits(b.db, task.Bit, section, head)
+						if compVector, err := rawdb.ReadBloomBits(b.db, task.Bit, section, head); err == nil {
+							task.Bitsets[i], _ = bitutil.DecompressBytes(compVector, int(params.BloomBitsBlocks/8))
+						}
 					}
 				}
 				request <- task
@@ -17,13 +17,16 @@
 package filters
 
 import (
+	"bytes"
 	"context"
 	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/bitutil"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -230,3 +233,129 @@ func TestFilters(t *testing.T) {
 		t.Error("expected 0 log, got", len(logs))
 	}
 }
+
+// Tests that the PoCR system logs derived from the reward records are found by
+// the filters, although they are not part of the header blooms.
+func TestPoCRFilters(t *testing.T) {
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(t, db, Config{})
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key1.PublicKey)
+		sealer  = common.HexToAddress("0x5ea1e5")
+		topic   = common.BytesToHash([]byte("topic"))
+
+		config = *params.TestChainConfig
+		gspec  = core.Genesis{
+			Config:    &config,
+			ExtraData: append(append(make([]byte, 32), sealer.Bytes()...), make([]byte, crypto.SignatureLength)...),
+			Alloc:     core.GenesisAlloc{addr: {Balance: big.NewInt(1000000)}},
+			BaseFee:   big.NewInt(params.InitialBaseFee),
+		}
+	)
+	config.Clique = &params.CliqueConfig{Period: 4, Epoch: 30000, PoCR: true}
+	genesis := gspec.MustCommit(db)
+
+	chain, receipts := core.GenerateChain(&config, genesis, ethash.NewFaker(), db, 10, func(i int, gen *core.BlockGen) {
+		if i == 4 {
+			receipt := types.NewReceipt(nil, false, 0)
+			receipt.Logs = []*types.Log{{Address: addr, Topics: []common.Hash{topic}}}
+			gen.AddUncheckedReceipt(receipt)
+			gen.AddUncheckedTx(types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, gen.BaseFee(), nil))
+		}
+	})
+	rewards := map[uint64]*types.PoCRReward{
+		5: {Author: sealer, Rank: big.NewRat(1, 2), BlockReward: big.NewInt(1000), FeeAdjustment: big.NewInt(-3)},
+		6: {Author: sealer, Rank: big.NewRat(1, 1), BlockReward: big.NewInt(2000), FeeAdjustment: new(big.Int)},
+		7: {Author: sealer, Rank: new(big.Rat), BlockReward: new(big.Int), FeeAdjustment: big.NewInt(5)},
+	}
+	for i, block := range chain {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(db, block.Hash())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+		if reward := rewards[block.NumberU64()]; reward != nil {
+			rawdb.WritePoCRReward(db, block.Hash(), block.NumberU64(), reward)
+		}
+	}
+	// Index the blooms of the chain in a single section, the blocks after the
+	// head having empty blooms
+	gen, err := bloombits.NewGenerator(uint(params.BloomBitsBlocks))
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+	gen.AddBloom(0, genesis.Bloom())
+	for i := uint(1); i < uint(params.BloomBitsBlocks); i++ {
+		var bloom types.Bloom
+		if int(i) <= len(chain) {
+			bloom = chain[i-1].Bloom()
+		}
+		gen.AddBloom(i, bloom)
+	}
+	for i := 0; i < types.BloomBitLength; i++ {
+		data, err := gen.Bitset(uint(i))
+		if err != nil {
+			t.Fatalf("failed to retrieve bitset: %v", err)
+		}
+		rawdb.WriteBloomBits(db, uint(i), 0, rawdb.ReadCanonicalHash(db, params.BloomBitsBlocks-1), bitutil.CompressBytes(data))
+	}
+	sealerTopic := common.BytesToHash(sealer.Bytes())
+
+	testCases := []struct {
+		addresses []common.Address
+		topics    [][]common.Hash
+		want      []uint64 // block numbers of the matching logs
+	}{
+		{[]common.Address{types.PoCRLogAddress}, nil, []uint64{5, 5, 6, 7}},
+		{nil, [][]common.Hash{{types.RewardMintedTopic}}, []uint64{5, 6}},
+		{nil, [][]common.Hash{{types.FeeAdjustedTopic}, {sealerTopic}}, []uint64{5, 7}},
+		{nil, [][]common.Hash{nil, {common.Hash{0x01}}}, nil},
+		{nil, [][]common.Hash{{topic}}, []uint64{5}},
+		{[]common.Address{addr}, nil, []uint64{5}},
+	}
+	// Search the blocks one by one, then with the bloom bits index
+	for _, sections := range []uint64{0, 1} {
+		backend.sections = sections
+		for i, tc := range testCases {
+			logs, err := sys.NewRangeFilter(0, -1, tc.addresses, tc.topics).Logs(context.Background())
+			if err != nil {
+				t.Fatalf("sections %d, test %d: filter failed: %v", sections, i, err)
+			}
+			var have []uint64
+			for _, log := range logs {
+				have = append(have, log.BlockNumber)
+			}
+			if len(have) != len(tc.want) {
+				t.Errorf("sections %d, test %d: matching blocks mismatch: have %v, want %v", sections, i, have, tc.want)
+				continue
+			}
+			for j := range have {
+				if have[j] != tc.want[j] {
+					t.Errorf("sections %d, test %d: matching blocks mismatch: have %v, want %v", sections, i, have, tc.want)
+					break
+				}
+			}
+		}
+	}
+	// The system logs follow the transactions of the block and their logs
+	logs, err := sys.NewBlockFilter(chain[4].Hash(), nil, nil).Logs(context.Background())
+	if err != nil {
+		t.Fatalf("block filter failed: %v", err)
+	}
+	if len(logs) != 3 {
+		t.Fatalf("block logs mismatch: have %d, want 3", len(logs))
+	}
+	minted, adjusted := logs[1], logs[2]
+	if minted.TxIndex != 1 || minted.Index != 1 || adjusted.TxIndex != 1 || adjusted.Index != 2 {
+		t.Errorf("system log position mismatch: have (%d, %d) and (%d, %d), want (1, 1) and (1, 2)", minted.TxIndex, minted.Index, adjusted.TxIndex, adjusted.Index)
+	}
+	if minted.TxHash != types.PoCRLogTxHash(chain[4].Hash()) || minted.TxHash != adjusted.TxHash {
+		t.Errorf("system log transaction hash mismatch: have %x and %x", minted.TxHash, adjusted.TxHash)
+	}
+	if want := common.FromHex("0x00000000000000000000000000000000000000000000000000000000000003e800000000000000000000000000000000000000000000000006f05b59d3b20000"); !bytes.Equal(minted.Data, want) {
+		t.Errorf("RewardMinted data mismatch: have %x, want %x", minted.Data, want)
+	}
+	if want := common.FromHex("0xfffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffd"); !bytes.Equal(adjusted.Data, want) {
+		t.Errorf("FeeAdjusted data mismatch: have %x, want %x", adjusted.Data, want)
+	}
+}
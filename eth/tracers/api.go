@@ -34,6 +34,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/systemcontracts"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/eth/tracers/logger"
@@ -274,8 +275,17 @@ func (api *API) traceChain(ctx context.Context, start, end *types.Block, config
 			for task := range tasks {
 				signer := types.MakeSigner(api.backend.ChainConfig(), task.block.Number())
 				blockCtx := core.NewEVMBlockContext(task.block.Header(), api.chainContext(localctx), nil)
+				// The system contract upgrades of the block run before its transactions
+				txs := task.block.Transactions()
+				if err := systemcontracts.UpgradeBuildInSystemContract(api.backend.ChainConfig(), task.block.Number(), task.statedb); err != nil {
+					log.Warn("Upgrading system contracts failed", "block", task.block.NumberU64(), "err", err)
+					for i := range txs {
+						task.results[i] = &txTraceResult{Error: err.Error()}
+					}
+					txs = nil
+				}
 				// Trace all the transactions contained within
-				for i, tx := range task.block.Transactions() {
+				for i, tx := range txs {
 					msg, _ := tx.AsMessage(signer, task.block.BaseFee())
 					txctx := &Context{
 						BlockHash: task.block.Hash(),
@@ -519,6 +529,10 @@ func (api *API) IntermediateRoots(ctx context.Context, hash common.Hash, config
 	if err != nil {
 		return nil, err
 	}
+	// The system contract upgrades of the block run before its transactions
+	if err := systemcontracts.UpgradeBuildInSystemContract(api.backend.ChainConfig(), block.Number(), statedb); err != nil {
+		return nil, err
+	}
 	var (
 		roots              []common.Hash
 		signer             = types.MakeSigner(api.backend.ChainConfig(), block.Number())
@@ -581,6 +595,10 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 	if err != nil {
 		return nil, err
 	}
+	// The system contract upgrades of the block run before its transactions
+	if err := systemcontracts.UpgradeBuildInSystemContract(api.backend.ChainConfig(), block.Number(), statedb); err != nil {
+		return nil, err
+	}
 	// Execute all the transaction contained within the block concurrently
 	var (
 		signer  = types.MakeSigner(api.backend.ChainConfig(), block.Number())
@@ -671,6 +689,10 @@ func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block
 	if err != nil {
 		return nil, err
 	}
+	// The system contract upgrades of the block run before its transactions
+	if err := systemcontracts.UpgradeBuildInSystemContract(api.backend.ChainConfig(), block.Number(), statedb); err != nil {
+		return nil, err
+	}
 	// Retrieve the tracing configurations, or use default values
 	var (
 		logConfig logger.Config
@@ -881,6 +903,13 @@ func (api *API) traceTx(ctx context.Context, message core.Message, txctx *Contex
 	if config == nil {
 		config = &TraceConfig{}
 	}
+	// The rank of the sealer of a PoCR block is the one recorded by the engine
+	// when the node processed the block
+	if txctx.PoCRRank == nil && txctx.BlockHash != (common.Hash{}) {
+		if reward := rawdb.ReadPoCRReward(api.backend.ChainDb(), txctx.BlockHash, vmctx.BlockNumber.Uint64()); reward != nil {
+			txctx.PoCRRank = reward.Rank
+		}
+	}
 	// Default tracer is the struct logger
 	tracer = logger.NewStructLogger(config.Config)
 	if config.Tracer != nil {
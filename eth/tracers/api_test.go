@@ -36,6 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/systemcontracts"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -420,6 +421,81 @@ func TestTraceBlock(t *testing.T) {
 	}
 }
 
+// Tests that all the transactions of a block whose system contract upgrades
+// fail are reported with the upgrade error when tracing a chain.
+func TestTraceChainUpgradeFailure(t *testing.T) {
+	t.Parallel()
+
+	accounts := newAccounts(2)
+	genesis := &core.Genesis{Alloc: core.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+	}}
+	signer := types.HomesteadSigner{}
+	backend := newTestBackend(t, 3, genesis, func(i int, b *core.BlockGen) {
+		for j := 0; j < 2; j++ {
+			tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(accounts[0].addr), accounts[1].addr, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, accounts[0].key)
+			b.AddTx(tx)
+		}
+	})
+	// The upgrade is scheduled once the chain is imported, so only the tracer
+	// runs it
+	config := *backend.chainConfig
+	config.ChainID = big.NewInt(0x7ace)
+	config.SystemContractUpgrades = []params.SystemContractUpgrade{{Name: "failing", Block: big.NewInt(2)}}
+	backend.chainConfig = &config
+	systemcontracts.RegisterUpgrade(config.ChainID.Uint64(), &systemcontracts.Upgrade{
+		UpgradeName: "failing",
+		Configs: []*systemcontracts.UpgradeConfig{{
+			ContractAddr: common.Address{0x01},
+			BeforeUpgrade: func(*big.Int, common.Address, *state.StateDB) error {
+				return errors.New("migration failed")
+			},
+		}},
+	})
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("debug", NewAPI(backend)); err != nil {
+		t.Fatalf("failed to register the tracing API: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	results := make(chan *blockTraceResult)
+	sub, err := client.Subscribe(context.Background(), "debug", results, "traceChain", rpc.BlockNumber(0), rpc.BlockNumber(3))
+	if err != nil {
+		t.Fatalf("failed to trace the chain: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	for number := uint64(1); number <= 3; number++ {
+		select {
+		case result := <-results:
+			if uint64(result.Block) != number {
+				t.Fatalf("block mismatch: have %d, want %d", result.Block, number)
+			}
+			if len(result.Traces) != 2 {
+				t.Fatalf("block %d: trace count mismatch: have %d, want 2", number, len(result.Traces))
+			}
+			for i, trace := range result.Traces {
+				if trace == nil {
+					t.Errorf("block %d, tx %d: trace missing", number, i)
+					continue
+				}
+				if number == 2 && trace.Error != "upgrade failing of contract 0100000000000000000000000000000000000000: before upgrade: migration failed" {
+					t.Errorf("block %d, tx %d: error mismatch: have %q", number, i, trace.Error)
+				}
+				if number != 2 && (trace.Error != "" || trace.Result == nil) {
+					t.Errorf("block %d, tx %d: trace failed: %q", number, i, trace.Error)
+				}
+			}
+		case err := <-sub.Err():
+			t.Fatalf("chain tracing failed: %v", err)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("block %d: trace not received", number)
+		}
+	}
+}
+
 func TestTracingWithOverrides(t *testing.T) {
 	t.Parallel()
 	// Initialize test accounts
@@ -0,0 +1,175 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+)
+
+func init() {
+	register("pocrFeeTracer", newPoCRFeeTracer)
+}
+
+// pocrRankPrecision is the number of decimals of the reported rank, the same as
+// the PoCR API.
+const pocrRankPrecision = 18
+
+type pocrFeeResult struct {
+	Sealer         common.Address `json:"sealer"`
+	GasUsed        hexutil.Uint64 `json:"gasUsed"`
+	EffectiveTip   *hexutil.Big   `json:"effectiveTip"`             // Tip paid per gas to the sealer
+	FeeSpent       *hexutil.Big   `json:"feeSpent"`                 // Fees paid by the sender
+	FeeTransferred *hexutil.Big   `json:"feeTransferred"`           // Fees received by the sealer, before the PoCR adjustment
+	FeeBurnt       *hexutil.Big   `json:"feeBurnt"`                 // Fees burnt by the EIP-1559
+	Rank           string         `json:"rank,omitempty"`           // Rank of the sealer as a decimal value between 0 and 1
+	FeeKept        *hexutil.Big   `json:"feeKept,omitempty"`        // Share of the received fees the sealer keeps for its rank
+	FeeConfiscated *hexutil.Big   `json:"feeConfiscated,omitempty"` // Share of the received fees removed from the sealer for its rank
+}
+
+// pocrFeeTracer is a go implementation of the Tracer interface which reports
+// where the fees of a transaction go on a PoCR chain: the tip received by the
+// sealer, the fees burnt by the EIP-1559 and the share of the tip the sealer
+// loses to its rank.
+//
+// The rank is the one the engine recorded when the node processed the block,
+// it is left out for the blocks the node did not process and for the calls.
+// The engine adjusts the fees of a whole block at once: as the kept shares are
+// rounded down, the confiscated shares of the transactions may add up to less
+// than a wei per transaction more than the adjustment of the block.
+//
+// Example:
+//   > debug.traceTransaction("0x214e597e35da083692f5386141e69f47e973b2c56e7a8073b1ea08fd7571e9de", {tracer: "pocrFeeTracer"})
+//   {
+//     sealer: "0x71562b71999873db5b286df957af199ec94617f7",
+//     gasUsed: "0x5208",
+//     effectiveTip: "0x3b9aca00",
+//     feeSpent: "0x2632e314a000",
+//     feeTransferred: "0x1319718a5000",
+//     feeBurnt: "0x1319718a5000",
+//     rank: "0.250000000000000000",
+//     feeKept: "0x4c65c629400",
+//     feeConfiscated: "0xe531527bc00"
+//   }
+type pocrFeeTracer struct {
+	env      *vm.EVM
+	rank     *big.Rat
+	gasLimit uint64
+	gasUsed  uint64
+	reason   error // Textual reason for the interruption
+}
+
+// newPoCRFeeTracer returns a new PoCR fee tracer.
+func newPoCRFeeTracer(ctx *tracers.Context, _ json.RawMessage) (tracers.Tracer, error) {
+	t := new(pocrFeeTracer)
+	if ctx != nil {
+		t.rank = ctx.PoCRRank
+	}
+	return t, nil
+}
+
+// CaptureStart implements the EVMLogger interface to initialize the tracing operation.
+func (t *pocrFeeTracer) CaptureStart(env *vm.EVM, _ common.Address, _ common.Address, _ bool, _ []byte, _ uint64, _ *big.Int) {
+	t.env = env
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *pocrFeeTracer) CaptureEnd(_ []byte, _ uint64, _ time.Duration, _ error) {
+}
+
+// CaptureState implements the EVMLogger interface to trace a single step of VM execution.
+func (t *pocrFeeTracer) CaptureState(_ uint64, _ vm.OpCode, _, _ uint64, _ *vm.ScopeContext, _ []byte, _ int, _ error) {
+}
+
+// CaptureFault implements the EVMLogger interface to trace an execution fault.
+func (t *pocrFeeTracer) CaptureFault(_ uint64, _ vm.OpCode, _, _ uint64, _ *vm.ScopeContext, _ int, _ error) {
+}
+
+// CaptureEnter is called when EVM enters a new scope (via call, create or selfdestruct).
+func (t *pocrFeeTracer) CaptureEnter(_ vm.OpCode, _ common.Address, _ common.Address, _ []byte, _ uint64, _ *big.Int) {
+}
+
+// CaptureExit is called when EVM exits a scope, even if the scope didn't
+// execute any code.
+func (t *pocrFeeTracer) CaptureExit(_ []byte, _ uint64, _ error) {
+}
+
+func (t *pocrFeeTracer) CaptureTxStart(gasLimit uint64) {
+	t.gasLimit = gasLimit
+}
+
+// CaptureTxEnd records the gas used once the refund is applied, which is the
+// gas the fees are paid for.
+func (t *pocrFeeTracer) CaptureTxEnd(restGas uint64) {
+	t.gasUsed = t.gasLimit - restGas
+}
+
+// GetResult returns the json-encoded fees of the transaction, computed as the
+// state transition does.
+func (t *pocrFeeTracer) GetResult() (json.RawMessage, error) {
+	if t.env == nil {
+		return json.RawMessage(`{}`), nil
+	}
+	var (
+		gasPrice = t.env.TxContext.GasPrice
+		tip      = new(big.Int).Set(gasPrice)
+	)
+	if t.env.Context.BaseFee != nil && t.env.ChainConfig().IsLondon(t.env.Context.BlockNumber) {
+		tip.Sub(tip, t.env.Context.BaseFee)
+	}
+	// The calls without fees (NoBaseFee) pay nothing
+	if gasPrice.Sign() == 0 || tip.Sign() < 0 {
+		gasPrice, tip = new(big.Int), new(big.Int)
+	}
+	var (
+		gasUsed  = new(big.Int).SetUint64(t.gasUsed)
+		spent    = new(big.Int).Mul(gasUsed, gasPrice)
+		received = new(big.Int).Mul(gasUsed, tip)
+		res      = pocrFeeResult{
+			Sealer:         t.env.Context.Coinbase,
+			GasUsed:        hexutil.Uint64(t.gasUsed),
+			EffectiveTip:   (*hexutil.Big)(tip),
+			FeeSpent:       (*hexutil.Big)(spent),
+			FeeTransferred: (*hexutil.Big)(received),
+			FeeBurnt:       (*hexutil.Big)(new(big.Int).Sub(spent, received)),
+		}
+	)
+	if t.rank != nil {
+		kept := new(big.Int).Mul(received, t.rank.Num())
+		kept.Div(kept, t.rank.Denom())
+
+		res.Rank = t.rank.FloatString(pocrRankPrecision)
+		res.FeeKept = (*hexutil.Big)(kept)
+		res.FeeConfiscated = (*hexutil.Big)(new(big.Int).Sub(received, kept))
+	}
+	out, err := json.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+	return out, t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *pocrFeeTracer) Stop(err error) {
+	t.reason = err
+}
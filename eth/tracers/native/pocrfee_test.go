@@ -0,0 +1,114 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the PoCR fee tracer reports the fees the state transition pays, and
+// the share of the tip the sealer loses to its rank.
+func TestPoCRFeeTracer(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("0000000000000000deadbeef00000000000000000000000000000000deadbeef")
+		sender  = crypto.PubkeyToAddress(key.PublicKey)
+		sealer  = common.HexToAddress("0x71562b71999873db5b286df957af199ec94617f7")
+		baseFee = big.NewInt(params.GWei)
+		signer  = types.LatestSigner(params.AllCliqueProtocolChanges)
+	)
+	tx, _ := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+		ChainID:   params.AllCliqueProtocolChanges.ChainID,
+		GasTipCap: big.NewInt(params.GWei),
+		GasFeeCap: big.NewInt(3 * params.GWei),
+		Gas:       params.TxGas,
+		To:        &common.Address{0x01},
+		Value:     big.NewInt(1),
+	})
+	tests := []struct {
+		name string
+		rank *big.Rat
+		want string
+	}{
+		{
+			name: "ranked",
+			rank: big.NewRat(1, 4),
+			want: `{"sealer":"0x71562b71999873db5b286df957af199ec94617f7","gasUsed":"0x5208","effectiveTip":"0x3b9aca00","feeSpent":"0x2632e314a000","feeTransferred":"0x1319718a5000","feeBurnt":"0x1319718a5000","rank":"0.250000000000000000","feeKept":"0x4c65c629400","feeConfiscated":"0xe531527bc00"}`,
+		},
+		{
+			name: "unknown rank",
+			want: `{"sealer":"0x71562b71999873db5b286df957af199ec94617f7","gasUsed":"0x5208","effectiveTip":"0x3b9aca00","feeSpent":"0x2632e314a000","feeTransferred":"0x1319718a5000","feeBurnt":"0x1319718a5000"}`,
+		},
+	}
+	for _, tt := range tests {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.SetBalance(sender, big.NewInt(params.Ether))
+
+		tracer, err := tracers.New("pocrFeeTracer", &tracers.Context{PoCRRank: tt.rank}, nil)
+		if err != nil {
+			t.Fatalf("%s: failed to create tracer: %v", tt.name, err)
+		}
+		context := vm.BlockContext{
+			CanTransfer: core.CanTransfer,
+			Transfer:    core.Transfer,
+			Coinbase:    sealer,
+			BlockNumber: big.NewInt(1),
+			Time:        big.NewInt(5),
+			Difficulty:  big.NewInt(2),
+			GasLimit:    params.GenesisGasLimit,
+			BaseFee:     baseFee,
+		}
+		msg, err := tx.AsMessage(signer, baseFee)
+		if err != nil {
+			t.Fatalf("%s: failed to prepare transaction for tracing: %v", tt.name, err)
+		}
+		evm := vm.NewEVM(context, core.NewEVMTxContext(msg), statedb, params.AllCliqueProtocolChanges, vm.Config{Debug: true, Tracer: tracer})
+		result, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(tx.Gas()), nil)
+		if err != nil {
+			t.Fatalf("%s: failed to execute transaction: %v", tt.name, err)
+		}
+		res, err := tracer.GetResult()
+		if err != nil {
+			t.Fatalf("%s: failed to retrieve trace result: %v", tt.name, err)
+		}
+		if string(res) != tt.want {
+			t.Errorf("%s: trace mismatch:\nhave %s\nwant %s", tt.name, res, tt.want)
+		}
+		// The reported fees are the ones the state transition paid
+		var have pocrFeeResult
+		if err := json.Unmarshal(res, &have); err != nil {
+			t.Fatalf("%s: failed to decode trace result: %v", tt.name, err)
+		}
+		if have.FeeTransferred.ToInt().Cmp(result.FeeTransferred) != 0 || have.FeeBurnt.ToInt().Cmp(result.FeeBurnt) != 0 {
+			t.Errorf("%s: fees mismatch: have %v/%v, want %v/%v", tt.name, have.FeeTransferred, have.FeeBurnt, result.FeeTransferred, result.FeeBurnt)
+		}
+		if balance := statedb.GetBalance(sealer); balance.Cmp(result.FeeTransferred) != 0 {
+			t.Errorf("%s: sealer balance mismatch: have %v, want %v", tt.name, balance, result.FeeTransferred)
+		}
+	}
+}
@@ -20,6 +20,7 @@ package tracers
 import (
 	"encoding/json"
 	"errors"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -31,6 +32,7 @@ type Context struct {
 	BlockHash common.Hash // Hash of the block the tx is contained within (zero if dangling tx or call)
 	TxIndex   int         // Index of the transaction within a block (zero if dangling tx or call)
 	TxHash    common.Hash // Hash of the transaction being traced (zero if dangling call)
+	PoCRRank  *big.Rat    // Rank of the sealer of the PoCR block the tx is contained within (nil if unknown)
 }
 
 // Tracer interface extends vm.EVMLogger and additionally
@@ -34,15 +34,21 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/cliquepocr"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	ethproto "github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/les"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/miner"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/gorilla/websocket"
 )
@@ -57,6 +63,8 @@ const (
 	txChanSize = 4096
 	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
 	chainHeadChanSize = 10
+	// pocrRankPrecision is the number of decimals of the reported PoCR ranks.
+	pocrRankPrecision = 18
 )
 
 // backend encompasses the bare-minimum functionality needed for ethstats reporting
@@ -78,6 +86,16 @@ type fullNodeBackend interface {
 	BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error)
 	CurrentBlock() *types.Block
 	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	ChainConfig() *params.ChainConfig
+	ChainDb() ethdb.Database
+	StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, error)
+}
+
+// pocrEngine is the proof-of-carbon-reduction consensus engine, ranking the
+// sealers by their carbon footprint.
+type pocrEngine interface {
+	Signer() common.Address
+	SealerStanding(config *params.ChainConfig, header *types.Header, statedb *state.StateDB, sealer common.Address) (*cliquepocr.SignerRanking, error)
 }
 
 // Service implements an Ethereum netstats reporting daemon that pushes local
@@ -576,6 +594,15 @@ type blockStats struct {
 	TxHash     common.Hash    `json:"transactionsRoot"`
 	Root       common.Hash    `json:"stateRoot"`
 	Uncles     uncleStats     `json:"uncles"`
+	PoCR       *blockPoCR     `json:"pocr,omitempty"`
+}
+
+// blockPoCR is the proof-of-carbon-reduction reward earned by the miner of a
+// block, reported on the PoCR chains only.
+type blockPoCR struct {
+	Rank          string   `json:"rank"`          // Rank of the miner as a decimal value between 0 and 1
+	Reward        *big.Int `json:"reward"`        // Reward minted for the miner
+	FeeAdjustment *big.Int `json:"feeAdjustment"` // Fees added to (positive) or removed from (negative) the miner
 }
 
 // txStats is the information to report about individual transactions.
@@ -621,6 +648,7 @@ func (s *Service) assembleBlockStats(block *types.Block) *blockStats {
 		td     *big.Int
 		txs    []txStats
 		uncles []*types.Header
+		pocr   *blockPoCR
 	)
 
 	// check if backend is a full node
@@ -637,6 +665,15 @@ func (s *Service) assembleBlockStats(block *types.Block) *blockStats {
 			txs[i].Hash = tx.Hash()
 		}
 		uncles = block.Uncles()
+
+		// The reward records are only kept by the full nodes of the PoCR chains
+		if reward := rawdb.ReadPoCRReward(fullBackend.ChainDb(), header.Hash(), header.Number.Uint64()); reward != nil {
+			pocr = &blockPoCR{
+				Rank:          reward.Rank.FloatString(pocrRankPrecision),
+				Reward:        reward.BlockReward,
+				FeeAdjustment: reward.FeeAdjustment,
+			}
+		}
 	} else {
 		// Light nodes would need on-demand lookups for transactions/uncles, skip
 		if block != nil {
@@ -665,6 +702,7 @@ func (s *Service) assembleBlockStats(block *types.Block) *blockStats {
 		TxHash:     header.TxHash,
 		Root:       header.Root,
 		Uncles:     uncles,
+		PoCR:       pocr,
 	}
 }
 
@@ -759,6 +797,53 @@ type nodeStats struct {
 	Peers    int  `json:"peers"`
 	GasPrice int  `json:"gasPrice"`
 	Uptime   int  `json:"uptime"`
+
+	PoCR *nodePoCR `json:"pocr,omitempty"`
+}
+
+// nodePoCR is the proof-of-carbon-reduction standing of the sealer of the local
+// node on top of its current head, reported on the PoCR chains only.
+type nodePoCR struct {
+	Sealer         common.Address `json:"sealer"`
+	Footprint      *big.Int       `json:"footprint"`      // Audited footprint
+	FootprintBlock *big.Int       `json:"footprintBlock"` // Block of the last audit, nil before the footprint storage fork
+	Rank           string         `json:"rank"`           // Rank as a decimal value between 0 and 1
+	Reward         *big.Int       `json:"reward"`         // Reward for sealing the next block
+}
+
+// assemblePoCRStats retrieves the standing of the sealer of the local node, or
+// nil if the chain is not a PoCR one or the node does not seal.
+func (s *Service) assemblePoCRStats(fullBackend fullNodeBackend) *nodePoCR {
+	// The engine of the full nodes is wrapped into the beacon one
+	inner := s.engine
+	if b, ok := inner.(*beacon.Beacon); ok {
+		inner = b.InnerEngine()
+	}
+	engine, ok := inner.(pocrEngine)
+	if !ok {
+		return nil
+	}
+	sealer := engine.Signer()
+	if sealer == (common.Address{}) {
+		return nil
+	}
+	statedb, header, err := fullBackend.StateAndHeaderByNumber(context.Background(), rpc.LatestBlockNumber)
+	if err != nil {
+		log.Debug("Failed to retrieve the head state for the PoCR stats", "err", err)
+		return nil
+	}
+	standing, err := engine.SealerStanding(fullBackend.ChainConfig(), header, statedb, sealer)
+	if err != nil {
+		log.Debug("Failed to rank the sealer for the PoCR stats", "sealer", sealer, "err", err)
+		return nil
+	}
+	return &nodePoCR{
+		Sealer:         sealer,
+		Footprint:      standing.Footprint.ToInt(),
+		FootprintBlock: standing.FootprintBlock.ToInt(),
+		Rank:           standing.Rank,
+		Reward:         standing.Reward.ToInt(),
+	}
 }
 
 // reportStats retrieves various stats about the node at the networking and
@@ -770,6 +855,7 @@ func (s *Service) reportStats(conn *connWrapper) error {
 		hashrate int
 		syncing  bool
 		gasprice int
+		pocr     *nodePoCR
 	)
 	// check if backend is a full node
 	fullBackend, ok := s.backend.(fullNodeBackend)
@@ -785,6 +871,7 @@ func (s *Service) reportStats(conn *connWrapper) error {
 		if basefee := fullBackend.CurrentHeader().BaseFee; basefee != nil {
 			gasprice += int(basefee.Uint64())
 		}
+		pocr = s.assemblePoCRStats(fullBackend)
 	} else {
 		sync := s.backend.SyncProgress()
 		syncing = s.backend.CurrentHeader().Number.Uint64() >= sync.HighestBlock
@@ -802,6 +889,7 @@ func (s *Service) reportStats(conn *connWrapper) error {
 			GasPrice: gasprice,
 			Syncing:  syncing,
 			Uptime:   100,
+			PoCR:     pocr,
 		},
 	}
 	report := map[string][]interface{}{
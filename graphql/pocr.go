@@ -0,0 +1,236 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/cliquepocr"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var errNotPoCRChain = errors.New("not a PoCR chain")
+
+// pocrRankPrecision is the number of decimals of the ranks, as reported by the
+// pocr API.
+const pocrRankPrecision = 18
+
+// pocrEngine is the proof-of-carbon-reduction consensus engine, computing the
+// rewards of the blocks from the footprints of their sealers.
+type pocrEngine interface {
+	BlockRewards(chain consensus.ChainHeaderReader, header *types.Header, statedb, parentState *state.StateDB, receipts types.Receipts) (*cliquepocr.BlockRewards, error)
+	Sealers(chain consensus.ChainHeaderReader, header *types.Header, statedb *state.StateDB) ([]*cliquepocr.SealerStatus, error)
+}
+
+// pocrEngine returns the PoCR engine of the chain, or nil if the chain is not a
+// PoCR one.
+func (r *Resolver) pocrEngine() pocrEngine {
+	engine := r.backend.Engine()
+	if b, ok := engine.(*beacon.Beacon); ok {
+		engine = b.InnerEngine()
+	}
+	pocr, _ := engine.(pocrEngine)
+	return pocr
+}
+
+// chainHeaderReader gives the consensus engine access to the headers of the
+// chain through the backend.
+type chainHeaderReader struct {
+	ctx     context.Context
+	backend ethapi.Backend
+}
+
+func (c *chainHeaderReader) Config() *params.ChainConfig {
+	return c.backend.ChainConfig()
+}
+
+func (c *chainHeaderReader) CurrentHeader() *types.Header {
+	return c.backend.CurrentHeader()
+}
+
+func (c *chainHeaderReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	header, _ := c.backend.HeaderByHash(c.ctx, hash)
+	if header == nil || header.Number.Uint64() != number {
+		return nil
+	}
+	return header
+}
+
+func (c *chainHeaderReader) GetHeaderByNumber(number uint64) *types.Header {
+	header, _ := c.backend.HeaderByNumber(c.ctx, rpc.BlockNumber(number))
+	return header
+}
+
+func (c *chainHeaderReader) GetHeaderByHash(hash common.Hash) *types.Header {
+	header, _ := c.backend.HeaderByHash(c.ctx, hash)
+	return header
+}
+
+func (c *chainHeaderReader) GetTd(hash common.Hash, number uint64) *big.Int {
+	return c.backend.GetTd(c.ctx, hash)
+}
+
+// PoCRReward is the proof-of-carbon-reduction reward of a block.
+type PoCRReward struct {
+	reward *types.PoCRReward
+}
+
+func (p *PoCRReward) Author(ctx context.Context) common.Address {
+	return p.reward.Author
+}
+
+func (p *PoCRReward) Footprint(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*p.reward.Footprint)
+}
+
+func (p *PoCRReward) Rank(ctx context.Context) string {
+	return p.reward.Rank.FloatString(pocrRankPrecision)
+}
+
+func (p *PoCRReward) Reward(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*p.reward.BlockReward)
+}
+
+func (p *PoCRReward) FeeAdjustment(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*p.reward.FeeAdjustment)
+}
+
+func (p *PoCRReward) Burnt(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*p.reward.Burnt)
+}
+
+func (p *PoCRReward) TotalSupply(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*p.reward.TotalCryptoAfter)
+}
+
+// Pocr returns the PoCR reward of the block the node recorded when processing
+// it. Without record (e.g. the block was snap synced), the reward is computed
+// with the engine code from the state of the block and of its parent.
+func (b *Block) Pocr(ctx context.Context) (*PoCRReward, error) {
+	engine := b.r.pocrEngine()
+	if engine == nil {
+		return nil, nil
+	}
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return nil, err
+	}
+	if header.Number.Sign() == 0 {
+		return nil, nil
+	}
+	if reward := rawdb.ReadPoCRReward(b.r.backend.ChainDb(), header.Hash(), header.Number.Uint64()); reward != nil {
+		return &PoCRReward{reward}, nil
+	}
+	statedb, _, err := b.r.backend.StateAndHeaderByNumberOrHash(ctx, rpc.BlockNumberOrHashWithHash(header.Hash(), false))
+	if err != nil {
+		return nil, err
+	}
+	parentState, _, err := b.r.backend.StateAndHeaderByNumberOrHash(ctx, rpc.BlockNumberOrHashWithHash(header.ParentHash, false))
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := b.resolveReceipts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The fee adjustment is only computed if receipts are given, even none
+	rewards, err := engine.BlockRewards(&chainHeaderReader{ctx, b.r.backend}, header, statedb, parentState, append(types.Receipts{}, receipts...))
+	if err != nil {
+		return nil, err
+	}
+	reward := &types.PoCRReward{
+		Author:           rewards.Author,
+		Footprint:        new(big.Int),
+		Rank:             new(big.Rat),
+		BlockReward:      rewards.BlockReward.ToInt(),
+		FeeAdjustment:    rewards.FeeAdjustment.ToInt(),
+		Burnt:            rewards.Burnt.ToInt(),
+		TotalCryptoAfter: cliquepocr.GeneratedPocRTotal(statedb),
+	}
+	if _, ok := reward.Rank.SetString(rewards.Rank); !ok {
+		return nil, fmt.Errorf("invalid rank %q", rewards.Rank)
+	}
+	for _, signer := range rewards.Signers {
+		if signer.Address == rewards.Author {
+			reward.Footprint = signer.PenalizedFootprint.ToInt()
+		}
+	}
+	return &PoCRReward{reward}, nil
+}
+
+// Sealer is a signer of a PoCR chain and its registration in the PoCR contract.
+type Sealer struct {
+	status *cliquepocr.SealerStatus
+}
+
+func (s *Sealer) Address(ctx context.Context) common.Address {
+	return s.status.Address
+}
+
+func (s *Sealer) Footprint(ctx context.Context) hexutil.Big {
+	return *s.status.Footprint
+}
+
+func (s *Sealer) AuditBlock(ctx context.Context) *Long {
+	if s.status.FootprintBlock == nil {
+		return nil
+	}
+	block := Long(s.status.FootprintBlock.ToInt().Int64())
+	return &block
+}
+
+func (s *Sealer) IsSealer(ctx context.Context) bool {
+	return s.status.IsSealer
+}
+
+// Sealers returns the signers of a block, the latest one if none is given, with
+// their registration in the PoCR contract of the block.
+func (r *Resolver) Sealers(ctx context.Context, args struct{ Block *Long }) ([]*Sealer, error) {
+	engine := r.pocrEngine()
+	if engine == nil {
+		return nil, errNotPoCRChain
+	}
+	number := rpc.LatestBlockNumber
+	if args.Block != nil {
+		number = rpc.BlockNumber(*args.Block)
+	}
+	statedb, header, err := r.backend.StateAndHeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := engine.Sealers(&chainHeaderReader{ctx, r.backend}, header, statedb)
+	if err != nil {
+		return nil, err
+	}
+	sealers := make([]*Sealer, 0, len(statuses))
+	for _, status := range statuses {
+		sealers = append(sealers, &Sealer{status})
+	}
+	return sealers, nil
+}
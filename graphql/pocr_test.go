@@ -0,0 +1,287 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/cliquepocr"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// newPoCRGQLService creates a GraphQL service over a PoCR chain sealed by the
+// given account alone, with blocks transferring funds.
+func newPoCRGQLService(t *testing.T, key []byte, genBlocks int) (*handler, *eth.Ethereum) {
+	var (
+		sealerKey, _ = crypto.ToECDSA(key)
+		sealer       = crypto.PubkeyToAddress(sealerKey.PublicKey)
+		config       = *params.AllCliqueProtocolChanges
+		stack        = createNode(t)
+	)
+	t.Cleanup(func() { stack.Close() })
+	// The built contracts are read from their storage, from the footprint storage fork on
+	config.Clique = &params.CliqueConfig{Period: 0, Epoch: 30000, PoCR: true, FootprintStorageBlock: new(big.Int)}
+
+	contracts := &cliquepocr.GenesisContracts{
+		Sealers: []cliquepocr.GenesisSealer{{Address: sealer, Footprint: big.NewInt(1000)}},
+	}
+	alloc, err := contracts.Alloc()
+	if err != nil {
+		t.Fatalf("failed to build the PoCR contracts: %v", err)
+	}
+	alloc[sealer] = core.GenesisAccount{Balance: big.NewInt(params.Ether)}
+	genesis := &core.Genesis{
+		Config:    &config,
+		ExtraData: contracts.ExtraData(),
+		GasLimit:  11500000,
+		Alloc:     alloc,
+		BaseFee:   big.NewInt(params.InitialBaseFee),
+	}
+	ethBackend, err := eth.New(stack, &ethconfig.Config{
+		Genesis:                 genesis,
+		NetworkId:               1337,
+		TrieCleanCache:          5,
+		TrieCleanCacheJournal:   "triecache",
+		TrieCleanCacheRejournal: 60 * time.Minute,
+		TrieDirtyCache:          5,
+		TrieTimeout:             60 * time.Minute,
+		SnapshotCache:           5,
+	})
+	if err != nil {
+		t.Fatalf("could not create eth backend: %v", err)
+	}
+	// Seal the blocks one by one on a chain of their own, so the engine finds
+	// the snapshot of the parent of the block it is finalizing
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		engine = cliquepocr.New(config.Clique, db)
+		signer = types.LatestSigner(&config)
+		parent = genesis.MustCommit(db)
+	)
+	engine.Authorize(sealer, nil)
+
+	chain, err := core.NewBlockChain(db, nil, &config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create the PoCR chain: %v", err)
+	}
+	defer chain.Stop()
+
+	for i := 0; i < genBlocks; i++ {
+		blocks, _ := core.GenerateChain(&config, parent, engine, db, 1, func(_ int, block *core.BlockGen) {
+			block.SetDifficulty(big.NewInt(2))
+			block.SetExtra(make([]byte, 32+crypto.SignatureLength))
+
+			tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(sealer), common.Address{0x01}, big.NewInt(1), params.TxGas, block.BaseFee(), nil), signer, sealerKey)
+			block.AddTx(tx)
+		})
+		header := blocks[0].Header()
+		sig, _ := crypto.Sign(engine.SealHash(header).Bytes(), sealerKey)
+		copy(header.Extra[len(header.Extra)-crypto.SignatureLength:], sig)
+		parent = blocks[0].WithSeal(header)
+
+		if _, err := chain.InsertChain(types.Blocks{parent}); err != nil {
+			t.Fatalf("failed to seal block %d: %v", parent.NumberU64(), err)
+		}
+		if _, err := ethBackend.BlockChain().InsertChain(types.Blocks{parent}); err != nil {
+			t.Fatalf("could not import block %d: %v", parent.NumberU64(), err)
+		}
+	}
+	filterSystem := filters.NewFilterSystem(ethBackend.APIBackend, filters.Config{})
+	handler, err := newHandler(stack, ethBackend.APIBackend, filterSystem, []string{}, []string{})
+	if err != nil {
+		t.Fatalf("could not create graphql service: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	return handler, ethBackend
+}
+
+// Tests that the PoCR reward of the blocks matches the one recorded by the
+// engine when processing them, and that the sealers are reported.
+func TestGraphQLPoCR(t *testing.T) {
+	var (
+		key    = common.FromHex("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		sealer = crypto.PubkeyToAddress(crypto.ToECDSAUnsafe(key).PublicKey)
+	)
+	handler, ethBackend := newPoCRGQLService(t, key, 2)
+
+	for number := 0; number <= 2; number++ {
+		query := fmt.Sprintf(`{block(number: %d) { hash pocr { author footprint rank reward feeAdjustment burnt totalSupply } } }`, number)
+		res := handler.Schema.Exec(context.Background(), query, "", map[string]interface{}{})
+		if res.Errors != nil {
+			t.Fatalf("block %d: graphql query failed: %v", number, res.Errors)
+		}
+		var have struct {
+			Block struct {
+				Hash common.Hash
+				Pocr *struct {
+					Author        common.Address
+					Footprint     *hexutil.Big
+					Rank          string
+					Reward        *hexutil.Big
+					FeeAdjustment *hexutil.Big
+					Burnt         *hexutil.Big
+					TotalSupply   *hexutil.Big
+				}
+			}
+		}
+		if err := json.Unmarshal(res.Data, &have); err != nil {
+			t.Fatalf("block %d: failed to decode graphql response: %v", number, err)
+		}
+		if number == 0 {
+			if have.Block.Pocr != nil {
+				t.Errorf("genesis reward reported: %+v", have.Block.Pocr)
+			}
+			continue
+		}
+		want := rawdb.ReadPoCRReward(ethBackend.ChainDb(), have.Block.Hash, uint64(number))
+		if want == nil {
+			t.Fatalf("block %d: reward not recorded", number)
+		}
+		pocr := have.Block.Pocr
+		if pocr == nil {
+			t.Fatalf("block %d: reward not reported", number)
+		}
+		if pocr.Author != want.Author {
+			t.Errorf("block %d: author mismatch: have %x, want %x", number, pocr.Author, want.Author)
+		}
+		if pocr.Footprint.ToInt().Cmp(big.NewInt(1000)) != 0 {
+			t.Errorf("block %d: footprint mismatch: have %v, want 1000", number, pocr.Footprint)
+		}
+		if pocr.Rank != want.Rank.FloatString(18) {
+			t.Errorf("block %d: rank mismatch: have %s, want %s", number, pocr.Rank, want.Rank.FloatString(18))
+		}
+		if pocr.Reward.ToInt().Cmp(want.BlockReward) != 0 {
+			t.Errorf("block %d: reward mismatch: have %v, want %v", number, pocr.Reward, want.BlockReward)
+		}
+		if pocr.FeeAdjustment.ToInt().Cmp(want.FeeAdjustment) != 0 {
+			t.Errorf("block %d: fee adjustment mismatch: have %v, want %v", number, pocr.FeeAdjustment, want.FeeAdjustment)
+		}
+		if pocr.Burnt.ToInt().Cmp(want.Burnt) != 0 {
+			t.Errorf("block %d: burnt mismatch: have %v, want %v", number, pocr.Burnt, want.Burnt)
+		}
+		if pocr.TotalSupply.ToInt().Cmp(want.TotalCryptoAfter) != 0 {
+			t.Errorf("block %d: total supply mismatch: have %v, want %v", number, pocr.TotalSupply, want.TotalCryptoAfter)
+		}
+	}
+	// The reward is the recorded one, and computed from the state without record
+	var (
+		db     = ethBackend.ChainDb()
+		block  = ethBackend.BlockChain().GetBlockByNumber(1)
+		record = rawdb.ReadPoCRReward(db, block.Hash(), 1)
+		reward = func() string {
+			res := handler.Schema.Exec(context.Background(), `{block(number: 1) { pocr { author footprint rank reward feeAdjustment burnt totalSupply } } }`, "", map[string]interface{}{})
+			if res.Errors != nil {
+				t.Fatalf("graphql query failed: %v", res.Errors)
+			}
+			return string(res.Data)
+		}
+		recorded = reward()
+	)
+	tampered := *record
+	tampered.BlockReward = big.NewInt(12345)
+	rawdb.WritePoCRReward(db, block.Hash(), 1, &tampered)
+	if have := reward(); !strings.Contains(have, `"reward":"0x3039"`) {
+		t.Errorf("recorded reward not reported: %s", have)
+	}
+	rawdb.DeletePoCRReward(db, block.Hash(), 1)
+	if have := reward(); have != recorded {
+		t.Errorf("computed reward mismatch: have %s, want %s", have, recorded)
+	}
+	// The sealer is registered by the genesis contracts
+	for _, block := range []string{"", "(block: 0)"} {
+		res := handler.Schema.Exec(context.Background(), fmt.Sprintf(`{sealers%s { address footprint auditBlock isSealer } }`, block), "", map[string]interface{}{})
+		if res.Errors != nil {
+			t.Fatalf("sealers%s: graphql query failed: %v", block, res.Errors)
+		}
+		var have struct {
+			Sealers []struct {
+				Address   common.Address
+				Footprint *hexutil.Big
+				IsSealer  bool
+			}
+		}
+		if err := json.Unmarshal(res.Data, &have); err != nil {
+			t.Fatalf("sealers%s: failed to decode graphql response: %v", block, err)
+		}
+		if len(have.Sealers) != 1 {
+			t.Fatalf("sealers%s: sealer count mismatch: have %d, want 1", block, len(have.Sealers))
+		}
+		if s := have.Sealers[0]; s.Address != sealer || s.Footprint.ToInt().Cmp(big.NewInt(1000)) != 0 || !s.IsSealer {
+			t.Errorf("sealers%s: sealer mismatch: %+v", block, s)
+		}
+	}
+}
+
+// Tests that the logs of the blocks include the PoCR system logs derived from
+// the reward records.
+func TestGraphQLPoCRLogs(t *testing.T) {
+	key := common.FromHex("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	handler, ethBackend := newPoCRGQLService(t, key, 2)
+
+	queries := []string{
+		fmt.Sprintf(`{logs(filter: {fromBlock: 0, toBlock: 2, addresses: ["%s"]}) { index topics account { address } transaction { hash } } }`, types.PoCRLogAddress.Hex()),
+		fmt.Sprintf(`{logs(filter: {fromBlock: 0, toBlock: 2, topics: [["%s"]]}) { index topics account { address } transaction { hash } } }`, types.RewardMintedTopic.Hex()),
+	}
+	for _, query := range queries {
+		res := handler.Schema.Exec(context.Background(), query, "", map[string]interface{}{})
+		if res.Errors != nil {
+			t.Fatalf("graphql query failed: %v", res.Errors)
+		}
+		var have struct {
+			Logs []struct {
+				Index   int
+				Topics  []common.Hash
+				Account struct{ Address common.Address }
+				// The system logs belong to no transaction
+				Transaction struct{ Hash common.Hash }
+			}
+		}
+		if err := json.Unmarshal(res.Data, &have); err != nil {
+			t.Fatalf("failed to decode graphql response: %v", err)
+		}
+		if len(have.Logs) != 2 {
+			t.Fatalf("system logs mismatch: have %d, want 2", len(have.Logs))
+		}
+		for i, log := range have.Logs {
+			block := ethBackend.BlockChain().GetBlockByNumber(uint64(i + 1))
+			if log.Account.Address != types.PoCRLogAddress || log.Topics[0] != types.RewardMintedTopic || log.Index != 0 {
+				t.Errorf("block %d: unexpected system log: %+v", i+1, log)
+			}
+			if log.Transaction.Hash != types.PoCRLogTxHash(block.Hash()) {
+				t.Errorf("block %d: transaction hash mismatch: have %x, want %x", i+1, log.Transaction.Hash, types.PoCRLogTxHash(block.Hash()))
+			}
+		}
+	}
+}
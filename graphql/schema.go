@@ -246,6 +246,48 @@ const schema string = `
         rawHeader: Bytes!
         # Raw is the RLP encoding of the block.
         raw: Bytes!
+        # PoCR is the proof-of-carbon-reduction reward of this block. It is null
+        # on the genesis block and on the chains that are not PoCR ones.
+        pocr: PoCRReward
+    }
+
+    # PoCRReward is the proof-of-carbon-reduction reward of a block, as recorded
+    # by the consensus engine when processing the block, or computed from the
+    # state of the block and of its parent if the node has no record.
+    type PoCRReward {
+        # Author is the sealer of the block.
+        author: Address!
+        # Footprint is the carbon footprint of the author the ranking used,
+        # audit age penalty included.
+        footprint: BigInt!
+        # Rank is the rank of the author among the sealers, as a decimal value
+        # between 0 and 1.
+        rank: String!
+        # Reward is the amount minted for the author.
+        reward: BigInt!
+        # FeeAdjustment is the amount added to (positive) or removed from
+        # (negative) the fees of the author because of its rank.
+        feeAdjustment: BigInt!
+        # Burnt is the amount of fees burnt by the EIP-1559.
+        burnt: BigInt!
+        # TotalSupply is the total amount generated by the PoCR rewards once the
+        # block is applied, net of the burnt and confiscated fees.
+        totalSupply: BigInt!
+    }
+
+    # Sealer is a signer of a PoCR chain and its registration in the PoCR
+    # contract.
+    type Sealer {
+        # Address is the address of the signer.
+        address: Address!
+        # Footprint is the audited carbon footprint of the signer, 0 if it was
+        # not audited.
+        footprint: BigInt!
+        # AuditBlock is the block of the last audit of the footprint, as recorded
+        # by the engine. It is null before the footprint storage fork.
+        auditBlock: Long
+        # IsSealer tells whether the PoCR contract lists the signer as a sealer.
+        isSealer: Boolean!
     }
 
     # CallData represents the data associated with a local contract call.
@@ -352,6 +394,10 @@ const schema string = `
         syncing: SyncState
         # ChainID returns the current chain ID for transaction replay protection.
         chainID: BigInt!
+        # Sealers returns the signers of a block of a PoCR chain, the most
+        # recent known block if none is supplied, with their registration in
+        # the PoCR contract.
+        sealers(block: Long): [Sealer!]!
     }
 
     type Mutation {
@@ -35,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/consensus/misc"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -1698,6 +1699,18 @@ func (s *TransactionAPI) GetTransactionReceipt(ctx context.Context, hash common.
 	if receipt.ContractAddress != (common.Address{}) {
 		fields["contractAddress"] = receipt.ContractAddress
 	}
+	// Assign the fees paid, and the share kept by the sealer of a PoCR block once
+	// the fees are adjusted to its rank
+	if receipt.FeeSpent != nil {
+		fields["feeSpent"] = (*hexutil.Big)(receipt.FeeSpent)
+		fields["feeTransferred"] = (*hexutil.Big)(receipt.FeeTransferred)
+		fields["feeBurnt"] = (*hexutil.Big)(receipt.FeeBurnt)
+
+		if reward := rawdb.ReadPoCRReward(s.b.ChainDb(), blockHash, blockNumber); reward != nil {
+			share := new(big.Int).Mul(receipt.FeeTransferred, reward.Rank.Num())
+			fields["sealerFeeShare"] = (*hexutil.Big)(share.Div(share, reward.Rank.Denom()))
+		}
+	}
 	return fields, nil
 }
 
@@ -30,6 +30,7 @@ var Modules = map[string]string{
 	"txpool":   TxpoolJs,
 	"les":      LESJs,
 	"vflux":    VfluxJs,
+	"pocr":     PoCRJs,
 }
 
 const CliqueJs = `
@@ -89,6 +90,42 @@ web3._extend({
 });
 `
 
+const PoCRJs = `
+web3._extend({
+	property: 'pocr',
+	methods: [
+		new web3._extend.Method({
+			name: 'getRewards',
+			call: 'pocr_getRewards',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getRewardsAtHash',
+			call: 'pocr_getRewardsAtHash',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getRewardLogs',
+			call: 'pocr_getRewardLogs',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getRewardLogsAtHash',
+			call: 'pocr_getRewardLogsAtHash',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getSealerStanding',
+			call: 'pocr_getSealerStanding',
+			params: 1,
+			inputFormatter: [null]
+		}),
+	]
+});
+`
+
 const EthashJs = `
 web3._extend({
 	property: 'ethash',
@@ -18,6 +18,7 @@
 package les
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -27,6 +28,8 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/cliquepocr"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/rawdb"
@@ -72,6 +75,7 @@ type LightEthereum struct {
 	ApiBackend     *LesApiBackend
 	eventMux       *event.TypeMux
 	engine         consensus.Engine
+	pocrProofs     *rpc.Client // Node serving the proofs of the PoCR rewards
 	accountManager *accounts.Manager
 	netRPCService  *ethapi.NetAPI
 
@@ -130,6 +134,22 @@ func New(stack *node.Node, config *ethconfig.Config) (*LightEthereum, error) {
 		shutdownTracker: shutdowncheck.NewShutdownTracker(chainDb),
 	}
 
+	// The light chain has no state to compute the PoCR rewards from, verify
+	// them with the proofs of a full node instead.
+	if config.LightPoCRProofs != "" {
+		engine := leth.engine
+		if b, ok := engine.(*beacon.Beacon); ok {
+			engine = b.InnerEngine()
+		}
+		pocr, ok := engine.(*cliquepocr.CliquePoCR)
+		if !ok {
+			return nil, errors.New("PoCR proofs configured on a non-PoCR chain")
+		}
+		if leth.pocrProofs, err = rpc.Dial(config.LightPoCRProofs); err != nil {
+			return nil, fmt.Errorf("failed to connect to the PoCR proofs endpoint: %v", err)
+		}
+		pocr.SetProofSource(cliquepocr.RPCProofFetcher(leth.pocrProofs))
+	}
 	var prenegQuery vfc.QueryFunc
 	if leth.udpEnabled {
 		prenegQuery = leth.prenegQuery
@@ -373,6 +393,9 @@ func (s *LightEthereum) Stop() error {
 	s.handler.stop()
 	s.txPool.Stop()
 	s.engine.Close()
+	if s.pocrProofs != nil {
+		s.pocrProofs.Close()
+	}
 	s.pruner.close()
 	s.eventMux.Stop()
 	// Clean shutdown marker as the last thing before closing db
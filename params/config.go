@@ -18,6 +18,7 @@ package params
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -270,16 +271,16 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, false, new(EthashConfig), nil}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, false, new(EthashConfig), nil, nil, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Ethereum core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, false, nil, &CliqueConfig{Period: 0, Epoch: 30000}}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, false, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil, nil}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, false, new(EthashConfig), nil}
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, false, new(EthashConfig), nil, nil, nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int), false)
 )
 
@@ -385,6 +386,32 @@ type ChainConfig struct {
 	// Various consensus engines
 	Ethash *EthashConfig `json:"ethash,omitempty"`
 	Clique *CliqueConfig `json:"clique,omitempty"`
+
+	// PoCR economic parameters, DefaultPoCRFork applies when nil or before the
+	// first fork
+	PoCR *PoCRConfig `json:"pocr,omitempty"`
+
+	// Upgrades of the system contracts (the PoCR genesis contracts), by ascending
+	// block. Their content is in the upgrade table of the network (core/systemcontracts).
+	SystemContractUpgrades []SystemContractUpgrade `json:"systemContractUpgrades,omitempty"`
+}
+
+// SystemContractUpgrade schedules an upgrade of the system contracts.
+type SystemContractUpgrade struct {
+	Name  string   `json:"name"`  // Name of the upgrade in the network upgrade table
+	Block *big.Int `json:"block"` // Block the upgrade is applied at, before its transactions
+}
+
+// SystemContractUpgradesAt returns the names of the system contract upgrades
+// scheduled at the given block, in the order they are applied.
+func (c *ChainConfig) SystemContractUpgradesAt(num *big.Int) []string {
+	var names []string
+	for _, upgrade := range c.SystemContractUpgrades {
+		if upgrade.Block != nil && num != nil && upgrade.Block.Cmp(num) == 0 {
+			names = append(names, upgrade.Name)
+		}
+	}
+	return names
 }
 
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
@@ -400,6 +427,98 @@ type CliqueConfig struct {
 	Period uint64 `json:"period"` // Number of seconds between blocks to enforce
 	Epoch  uint64 `json:"epoch"`  // Epoch length to reset votes and checkpoint
 	PoCR   bool   `json:"pocr"`
+
+	RewardAlgorithm      uint64                `json:"rewardAlgorithm,omitempty"`      // PoCR reward algorithm id from genesis (0 = DefaultRewardAlgorithm)
+	RewardAlgorithmForks []RewardAlgorithmFork `json:"rewardAlgorithmForks,omitempty"` // PoCR reward algorithm switches, by ascending block
+
+	SealerSetBlock         *big.Int `json:"sealerSetBlock,omitempty"`         // Block from which the PoCR contract keeps the sealers as an unordered set updated on signer changes (nil = sorted list rewritten on every block)
+	RankedSealingBlock     *big.Int `json:"rankedSealingBlock,omitempty"`     // Block from which the sealers delay their blocks by their PoCR rank (nil = round-robin only)
+	FootprintRequiredBlock *big.Int `json:"footprintRequiredBlock,omitempty"` // Block from which the signers need an audited footprint to seal (nil = any signer seals)
+	FootprintStorageBlock  *big.Int `json:"footprintStorageBlock,omitempty"`  // Block from which the footprints are read from the storage of the PoCR contract (nil = footprint getters)
+}
+
+// DefaultRewardAlgorithm is the id of the PoCR reward algorithm used when the
+// configuration does not select any (the white paper race rank).
+const DefaultRewardAlgorithm = 3
+
+// RewardAlgorithmFork switches the PoCR reward algorithm from a given block on.
+type RewardAlgorithmFork struct {
+	Block     *big.Int `json:"block"`     // First block computed with the algorithm
+	Algorithm uint64   `json:"algorithm"` // Id of the algorithm
+}
+
+// RewardAlgorithmAt returns the id of the PoCR reward algorithm in force at the
+// given block.
+func (c *CliqueConfig) RewardAlgorithmAt(num *big.Int) uint64 {
+	algorithm := c.RewardAlgorithm
+	for _, fork := range c.RewardAlgorithmForks {
+		if isForked(fork.Block, num) {
+			algorithm = fork.Algorithm
+		}
+	}
+	if algorithm == 0 {
+		return DefaultRewardAlgorithm
+	}
+	return algorithm
+}
+
+// IsSealerSet returns whether num is either equal to the PoCR sealer set fork
+// block or greater.
+func (c *CliqueConfig) IsSealerSet(num *big.Int) bool {
+	return isForked(c.SealerSetBlock, num)
+}
+
+// IsRankedSealing returns whether num is either equal to the PoCR ranked sealing
+// fork block or greater.
+func (c *CliqueConfig) IsRankedSealing(num *big.Int) bool {
+	return isForked(c.RankedSealingBlock, num)
+}
+
+// IsFootprintRequired returns whether num is either equal to the PoCR footprint
+// required fork block or greater.
+func (c *CliqueConfig) IsFootprintRequired(num *big.Int) bool {
+	return isForked(c.FootprintRequiredBlock, num)
+}
+
+// IsFootprintStorage returns whether num is either equal to the PoCR footprint
+// storage fork block or greater.
+func (c *CliqueConfig) IsFootprintStorage(num *big.Int) bool {
+	return isForked(c.FootprintStorageBlock, num)
+}
+
+// checkCompatible checks whether the reward algorithm in force at any block up
+// to head is the same in both configurations, returning the earliest mismatch,
+// and whether the sealer set, footprint required and footprint storage forks
+// can still be rescheduled. The ranked sealing fork only changes when the
+// sealers release their blocks, not their validity, so it can be rescheduled at
+// any time.
+func (c *CliqueConfig) checkCompatible(newcfg *CliqueConfig, head *big.Int) *ConfigCompatError {
+	if isForkIncompatible(c.SealerSetBlock, newcfg.SealerSetBlock, head) {
+		return newCompatError("PoCR sealer set fork block", c.SealerSetBlock, newcfg.SealerSetBlock)
+	}
+	if isForkIncompatible(c.FootprintRequiredBlock, newcfg.FootprintRequiredBlock, head) {
+		return newCompatError("PoCR footprint required fork block", c.FootprintRequiredBlock, newcfg.FootprintRequiredBlock)
+	}
+	if isForkIncompatible(c.FootprintStorageBlock, newcfg.FootprintStorageBlock, head) {
+		return newCompatError("PoCR footprint storage fork block", c.FootprintStorageBlock, newcfg.FootprintStorageBlock)
+	}
+	blocks := []*big.Int{common.Big0}
+	for _, fork := range append(append([]RewardAlgorithmFork{}, c.RewardAlgorithmForks...), newcfg.RewardAlgorithmForks...) {
+		blocks = append(blocks, fork.Block)
+	}
+	var mismatch *big.Int
+	for _, block := range blocks {
+		if !isForked(block, head) || c.RewardAlgorithmAt(block) == newcfg.RewardAlgorithmAt(block) {
+			continue
+		}
+		if mismatch == nil || block.Cmp(mismatch) < 0 {
+			mismatch = block
+		}
+	}
+	if mismatch != nil {
+		return newCompatError("PoCR reward algorithm fork block", mismatch, mismatch)
+	}
+	return nil
 }
 
 // String implements the stringer interface, returning the consensus engine details.
@@ -407,6 +526,114 @@ func (c *CliqueConfig) String() string {
 	return "clique"
 }
 
+// PoCRConfig is the schedule of the economic parameters of the proof-of-carbon
+// reduction rewards. Durations are given in seconds and converted to blocks
+// with the clique period.
+type PoCRConfig struct {
+	Forks []PoCRFork `json:"forks"` // Parameter sets, by ascending activation block
+}
+
+// PoCRFork is a set of PoCR economic parameters in force from a given block on.
+type PoCRFork struct {
+	Block                *big.Int `json:"block"`                // First block the parameters apply to
+	AuditValidity        uint64   `json:"auditValidity"`        // Seconds after which an audited footprint gets penalized
+	AuditPenalty         uint64   `json:"auditPenalty"`         // Footprint penalty in percent per elapsed audit validity
+	InflationDenominator *big.Int `json:"inflationDenominator"` // Total generated coins per step of the inflation control
+	MinCreationPerYear   *big.Int `json:"minCreationPerYear"`   // Minimum coins minted per year
+	RankDecay            uint64   `json:"rankDecay"`            // Rank multiplier in percent per better ranked sealer
+	AlphaFactor          uint64   `json:"alphaFactor"`          // Inflation control exponent factor in percent
+	ExactInflation       bool     `json:"exactInflation"`       // Fixed-point inflation control instead of the Taylor series
+}
+
+// DefaultPoCRPeriod is the block period the PoCR durations are converted with
+// when the clique period is zero, as on the development chains. It is the period
+// all the durations were converted with before the clique period was used.
+const DefaultPoCRPeriod = 4
+
+// DefaultPoCRFork holds the PoCR parameters of the chains having no PoCR
+// configuration.
+var DefaultPoCRFork = PoCRFork{
+	Block:                big.NewInt(0),
+	AuditValidity:        365 * 24 * 3600, // 1 year
+	AuditPenalty:         5,
+	InflationDenominator: big.NewInt(10000000),
+	MinCreationPerYear:   big.NewInt(100000),
+	RankDecay:            90,
+	AlphaFactor:          72,
+}
+
+// ForkAt returns the PoCR parameters in force at the given block, or nil if
+// none is scheduled yet.
+func (c *PoCRConfig) ForkAt(num *big.Int) *PoCRFork {
+	var fork *PoCRFork
+	for i := range c.Forks {
+		if isForked(c.Forks[i].Block, num) {
+			fork = &c.Forks[i]
+		}
+	}
+	return fork
+}
+
+// validate checks the forks are scheduled in order with sane parameters.
+func (c *PoCRConfig) validate() error {
+	var last *big.Int
+	for _, fork := range c.Forks {
+		switch {
+		case fork.Block == nil:
+			return errors.New("unsupported PoCR fork: block not set")
+		case last != nil && last.Cmp(fork.Block) >= 0:
+			return fmt.Errorf("unsupported PoCR fork ordering: fork at %v follows fork at %v", fork.Block, last)
+		case fork.AuditValidity == 0:
+			return fmt.Errorf("invalid PoCR fork at %v: zero audit validity", fork.Block)
+		case fork.InflationDenominator == nil || fork.InflationDenominator.Sign() <= 0:
+			return fmt.Errorf("invalid PoCR fork at %v: inflation denominator must be positive", fork.Block)
+		case fork.MinCreationPerYear == nil || fork.MinCreationPerYear.Sign() < 0:
+			return fmt.Errorf("invalid PoCR fork at %v: minimum creation must be set", fork.Block)
+		case fork.RankDecay == 0 || fork.RankDecay > 100:
+			return fmt.Errorf("invalid PoCR fork at %v: rank decay must be in ]0, 100]", fork.Block)
+		case fork.AlphaFactor == 0:
+			return fmt.Errorf("invalid PoCR fork at %v: zero alpha factor", fork.Block)
+		}
+		last = fork.Block
+	}
+	return nil
+}
+
+// equal reports whether both forks define the same parameters, regardless of
+// their activation block.
+func (f *PoCRFork) equal(other *PoCRFork) bool {
+	if f == nil || other == nil {
+		return f == other
+	}
+	return f.AuditValidity == other.AuditValidity && f.AuditPenalty == other.AuditPenalty &&
+		configNumEqual(f.InflationDenominator, other.InflationDenominator) &&
+		configNumEqual(f.MinCreationPerYear, other.MinCreationPerYear) &&
+		f.RankDecay == other.RankDecay && f.AlphaFactor == other.AlphaFactor &&
+		f.ExactInflation == other.ExactInflation
+}
+
+// PoCRForkAt returns the PoCR parameters in force at the given block along with
+// the block period their durations are converted with: the clique period, the
+// default parameters included.
+func (c *ChainConfig) PoCRForkAt(num *big.Int) (*PoCRFork, uint64) {
+	fork := &DefaultPoCRFork
+	if c.PoCR != nil {
+		if scheduled := c.PoCR.ForkAt(num); scheduled != nil {
+			fork = scheduled
+		}
+	}
+	return fork, c.pocrPeriod()
+}
+
+// pocrPeriod returns the block period the PoCR durations are converted with, the
+// clique period or DefaultPoCRPeriod if it is zero.
+func (c *ChainConfig) pocrPeriod() uint64 {
+	if c.Clique == nil || c.Clique.Period == 0 {
+		return DefaultPoCRPeriod
+	}
+	return c.Clique.Period
+}
+
 // String implements the fmt.Stringer interface.
 func (c *ChainConfig) String() string {
 	var banner string
@@ -643,6 +870,39 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 			lastFork = cur
 		}
 	}
+	if c.PoCR != nil {
+		if err := c.PoCR.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Clique != nil {
+		var last *big.Int
+		for _, fork := range c.Clique.RewardAlgorithmForks {
+			if fork.Block == nil {
+				return fmt.Errorf("unsupported reward algorithm fork: %d not scheduled", fork.Algorithm)
+			}
+			if last != nil && last.Cmp(fork.Block) >= 0 {
+				return fmt.Errorf("unsupported reward algorithm fork ordering: fork at %v follows fork at %v", fork.Block, last)
+			}
+			last = fork.Block
+		}
+	}
+	var (
+		last  *big.Int
+		names = make(map[string]bool)
+	)
+	for _, upgrade := range c.SystemContractUpgrades {
+		if upgrade.Name == "" || names[upgrade.Name] {
+			return fmt.Errorf("unsupported system contract upgrade: invalid or duplicate name %q", upgrade.Name)
+		}
+		if upgrade.Block == nil {
+			return fmt.Errorf("unsupported system contract upgrade: %s not scheduled", upgrade.Name)
+		}
+		if last != nil && last.Cmp(upgrade.Block) > 0 {
+			return fmt.Errorf("unsupported system contract upgrade ordering: %s at %v follows an upgrade at %v", upgrade.Name, upgrade.Block, last)
+		}
+		names[upgrade.Name], last = true, upgrade.Block
+	}
 	return nil
 }
 
@@ -708,6 +968,79 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.CancunBlock, newcfg.CancunBlock, head) {
 		return newCompatError("Cancun fork block", c.CancunBlock, newcfg.CancunBlock)
 	}
+	if c.Clique != nil && newcfg.Clique != nil {
+		if err := c.Clique.checkCompatible(newcfg.Clique, head); err != nil {
+			return err
+		}
+	}
+	if err := c.checkPoCRCompatible(newcfg, head); err != nil {
+		return err
+	}
+	if err := c.checkSystemContractUpgradesCompatible(newcfg, head); err != nil {
+		return err
+	}
+	return nil
+}
+
+// checkSystemContractUpgradesCompatible checks whether the system contract
+// upgrades applied up to head are the same in both configurations, returning
+// the earliest mismatch.
+func (c *ChainConfig) checkSystemContractUpgradesCompatible(newcfg *ChainConfig, head *big.Int) *ConfigCompatError {
+	scheduled := func(upgrades []SystemContractUpgrade, upgrade SystemContractUpgrade) bool {
+		for _, other := range upgrades {
+			if other.Name == upgrade.Name && configNumEqual(other.Block, upgrade.Block) {
+				return true
+			}
+		}
+		return false
+	}
+	var mismatch *big.Int
+	for _, upgrade := range append(append([]SystemContractUpgrade{}, c.SystemContractUpgrades...), newcfg.SystemContractUpgrades...) {
+		if !isForked(upgrade.Block, head) {
+			continue
+		}
+		if scheduled(c.SystemContractUpgrades, upgrade) && scheduled(newcfg.SystemContractUpgrades, upgrade) {
+			continue
+		}
+		if mismatch == nil || upgrade.Block.Cmp(mismatch) < 0 {
+			mismatch = upgrade.Block
+		}
+	}
+	if mismatch != nil {
+		return newCompatError("system contract upgrade block", mismatch, mismatch)
+	}
+	return nil
+}
+
+// checkPoCRCompatible checks whether the PoCR parameters in force at any block
+// up to head are the same in both configurations, and whether a PoCR fork is in
+// force at the same blocks, returning the earliest mismatch.
+func (c *ChainConfig) checkPoCRCompatible(newcfg *ChainConfig, head *big.Int) *ConfigCompatError {
+	blocks := []*big.Int{common.Big0}
+	for _, cfg := range []*ChainConfig{c, newcfg} {
+		if cfg.PoCR != nil {
+			for _, fork := range cfg.PoCR.Forks {
+				blocks = append(blocks, fork.Block)
+			}
+		}
+	}
+	var mismatch *big.Int
+	for _, block := range blocks {
+		if !isForked(block, head) {
+			continue
+		}
+		oldFork, oldPeriod := c.PoCRForkAt(block)
+		newFork, newPeriod := newcfg.PoCRForkAt(block)
+		if oldFork.equal(newFork) && oldPeriod == newPeriod {
+			continue
+		}
+		if mismatch == nil || block.Cmp(mismatch) < 0 {
+			mismatch = block
+		}
+	}
+	if mismatch != nil {
+		return newCompatError("PoCR parameters fork block", mismatch, mismatch)
+	}
 	return nil
 }
 
@@ -87,6 +87,85 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     30,
 			},
 		},
+		{
+			stored:  &ChainConfig{Clique: &CliqueConfig{RewardAlgorithmForks: []RewardAlgorithmFork{{Block: big.NewInt(10), Algorithm: 4}}}},
+			new:     &ChainConfig{Clique: &CliqueConfig{RewardAlgorithmForks: []RewardAlgorithmFork{{Block: big.NewInt(20), Algorithm: 4}}}},
+			head:    9,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Clique: &CliqueConfig{RewardAlgorithmForks: []RewardAlgorithmFork{{Block: big.NewInt(10), Algorithm: 4}}}},
+			new:    &ChainConfig{Clique: &CliqueConfig{RewardAlgorithmForks: []RewardAlgorithmFork{{Block: big.NewInt(20), Algorithm: 4}}}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "PoCR reward algorithm fork block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored:  &ChainConfig{Clique: &CliqueConfig{}},
+			new:     &ChainConfig{Clique: &CliqueConfig{RewardAlgorithm: DefaultRewardAlgorithm}},
+			head:    15,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Clique: &CliqueConfig{}},
+			new:    &ChainConfig{Clique: &CliqueConfig{RewardAlgorithm: 4}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "PoCR reward algorithm fork block",
+				StoredConfig: big.NewInt(0),
+				NewConfig:    big.NewInt(0),
+				RewindTo:     0,
+			},
+		},
+		{
+			stored:  &ChainConfig{Clique: &CliqueConfig{SealerSetBlock: big.NewInt(10)}},
+			new:     &ChainConfig{Clique: &CliqueConfig{SealerSetBlock: big.NewInt(20)}},
+			head:    9,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Clique: &CliqueConfig{SealerSetBlock: big.NewInt(10)}},
+			new:    &ChainConfig{Clique: &CliqueConfig{SealerSetBlock: big.NewInt(20)}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "PoCR sealer set fork block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(20),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored:  &ChainConfig{Clique: &CliqueConfig{}},
+			new:     &ChainConfig{Clique: &CliqueConfig{FootprintRequiredBlock: big.NewInt(20)}},
+			head:    15,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Clique: &CliqueConfig{}},
+			new:    &ChainConfig{Clique: &CliqueConfig{FootprintRequiredBlock: big.NewInt(10)}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "PoCR footprint required fork block",
+				StoredConfig: nil,
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored: &ChainConfig{Clique: &CliqueConfig{FootprintStorageBlock: big.NewInt(10)}},
+			new:    &ChainConfig{Clique: &CliqueConfig{}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "PoCR footprint storage fork block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    nil,
+				RewindTo:     9,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -96,3 +175,134 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestRewardAlgorithmForks(t *testing.T) {
+	config := &CliqueConfig{
+		RewardAlgorithm: 3,
+		RewardAlgorithmForks: []RewardAlgorithmFork{
+			{Block: big.NewInt(10), Algorithm: 4},
+			{Block: big.NewInt(20), Algorithm: 3},
+		},
+	}
+	for _, tt := range []struct {
+		number uint64
+		want   uint64
+	}{{0, 3}, {9, 3}, {10, 4}, {19, 4}, {20, 3}, {100, 3}} {
+		if have := config.RewardAlgorithmAt(new(big.Int).SetUint64(tt.number)); have != tt.want {
+			t.Errorf("block %d: reward algorithm mismatch: have %d, want %d", tt.number, have, tt.want)
+		}
+	}
+	chain := &ChainConfig{Clique: config}
+	if err := chain.CheckConfigForkOrder(); err != nil {
+		t.Errorf("valid forks rejected: %v", err)
+	}
+	config.RewardAlgorithmForks[1].Block = big.NewInt(10)
+	if err := chain.CheckConfigForkOrder(); err == nil {
+		t.Errorf("forks at the same block accepted")
+	}
+	config.RewardAlgorithmForks[1].Block = nil
+	if err := chain.CheckConfigForkOrder(); err == nil {
+		t.Errorf("unscheduled fork accepted")
+	}
+}
+
+func TestPoCRForks(t *testing.T) {
+	later := DefaultPoCRFork
+	later.Block, later.AuditPenalty = big.NewInt(10), 10
+
+	config := &ChainConfig{Clique: &CliqueConfig{Period: 2}, PoCR: &PoCRConfig{Forks: []PoCRFork{later}}}
+	for _, tt := range []struct {
+		number  uint64
+		penalty uint64
+		period  uint64
+	}{{0, 5, 2}, {9, 5, 2}, {10, 10, 2}, {100, 10, 2}} {
+		fork, period := config.PoCRForkAt(new(big.Int).SetUint64(tt.number))
+		if fork.AuditPenalty != tt.penalty || period != tt.period {
+			t.Errorf("block %d: parameters mismatch: have %d/%d, want %d/%d", tt.number, fork.AuditPenalty, period, tt.penalty, tt.period)
+		}
+	}
+	if err := config.CheckConfigForkOrder(); err != nil {
+		t.Errorf("valid forks rejected: %v", err)
+	}
+	// The chains without PoCR config convert the durations with their period too,
+	// the zero period falling back to the default one
+	for _, tt := range []struct{ clique, pocr uint64 }{{0, DefaultPoCRPeriod}, {2, 2}, {15, 15}} {
+		legacy := &ChainConfig{Clique: &CliqueConfig{Period: tt.clique}}
+		if _, period := legacy.PoCRForkAt(big.NewInt(100)); period != tt.pocr {
+			t.Errorf("clique period %d: PoCR period mismatch: have %d, want %d", tt.clique, period, tt.pocr)
+		}
+	}
+	// Scheduling the default values changes nothing, the footprints being read
+	// the same way with any parameters
+	legacy := &ChainConfig{Clique: &CliqueConfig{Period: DefaultPoCRPeriod}}
+	explicit := &ChainConfig{Clique: &CliqueConfig{Period: DefaultPoCRPeriod}, PoCR: &PoCRConfig{Forks: []PoCRFork{DefaultPoCRFork}}}
+	explicit.PoCR.Forks[0].Block = big.NewInt(50)
+	if err := legacy.CheckCompatible(explicit, 100); err != nil {
+		t.Errorf("passed default parameters rejected: %v", err)
+	}
+	explicit.PoCR = nil
+	explicit.Clique.Period = 2
+	if err := legacy.CheckCompatible(explicit, 100); err == nil || err.RewindTo != 0 {
+		t.Errorf("period change at genesis mismatch: have %v, want rewind to 0", err)
+	}
+	// Changing the parameters of a passed fork is not
+	changed := &ChainConfig{Clique: &CliqueConfig{Period: 2}, PoCR: &PoCRConfig{Forks: []PoCRFork{later}}}
+	changed.PoCR.Forks[0].AuditPenalty = 20
+	if err := config.CheckCompatible(changed, 9); err != nil {
+		t.Errorf("change of a future fork rejected: %v", err)
+	}
+	if err := config.CheckCompatible(changed, 10); err == nil || err.RewindTo != 9 {
+		t.Errorf("change of a passed fork mismatch: have %v, want rewind to 9", err)
+	}
+	// Invalid schedules are rejected
+	config.PoCR.Forks = append(config.PoCR.Forks, later)
+	if err := config.CheckConfigForkOrder(); err == nil {
+		t.Errorf("forks at the same block accepted")
+	}
+	invalid := later
+	invalid.RankDecay = 101
+	config.PoCR.Forks = []PoCRFork{invalid}
+	if err := config.CheckConfigForkOrder(); err == nil {
+		t.Errorf("invalid rank decay accepted")
+	}
+}
+
+func TestSystemContractUpgrades(t *testing.T) {
+	config := &ChainConfig{SystemContractUpgrades: []SystemContractUpgrade{
+		{Name: "first", Block: big.NewInt(10)},
+		{Name: "second", Block: big.NewInt(10)},
+		{Name: "third", Block: big.NewInt(20)},
+	}}
+	if have := config.SystemContractUpgradesAt(big.NewInt(10)); !reflect.DeepEqual(have, []string{"first", "second"}) {
+		t.Errorf("upgrades at block 10 mismatch: have %v", have)
+	}
+	if have := config.SystemContractUpgradesAt(big.NewInt(15)); len(have) != 0 {
+		t.Errorf("upgrades at block 15 mismatch: have %v, want none", have)
+	}
+	if err := config.CheckConfigForkOrder(); err != nil {
+		t.Errorf("valid upgrades rejected: %v", err)
+	}
+	// Rescheduling a future upgrade is compatible, a passed one is not
+	changed := &ChainConfig{SystemContractUpgrades: append([]SystemContractUpgrade{}, config.SystemContractUpgrades...)}
+	changed.SystemContractUpgrades[2] = SystemContractUpgrade{Name: "third", Block: big.NewInt(30)}
+	if err := config.CheckCompatible(changed, 19); err != nil {
+		t.Errorf("change of a future upgrade rejected: %v", err)
+	}
+	if err := config.CheckCompatible(changed, 25); err == nil || err.RewindTo != 19 {
+		t.Errorf("change of a passed upgrade mismatch: have %v, want rewind to 19", err)
+	}
+	if err := config.CheckCompatible(&ChainConfig{}, 10); err == nil || err.RewindTo != 9 {
+		t.Errorf("removal of a passed upgrade mismatch: have %v, want rewind to 9", err)
+	}
+	// Invalid schedules are rejected
+	for _, upgrades := range [][]SystemContractUpgrade{
+		{{Name: "", Block: big.NewInt(10)}},
+		{{Name: "first", Block: nil}},
+		{{Name: "first", Block: big.NewInt(10)}, {Name: "first", Block: big.NewInt(20)}},
+		{{Name: "first", Block: big.NewInt(20)}, {Name: "second", Block: big.NewInt(10)}},
+	} {
+		if err := (&ChainConfig{SystemContractUpgrades: upgrades}).CheckConfigForkOrder(); err == nil {
+			t.Errorf("invalid upgrades %v accepted", upgrades)
+		}
+	}
+}